@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AppConfig holds sshtui's own settings, kept apart from ~/.ssh/config
+type AppConfig struct {
+	Locale            string         // UI language, overrides the environment
+	PageSize          int            // scrollback viewer lines per page, 0 fits the terminal
+	MultiTimeout      time.Duration  // default per-host limit of multi-host runs
+	WatchPatterns     []string       // text raising a notification in any session output
+	UpdateCheck       bool           // look for a newer release once a day
+	ManagedHosts      bool           // sshtui keeps added hosts itself, see managedProvider
+	SessionRetention  time.Duration  // ended sessions are archived after it, 0 never
+	Terminal          string         // emulator opening external windows, see externalCommand
+	PTYColumns        int            // size of PTYs not attached to the terminal,
+	PTYRows           int            // 0 mirrors the terminal
+	RcSnippet         string         // shell lines run in every interactive session, see rcCommand
+	ShellIntegration  bool           // OSC 133 prompt marks from bash, see shellIntegrationRc
+	PromptPattern     *regexp.Regexp // shell prompts in scrollback, nil for DefaultPromptPattern
+	Dashboard         string         // DashboardAlways, DashboardSessions or DashboardOff
+	DashboardInterval time.Duration  // between polls of the hosts' stats
+	ScrollbackHistory int            // output kept compressed beyond MaxScrollbackSize, 0 none
+	HealthWatch       bool           // probe hosts in the background, see watchHealth
+	HealthInterval    time.Duration  // between probes of the watched hosts
+	HealthDownAfter   time.Duration  // how long a watched host fails probes before it is down
+	HealthHook        string         // local command run when a watched host goes down or up
+	HealthWebhook     string         // URL posted the same events as JSON
+	Hosts             []HostSettings
+	Tags              map[string]TagSettings
+	Workspaces        []Workspace
+	Providers         []ProviderSettings
+}
+
+// ProviderSettings enables a built-in host provider with key=value
+// options, or defines a script provider running Args
+type ProviderSettings struct {
+	Name string
+	Args []string
+}
+
+// HostSettings applies to hosts whose alias matches Pattern (globs allowed)
+type HostSettings struct {
+	Pattern string
+	Tags    []string
+	names   []string // Pattern with its numeric ranges expanded
+	Tee     string   // local command fed with session output
+	Command string   // default remote command for interactive sessions
+	Connect string   // command line replacing ssh, see expandConnect
+
+	Encoding string // character set of the hosts' terminal, see normalizeEncoding
+
+	StatusBar string // "yes" or "no", empty when the block does not say
+	Hotkey    string // F1-F12 or a letter connecting from the menu
+
+	ExternalWindow  string // "yes" or "no", empty when the block does not say
+	TerminalProfile string
+
+	RcFile    string // snippet file, "none" for no snippet, empty when the block does not say
+	rcSnippet string // what RcFile holds
+
+	PromptPattern    *regexp.Regexp
+	ShellIntegration string // "yes" or "no", empty when the block does not say
+	Dashboard        string // "yes", "sessions" or "no", empty when the block does not say
+	HealthWatch      string // "yes" or "no", empty when the block does not say
+
+	TOTPSecret string // command printing the host's TOTP secret
+	TOTP       string // TOTPShow or TOTPType, empty when the block does not say
+}
+
+// matches reports whether alias matches the block's pattern, a glob
+// which may hold numeric ranges
+func (h HostSettings) matches(alias string) bool {
+	for _, name := range h.names {
+		if matched, _ := filepath.Match(name, alias); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// TagSettings configures behavior shared by all hosts with a tag
+type TagSettings struct {
+	Name    string
+	Confirm string // ConfirmConnect, ConfirmMulti, ConfirmBoth or ConfirmNone
+	User    string // login user for tagged hosts that set none
+
+	CertCommand  string // issues a short-lived certificate before connecting
+	CertIdentity string // key the certificate is issued for
+
+	ProxyJump string // jump host of tagged hosts not from ~/.ssh/config
+
+	Record string // "yes" or "no": record sessions, empty when the block does not say
+	Reason string // "yes" or "no": ask for a ticket or reason before connecting
+}
+
+// Confirmation modes for tagged hosts
+const (
+	ConfirmNone    = "no"
+	ConfirmConnect = "connect"
+	ConfirmMulti   = "multi"
+	ConfirmBoth    = "yes"
+)
+
+// DefaultMultiTimeout bounds each host of a multi-host run unless
+// MultiTimeout says otherwise
+const DefaultMultiTimeout = 5 * time.Minute
+
+// defaultConfirmTags require confirmation unless a Tag block says otherwise
+var defaultConfirmTags = map[string]string{
+	"prod":      ConfirmBoth,
+	"dangerous": ConfirmBoth,
+	"regulated": ConfirmMulti,
+}
+
+// Workspace is a named group of hosts opened together
+type Workspace struct {
+	Name    string
+	Members []WorkspaceMember
+}
+
+// WorkspaceMember is a host in a workspace with its startup constraints
+type WorkspaceMember struct {
+	Alias string
+	After []string // aliases that must be connected first
+	Wait  bool     // dependents wait until this host responds
+}
+
+var appConfig AppConfig
+
+func appConfigPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config"), nil
+}
+
+// parseAppConfig reads ~/.config/sshtui/config; a missing file is an empty config
+func parseAppConfig() (AppConfig, error) {
+	cfg := AppConfig{MultiTimeout: DefaultMultiTimeout, SessionRetention: DefaultSessionRetention, DashboardInterval: DefaultDashboardInterval,
+		HealthInterval: DefaultHealthInterval, HealthDownAfter: DefaultHealthDownAfter, ScrollbackHistory: DefaultScrollbackHistory}
+
+	configPath, err := appConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	defer file.Close()
+
+	cfg.Tags = make(map[string]TagSettings)
+	block := "" // "host", "tag" or "workspace"
+	tagName := ""
+	lineNum := 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			return cfg, fmt.Errorf(tr("%s:%d: missing value for %s"), configPath, lineNum, parts[0])
+		}
+
+		key := strings.ToLower(parts[0])
+		value := strings.Join(parts[1:], " ")
+
+		switch key {
+		case "locale":
+			cfg.Locale = value
+
+		case "multitimeout":
+			timeout, err := parseTimeout(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.MultiTimeout = timeout
+
+		case "sessionretention":
+			if strings.ToLower(value) == "off" {
+				cfg.SessionRetention = 0
+				break
+			}
+			retention, err := parseTimeout(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.SessionRetention = retention
+
+		case "scrollbackhistory":
+			size, err := parseSize(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.ScrollbackHistory = size
+
+		case "pagesize":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 1 {
+				return cfg, fmt.Errorf(tr("%s:%d: PageSize must be a positive number"), configPath, lineNum)
+			}
+			cfg.PageSize = size
+
+		case "ptysize":
+			// COLUMNSxROWS, or auto to mirror the terminal
+			if strings.ToLower(value) == "auto" {
+				cfg.PTYColumns, cfg.PTYRows = 0, 0
+				break
+			}
+			cols, rows, ok := strings.Cut(strings.ToLower(value), "x")
+			c, errC := strconv.Atoi(cols)
+			r, errR := strconv.Atoi(rows)
+			if !ok || errC != nil || errR != nil || c < 1 || r < 1 || c > 65535 || r > 65535 {
+				return cfg, fmt.Errorf(tr("%s:%d: PTYSize must be auto or COLUMNSxROWS, e.g. 200x50"), configPath, lineNum)
+			}
+			cfg.PTYColumns, cfg.PTYRows = c, r
+
+		case "updatecheck":
+			switch strings.ToLower(value) {
+			case "yes", "no":
+				cfg.UpdateCheck = strings.ToLower(value) == "yes"
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+
+		case "managedhosts":
+			switch strings.ToLower(value) {
+			case "yes", "no":
+				cfg.ManagedHosts = strings.ToLower(value) == "yes"
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+
+		case "terminal":
+			// Known emulators by name, otherwise a command line kept as written
+			cfg.Terminal = rawValue(line, parts)
+			switch strings.ToLower(cfg.Terminal) {
+			case TerminalKitty, TerminalWezTerm, TerminalAlacritty, TerminalITerm2:
+				cfg.Terminal = strings.ToLower(cfg.Terminal)
+			default:
+				if !strings.Contains(cfg.Terminal, "{ssh}") {
+					return cfg, fmt.Errorf(tr("%s:%d: Terminal must be kitty, wezterm, alacritty, iterm2 or a command with {ssh}"), configPath, lineNum)
+				}
+			}
+
+		case "rcfile":
+			// At the top it applies to every host, in a Host block to its hosts
+			snippet := ""
+			if strings.ToLower(value) != "none" {
+				data, err := os.ReadFile(expandHome(value))
+				if err != nil {
+					return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+				}
+				snippet = string(data)
+			}
+			switch block {
+			case "":
+				cfg.RcSnippet = snippet
+			case "host":
+				h := &cfg.Hosts[len(cfg.Hosts)-1]
+				h.RcFile, h.rcSnippet = value, snippet
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s goes before the first block or in a Host block"), configPath, lineNum, parts[0])
+			}
+
+		case "shellintegration":
+			value = strings.ToLower(value)
+			if value != "yes" && value != "no" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+			switch block {
+			case "":
+				cfg.ShellIntegration = value == "yes"
+			case "host":
+				cfg.Hosts[len(cfg.Hosts)-1].ShellIntegration = value
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s goes before the first block or in a Host block"), configPath, lineNum, parts[0])
+			}
+
+		case "dashboard":
+			value = strings.ToLower(value)
+			if value != DashboardAlways && value != DashboardSessions && value != DashboardOff {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes, sessions or no"), configPath, lineNum, parts[0])
+			}
+			switch block {
+			case "":
+				cfg.Dashboard = value
+			case "host":
+				cfg.Hosts[len(cfg.Hosts)-1].Dashboard = value
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s goes before the first block or in a Host block"), configPath, lineNum, parts[0])
+			}
+
+		case "dashboardinterval":
+			interval, err := parseTimeout(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			if interval < MinDashboardInterval {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be at least %v"), configPath, lineNum, parts[0], MinDashboardInterval)
+			}
+			cfg.DashboardInterval = interval
+
+		case "healthwatch":
+			value = strings.ToLower(value)
+			if value != "yes" && value != "no" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+			switch block {
+			case "":
+				cfg.HealthWatch = value == "yes"
+			case "host":
+				cfg.Hosts[len(cfg.Hosts)-1].HealthWatch = value
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s goes before the first block or in a Host block"), configPath, lineNum, parts[0])
+			}
+
+		case "healthinterval", "healthdownafter":
+			interval, err := parseTimeout(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			if key == "healthdownafter" {
+				cfg.HealthDownAfter = interval
+				break
+			}
+			if interval < MinHealthInterval {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be at least %v"), configPath, lineNum, parts[0], MinHealthInterval)
+			}
+			cfg.HealthInterval = interval
+
+		case "healthhook":
+			cfg.HealthHook = rawValue(line, parts)
+
+		case "healthwebhook":
+			if err := checkWebhookURL(value); err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.HealthWebhook = value
+
+		case "promptpattern":
+			pattern, err := regexp.Compile(rawValue(line, parts))
+			if err != nil {
+				return cfg, fmt.Errorf(tr("%s:%d: invalid PromptPattern: %v"), configPath, lineNum, err)
+			}
+			switch block {
+			case "":
+				cfg.PromptPattern = pattern
+			case "host":
+				cfg.Hosts[len(cfg.Hosts)-1].PromptPattern = pattern
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s goes before the first block or in a Host block"), configPath, lineNum, parts[0])
+			}
+
+		case "watchpattern":
+			cfg.WatchPatterns = append(cfg.WatchPatterns, rawValue(line, parts))
+
+		case "host":
+			block = "host"
+			names, err := expandRanges(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.Hosts = append(cfg.Hosts, HostSettings{Pattern: value, names: names})
+
+		case "tag":
+			block = "tag"
+			tagName = value
+			cfg.Tags[tagName] = TagSettings{Name: tagName}
+
+		case "workspace":
+			block = "workspace"
+			cfg.Workspaces = append(cfg.Workspaces, Workspace{Name: value})
+
+		case "provider":
+			block = ""
+			settings := ProviderSettings{Name: parts[1], Args: parts[2:]}
+			if _, err := newProvider(settings); err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.Providers = append(cfg.Providers, settings)
+
+		case "tags":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			h := &cfg.Hosts[len(cfg.Hosts)-1]
+			h.Tags = append(h.Tags, parts[1:]...)
+
+		case "tee", "command", "connect":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			command := rawValue(line, parts)
+			switch key {
+			case "tee":
+				cfg.Hosts[len(cfg.Hosts)-1].Tee = command
+			case "command":
+				cfg.Hosts[len(cfg.Hosts)-1].Command = command
+			default:
+				cfg.Hosts[len(cfg.Hosts)-1].Connect = command
+			}
+
+		case "encoding":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			cfg.Hosts[len(cfg.Hosts)-1].Encoding = normalizeEncoding(value)
+
+		case "totpsecret":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			cfg.Hosts[len(cfg.Hosts)-1].TOTPSecret = rawValue(line, parts)
+
+		case "totp":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			value = strings.ToLower(value)
+			if value != TOTPShow && value != TOTPType {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be show or type"), configPath, lineNum, parts[0])
+			}
+			cfg.Hosts[len(cfg.Hosts)-1].TOTP = value
+
+		case "terminalprofile":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			cfg.Hosts[len(cfg.Hosts)-1].TerminalProfile = value
+
+		case "hotkey":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			hotkey, err := parseHotkey(value)
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			cfg.Hosts[len(cfg.Hosts)-1].Hotkey = hotkey
+
+		case "statusbar", "externalwindow":
+			if block != "host" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Host")
+			}
+			switch strings.ToLower(value) {
+			case "yes", "no":
+				if key == "statusbar" {
+					cfg.Hosts[len(cfg.Hosts)-1].StatusBar = strings.ToLower(value)
+				} else {
+					cfg.Hosts[len(cfg.Hosts)-1].ExternalWindow = strings.ToLower(value)
+				}
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+
+		case "confirm":
+			if block != "tag" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Tag")
+			}
+			mode := strings.ToLower(value)
+			switch mode {
+			case ConfirmNone, ConfirmConnect, ConfirmMulti, ConfirmBoth:
+			default:
+				return cfg, fmt.Errorf(tr("%s:%d: Confirm must be yes, no, connect or multi"), configPath, lineNum)
+			}
+			tag := cfg.Tags[tagName]
+			tag.Confirm = mode
+			cfg.Tags[tagName] = tag
+
+		case "record", "reason":
+			if block != "tag" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Tag")
+			}
+			setting := strings.ToLower(value)
+			if setting != "yes" && setting != "no" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s must be yes or no"), configPath, lineNum, parts[0])
+			}
+			tag := cfg.Tags[tagName]
+			if key == "record" {
+				tag.Record = setting
+			} else {
+				tag.Reason = setting
+			}
+			cfg.Tags[tagName] = tag
+
+		case "certcommand", "certidentity":
+			if block != "tag" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Tag")
+			}
+			tag := cfg.Tags[tagName]
+			if key == "certcommand" {
+				tag.CertCommand = rawValue(line, parts)
+			} else {
+				tag.CertIdentity = value
+			}
+			cfg.Tags[tagName] = tag
+
+		case "user", "proxyjump":
+			if block != "tag" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Tag")
+			}
+			tag := cfg.Tags[tagName]
+			if key == "user" {
+				tag.User = value
+			} else {
+				tag.ProxyJump = value
+			}
+			cfg.Tags[tagName] = tag
+
+		case "open":
+			if block != "workspace" {
+				return cfg, fmt.Errorf(tr("%s:%d: %s outside of a %s block"), configPath, lineNum, parts[0], "Workspace")
+			}
+			member, err := parseWorkspaceMember(parts[1:])
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: %v", configPath, lineNum, err)
+			}
+			ws := &cfg.Workspaces[len(cfg.Workspaces)-1]
+			ws.Members = append(ws.Members, member)
+
+		default:
+			return cfg, fmt.Errorf(tr("%s:%d: unknown option %s"), configPath, lineNum, parts[0])
+		}
+	}
+
+	return cfg, scanner.Err()
+}
+
+// rawValue is a setting's value as written after its key, spacing included,
+// for commands and patterns that joining the fields would change
+func rawValue(line string, parts []string) string {
+	return strings.TrimSpace(line[len(parts[0]):])
+}
+
+func parseWorkspaceMember(fields []string) (WorkspaceMember, error) {
+	// Open web1 [wait] [after bastion,proxy]
+	member := WorkspaceMember{Alias: fields[0]}
+
+	for i := 1; i < len(fields); i++ {
+		switch strings.ToLower(fields[i]) {
+		case "wait":
+			member.Wait = true
+		case "after":
+			if i+1 >= len(fields) {
+				return member, errors.New(tr("after needs a host list"))
+			}
+			i++
+			member.After = append(member.After, strings.Split(fields[i], ",")...)
+		default:
+			return member, fmt.Errorf(tr("unexpected %q in Open"), fields[i])
+		}
+	}
+	return member, nil
+}
+
+// applyHostSettings copies sshtui per-host settings (tags, tee, command,
+// status bar) onto parsed hosts, after the tags from inventory metadata;
+// for all but tags the last matching block wins.
+// The first of a host's tags with a User rule gives its rule user, the
+// first with a ProxyJump the jump host of a provider's host, and the first
+// with a CertCommand issues its certificate
+func applyHostSettings(hosts []SSHHost, cfg AppConfig) []SSHHost {
+	hotkeys := map[string]bool{} // a hotkey goes to the first host it matches
+	for i := range hosts {
+		hosts[i].Tags = metaTags(hosts[i])
+		hosts[i].Tee = ""
+		hosts[i].Command = ""
+		hosts[i].Connect = ""
+		hosts[i].Encoding = ""
+		hosts[i].RuleUser = ""
+		hosts[i].StatusBar = false
+		hosts[i].Hotkey = ""
+		hosts[i].ExternalWindow, hosts[i].TerminalProfile = false, ""
+		hosts[i].RcSnippet = cfg.RcSnippet
+		hosts[i].PromptPattern = cfg.PromptPattern
+		hosts[i].ShellIntegration = cfg.ShellIntegration
+		hosts[i].Dashboard = cfg.Dashboard
+		hosts[i].HealthWatch = cfg.HealthWatch
+		hosts[i].TOTPSecret, hosts[i].TOTP = "", ""
+		hosts[i].CertTag, hosts[i].CertCommand, hosts[i].CertIdentity = "", "", ""
+		for _, settings := range cfg.Hosts {
+			if settings.matches(hosts[i].Alias) {
+				hosts[i].Tags = appendUnique(hosts[i].Tags, settings.Tags...)
+				if settings.Tee != "" {
+					hosts[i].Tee = settings.Tee
+				}
+				if settings.Command != "" {
+					hosts[i].Command = settings.Command
+				}
+				if settings.Connect != "" {
+					hosts[i].Connect = settings.Connect
+				}
+				if settings.Encoding != "" {
+					hosts[i].Encoding = settings.Encoding
+				}
+				if settings.StatusBar != "" {
+					hosts[i].StatusBar = settings.StatusBar == "yes"
+				}
+				if settings.ExternalWindow != "" {
+					hosts[i].ExternalWindow = settings.ExternalWindow == "yes"
+				}
+				if settings.TerminalProfile != "" {
+					hosts[i].TerminalProfile = settings.TerminalProfile
+				}
+				if settings.RcFile != "" {
+					hosts[i].RcSnippet = settings.rcSnippet
+				}
+				if settings.PromptPattern != nil {
+					hosts[i].PromptPattern = settings.PromptPattern
+				}
+				if settings.ShellIntegration != "" {
+					hosts[i].ShellIntegration = settings.ShellIntegration == "yes"
+				}
+				if settings.Dashboard != "" {
+					hosts[i].Dashboard = settings.Dashboard
+				}
+				if settings.HealthWatch != "" {
+					hosts[i].HealthWatch = settings.HealthWatch == "yes"
+				}
+				if settings.TOTPSecret != "" {
+					hosts[i].TOTPSecret = settings.TOTPSecret
+				}
+				if settings.TOTP != "" {
+					hosts[i].TOTP = settings.TOTP
+				}
+				if settings.Hotkey != "" && hosts[i].Hotkey == "" && !hotkeys[settings.Hotkey] {
+					hosts[i].Hotkey = settings.Hotkey
+					hotkeys[settings.Hotkey] = true
+				}
+			}
+		}
+		for _, tag := range hosts[i].Tags {
+			if user := cfg.Tags[tag].User; user != "" {
+				hosts[i].RuleUser = user
+				break
+			}
+		}
+		// ssh applies the ProxyJump of ~/.ssh/config hosts itself
+		if !fromSSHConfig(hosts[i]) {
+			hosts[i].ProxyJump = ""
+			for _, tag := range hosts[i].Tags {
+				if jump := cfg.Tags[tag].ProxyJump; jump != "" {
+					hosts[i].ProxyJump = jump
+					break
+				}
+			}
+		}
+		for _, tag := range hosts[i].Tags {
+			if settings := cfg.Tags[tag]; settings.CertCommand != "" {
+				hosts[i].CertTag = tag
+				hosts[i].CertCommand = settings.CertCommand
+				hosts[i].CertIdentity = settings.CertIdentity
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+// confirmMode returns how a tag requires confirmation
+func confirmMode(tag string) string {
+	if settings, ok := appConfig.Tags[tag]; ok && settings.Confirm != "" {
+		return settings.Confirm
+	}
+	if mode, ok := defaultConfirmTags[tag]; ok {
+		return mode
+	}
+	return ConfirmNone
+}
+
+// needsConfirm returns the first tag of host requiring confirmation for
+// the action (ConfirmConnect or ConfirmMulti), or ""
+func needsConfirm(host SSHHost, action string) string {
+	for _, tag := range host.Tags {
+		mode := confirmMode(tag)
+		if mode == ConfirmBoth || mode == action {
+			return tag
+		}
+	}
+	return ""
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
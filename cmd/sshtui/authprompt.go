@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// authPrompts are fragments of prompts ssh or PAM leave waiting for input
+var authPrompts = []string{
+	"password:",
+	"password for",
+	"passphrase for key",
+	"verification code",
+	"one-time password",
+	"otp:",
+	"token:",
+	"enter pin",
+	hostKeyPrompt,
+	"duo two-factor",
+	"passcode",
+}
+
+const (
+	// AuthPromptMaxLen bounds the line checked; prompts are short
+	AuthPromptMaxLen = 200
+	// hostKeyPrompt asks to accept a host key, the only prompt whose
+	// answer is not a secret
+	hostKeyPrompt = "(yes/no"
+)
+
+// waitingForAuth reports whether the output ends with an unanswered
+// password, passphrase, host key or 2FA prompt
+func waitingForAuth(scrollback []byte) bool {
+	return authPrompt(scrollback) != ""
+}
+
+// authPrompt returns the fragment of authPrompts the output ends with a
+// prompt for, or ""
+func authPrompt(scrollback []byte) string {
+	// A prompt is the last line, not yet terminated by a newline
+	line := scrollback[bytes.LastIndexByte(scrollback, '\n')+1:]
+	if len(line) == 0 || len(line) > AuthPromptMaxLen {
+		return ""
+	}
+
+	text := strings.ToLower(strings.TrimSpace(string(line)))
+	if !strings.HasSuffix(text, ":") && !strings.HasSuffix(text, "?") {
+		return ""
+	}
+	for _, prompt := range authPrompts {
+		if strings.Contains(text, prompt) {
+			return prompt
+		}
+	}
+	return ""
+}
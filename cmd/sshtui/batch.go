@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exit codes of the non-interactive modes (--run, --test)
+const (
+	ExitOK       = 0 // every host succeeded
+	ExitFailures = 1 // at least one host failed
+	ExitConfig   = 2 // config or usage error, nothing was run
+)
+
+// batchQuiet (--quiet) limits --run and --test output to failures
+var batchQuiet bool
+
+// matchTargets resolves aliases and globs to hosts, in config order; a
+// target matching nothing is an error. With local, localhost is the local
+// pseudo-host when no host has that name
+func matchTargets(hosts []SSHHost, targets []string, local bool) ([]SSHHost, error) {
+	selected := []SSHHost{}
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		found := false
+		for _, host := range hosts {
+			if matched, _ := filepath.Match(target, host.Alias); !matched {
+				continue
+			}
+			found = true
+			if !seen[host.Alias] {
+				seen[host.Alias] = true
+				selected = append(selected, host)
+			}
+		}
+		if !found && local && target == localAlias {
+			// Named in full, never by a glob
+			found = true
+			if !seen[localAlias] {
+				seen[localAlias] = true
+				selected = append(selected, localHost())
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(tr("no host matches %s"), target)
+		}
+	}
+	return selected, nil
+}
+
+// runBatch runs a command on the hosts without the menu, output prefixed
+// by host, and returns the exit code. Hosts whose tags require
+// confirmation or a reason are skipped as failed, since nobody is there
+// to answer
+func runBatch(hosts []SSHHost, command string) int {
+	job := parseMultiJob(command)
+	if job.Script != "" {
+		if _, err := os.Stat(job.Script); err != nil {
+			fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+			return ExitConfig
+		}
+	}
+
+	live := newLiveOutput(hosts)
+	results := make([]HostResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		if tag := needsConfirm(host, ConfirmMulti); tag != "" {
+			results[i] = HostResult{Alias: host.Alias, Error: fmt.Errorf(tr("tagged %s, needs confirmation"), tag)}
+			continue
+		}
+		if tag := reasonTag(host); tag != "" {
+			results[i] = HostResult{Alias: host.Alias, Error: fmt.Errorf(tr("tagged %s, needs a reason"), tag)}
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, h SSHHost) {
+			defer wg.Done()
+			if batchQuiet {
+				results[idx] = runOnHost(context.Background(), h, job, appConfig.MultiTimeout, nil)
+				return
+			}
+			stream := live.stream(h.Alias)
+			results[idx] = runOnHost(context.Background(), h, job, appConfig.MultiTimeout, stream)
+			stream.flush()
+		}(i, host)
+	}
+	wg.Wait()
+
+	// The output was streamed already; the report only says how it went,
+	// with a failing host's output when --quiet kept it back
+	for i := range results {
+		if !batchQuiet || results[i].Error == nil {
+			results[i].Output = ""
+		}
+	}
+	return reportBatch(results)
+}
+
+// testHosts runs the connection test on every host at once and returns
+// the exit code
+func testHosts(hosts []SSHHost) int {
+	results := make([]HostResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(idx int, h SSHHost) {
+			defer wg.Done()
+			test := runConnectTest(h)
+			recordConnect(h.Alias, test.Err)
+			results[idx] = HostResult{Alias: h.Alias, Error: test.Err}
+			if test.Err == nil {
+				results[idx].Output = fmt.Sprintf("%v %s", test.Total.Round(time.Millisecond), test.AuthMethod)
+			}
+		}(i, host)
+	}
+	wg.Wait()
+
+	return reportBatch(results)
+}
+
+// reportBatch prints one line per host, failures on stderr followed by
+// any output they kept, and turns the results into an exit code
+func reportBatch(results []HostResult) int {
+	code := ExitOK
+	for _, result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", failMark(), result.Alias, result.Error)
+			if output := strings.TrimRight(result.Output, "\r\n"); output != "" {
+				for _, line := range strings.Split(output, "\n") {
+					fmt.Fprintf(os.Stderr, "  %s\n", strings.TrimRight(line, "\r"))
+				}
+			}
+			code = ExitFailures
+		} else if !batchQuiet {
+			fmt.Fprintln(os.Stderr, strings.TrimSpace(fmt.Sprintf("%s %s %s", okMark(), result.Alias, result.Output)))
+		}
+	}
+	return code
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureStderr returns what f writes to stderr
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = old }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+func TestRunBatchQuietKeepsFailures(t *testing.T) {
+	headless(t, `case "$*" in *bad*) echo boom; exit 1;; esac; echo fine`)
+	oldQuiet, oldTimeout := batchQuiet, appConfig.MultiTimeout
+	batchQuiet, appConfig.MultiTimeout = true, time.Second
+	t.Cleanup(func() { batchQuiet, appConfig.MultiTimeout = oldQuiet, oldTimeout })
+
+	code := 0
+	report := captureStderr(t, func() {
+		code = runBatch([]SSHHost{{Alias: "good"}, {Alias: "bad"}}, "uptime")
+	})
+	if code != ExitFailures {
+		t.Errorf("exit code %d, want %d", code, ExitFailures)
+	}
+	if !strings.Contains(report, "bad") || !strings.Contains(report, "  boom\n") {
+		t.Errorf("report %q lacks the failing host's output", report)
+	}
+	if strings.Contains(report, "good") || strings.Contains(report, "fine") {
+		t.Errorf("quiet report %q shows a host that succeeded", report)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+// bellTracker flags a session that rang the bell while nobody was
+// attached, like tmux's window bell flag. While attached, BEL reaches the
+// terminal with the rest of the output. The BEL ending an OSC sequence
+// (window titles and the like) is not a bell
+type bellTracker struct {
+	session *Session
+	inOSC   bool
+	escape  bool // previous byte was ESC
+}
+
+func (t *bellTracker) Write(p []byte) (int, error) {
+	rang := false
+	for _, b := range p {
+		switch {
+		case t.escape:
+			t.escape = false
+			if b == ']' {
+				t.inOSC = true
+			} else if b == '\\' {
+				t.inOSC = false // ST
+			}
+		case b == 0x1b:
+			t.escape = true
+		case b == 0x07:
+			if t.inOSC {
+				t.inOSC = false
+			} else {
+				rang = true
+			}
+		}
+	}
+
+	if rang {
+		sessionsMu.Lock()
+		if t.session.State != StateAttached && !t.session.Bell {
+			t.session.Bell = true
+			postEvent(EventSession)
+		}
+		sessionsMu.Unlock()
+	}
+	return len(p), nil
+}
+
+// bellLabel returns the menu marker of a session that rang the bell
+func bellLabel(s *Session) string {
+	if !s.Bell {
+		return ""
+	}
+	return colorize("1;33", tr(" [bell]"))
+}
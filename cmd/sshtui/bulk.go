@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// selectSessions returns the sessions matching a predicate; callers hold sessionsMu
+func selectSessions(match func(s *Session) bool) []*Session {
+	selected := []*Session{}
+	for _, s := range sessions {
+		if match(s) {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// confirmSessions lists the affected sessions and asks before acting
+func confirmSessions(action string, selected []*Session, reader *bufio.Reader) bool {
+	if len(selected) == 0 {
+		fmt.Print(tr("No matching sessions. Press Enter..."))
+		reader.ReadString('\n')
+		return false
+	}
+
+	fmt.Printf(tr("\n%s %d session(s):\n"), action, len(selected))
+	for _, s := range selected {
+		fmt.Printf("  [!%d] %s (%s)\n", sessionIndex(s), s.Alias, coloredStatus(s))
+	}
+	fmt.Print(tr("\nProceed? [y/N]: "))
+
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+// closeSessions kills and removes the given sessions
+func closeSessions(selected []*Session) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	remove := make(map[*Session]bool)
+	for _, s := range selected {
+		killSession(s)
+		remove[s] = true
+	}
+
+	kept := sessions[:0]
+	for _, s := range sessions {
+		if !remove[s] {
+			kept = append(kept, s)
+		}
+	}
+	sessions = kept
+}
+
+// restartSession starts a fresh ssh process for an ended session in place
+func restartSession(s *Session) error {
+	if s.Kind == SessionForward {
+		cmd, err := spawnForward(s.Host, s)
+		if err != nil {
+			return err
+		}
+		sessionsMu.Lock()
+		s.Cmd = cmd
+		s.State = StateReady
+		s.Restarts = 0
+		sessionsMu.Unlock()
+		postEvent(EventSession)
+		go superviseForward(s.Host, s)
+		return nil
+	}
+
+	if err := refreshCertificate(s.Host); err != nil {
+		return err
+	}
+	cmd, ptmx, err := spawnPTY(s.Args)
+	if err != nil {
+		return err
+	}
+	sessionsMu.Lock()
+	if s.PTY != nil {
+		s.PTY.Close()
+	}
+	s.Cmd = cmd
+	s.PTY = ptmx
+	s.State = StateConnecting
+	sessionsMu.Unlock()
+	postEvent(EventSession)
+	go readPTY(s, ptmx)
+	go monitorSession(s)
+	return nil
+}
+
+// isEnded reports whether a session is down and not being restarted
+func isEnded(s *Session) bool {
+	return s.State == StateDead
+}
+
+// manageSessions offers operations over several sessions at once
+func manageSessions() {
+	reader := bufio.NewReader(os.Stdin)
+	moving := 0 // session moved last, see reorderCommand
+
+	for {
+		clearScreen()
+		printHeader(tr("Bulk Session Operations"))
+
+		sessionsMu.RLock()
+		for i, s := range sessions {
+			marker := ""
+			if i+1 == moving {
+				marker = tr(" <- moving")
+			}
+			fmt.Printf("  [!%d] %s (%s)%s\n", i+1, s.Alias, coloredStatus(s), marker)
+		}
+		if len(sessions) == 0 {
+			fmt.Println(tr("No active sessions"))
+		}
+		sessionsMu.RUnlock()
+
+		fmt.Println(tr("\nCommands:"))
+		fmt.Println(tr("  e         - Close all ended sessions"))
+		fmt.Println(tr("  g         - Close sessions matching a host glob"))
+		fmt.Println(tr("  r         - Reconnect all ended sessions"))
+		fmt.Println(tr("  d         - Detach all sessions attached elsewhere"))
+		fmt.Println(tr("  X         - Close all sessions, running ones included"))
+		fmt.Println(tr("  k [!n]    - Move session up (again without a number)"))
+		fmt.Println(tr("  j [!n]    - Move session down (again without a number)"))
+		fmt.Println(tr("  m !n !to  - Move session to a number; the order is remembered"))
+		fmt.Println(tr("  q         - Back to main menu"))
+		fmt.Print("\n> ")
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		var selected []*Session
+		switch input {
+		case "q":
+			return
+
+		case "e":
+			sessionsMu.RLock()
+			selected = selectSessions(isEnded)
+			sessionsMu.RUnlock()
+			if confirmSessions(tr("Close"), selected, reader) {
+				closeSessions(selected)
+			}
+
+		case "g":
+			fmt.Print(tr("Host glob (e.g. web*): "))
+			pattern, _ := reader.ReadString('\n')
+			pattern = strings.TrimSpace(pattern)
+			if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+				fmt.Print(tr("Invalid pattern. Press Enter..."))
+				reader.ReadString('\n')
+				continue
+			}
+			sessionsMu.RLock()
+			selected = selectSessions(func(s *Session) bool {
+				matched, _ := filepath.Match(pattern, s.Alias)
+				return matched
+			})
+			sessionsMu.RUnlock()
+			if confirmSessions(tr("Close"), selected, reader) {
+				closeSessions(selected)
+			}
+
+		case "r":
+			sessionsMu.RLock()
+			selected = selectSessions(isEnded)
+			sessionsMu.RUnlock()
+			if confirmSessions(tr("Reconnect"), selected, reader) {
+				restarted := []*Session{}
+				for _, s := range selected {
+					if err := restartSession(s); err != nil {
+						fmt.Printf("  %s %s: %v\n", failMark(), s.Alias, err)
+					} else {
+						fmt.Printf("  %s %s\n", okMark(), s.Alias)
+						restarted = append(restarted, s)
+					}
+				}
+				if !offerPromptRelay(restarted) {
+					fmt.Print(tr("\nPress Enter..."))
+					reader.ReadString('\n')
+				}
+			}
+
+		case "d":
+			sessionsMu.RLock()
+			selected = selectSessions(func(s *Session) bool { return s.State == StateAttached })
+			sessionsMu.RUnlock()
+			if confirmSessions(tr("Detach"), selected, reader) {
+				for _, s := range selected {
+					detachSession(s)
+				}
+			}
+
+		case "X":
+			sessionsMu.RLock()
+			selected = selectSessions(func(s *Session) bool { return true })
+			sessionsMu.RUnlock()
+			if confirmSessions(tr("Close"), selected, reader) {
+				closeSessions(selected)
+			}
+
+		default:
+			if fields := strings.Fields(input); len(fields) > 0 && slices.Contains([]string{"k", "j", "m"}, fields[0]) {
+				var err error
+				if moving, err = reorderCommand(input, moving); err != nil {
+					fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+					reader.ReadString('\n')
+				}
+			}
+		}
+	}
+}
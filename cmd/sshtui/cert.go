@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// certTimeLayout is how ssh-keygen -L prints validity bounds, in local time
+const certTimeLayout = "2006-01-02T15:04:05"
+
+// defaultIdentities are the keys ssh tries when a host sets no IdentityFile
+var defaultIdentities = []string{"~/.ssh/id_rsa", "~/.ssh/id_ecdsa", "~/.ssh/id_ed25519"}
+
+// SSHCertificate is what ssh-keygen -L reports about a certificate
+type SSHCertificate struct {
+	Path       string
+	KeyID      string
+	ValidFrom  time.Time // zero when valid from the beginning of time
+	ValidTo    time.Time // zero when valid forever
+	Principals []string
+	Err        error // the certificate could not be read
+}
+
+// hostCertificates returns the certificates ssh may offer for host: its
+// CertificateFile entries and the -cert.pub files next to its identities
+func hostCertificates(host SSHHost) []SSHCertificate {
+	paths := []string{}
+	if host.CertCommand != "" {
+		if path, err := issuedCertPath(host); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	for _, file := range host.CertificateFiles {
+		paths = appendUnique(paths, expandHome(file))
+	}
+
+	identities := host.IdentityFiles
+	if len(identities) == 0 {
+		identities = defaultIdentities
+	}
+	for _, identity := range identities {
+		cert := strings.TrimSuffix(expandHome(identity), ".pub") + "-cert.pub"
+		if _, err := os.Stat(cert); err == nil {
+			paths = appendUnique(paths, cert)
+		}
+	}
+
+	certs := []SSHCertificate{}
+	for _, path := range paths {
+		certs = append(certs, readCertificate(path))
+	}
+	return certs
+}
+
+// readCertificate parses the output of ssh-keygen -L
+func readCertificate(path string) SSHCertificate {
+	cert := SSHCertificate{Path: path}
+	out, err := exec.Command("ssh-keygen", "-L", "-f", path).Output()
+	if err != nil {
+		cert.Err = err
+		return cert
+	}
+
+	inPrincipals := false
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, isField := strings.Cut(line, ":")
+		// Principals are listed one per line below their heading
+		if inPrincipals && !isField {
+			cert.Principals = append(cert.Principals, line)
+			continue
+		}
+		inPrincipals = false
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Key ID":
+			cert.KeyID = strings.Trim(value, `"`)
+		case "Valid":
+			cert.ValidFrom, cert.ValidTo, err = parseCertValidity(value)
+			if err != nil {
+				cert.Err = err
+			}
+		case "Principals":
+			inPrincipals = true
+		}
+	}
+	return cert
+}
+
+// parseCertValidity reads "forever", "from X to Y", "after X" or "before Y"
+func parseCertValidity(value string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	fields := strings.Fields(value)
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "from", "after":
+			from, err = time.ParseInLocation(certTimeLayout, fields[i+1], time.Local)
+		case "to", "before":
+			to, err = time.ParseInLocation(certTimeLayout, fields[i+1], time.Local)
+		}
+		if err != nil {
+			return from, to, fmt.Errorf(tr("unexpected validity %q"), value)
+		}
+	}
+	return from, to, nil
+}
+
+// Problem describes why the certificate is unusable now, or ""
+func (c SSHCertificate) Problem() string {
+	now := time.Now()
+	switch {
+	case c.Err != nil:
+		return fmt.Sprintf(tr("unreadable: %v"), c.Err)
+	case !c.ValidTo.IsZero() && now.After(c.ValidTo):
+		return fmt.Sprintf(tr("expired on %s"), c.ValidTo.Format(time.DateTime))
+	case !c.ValidFrom.IsZero() && now.Before(c.ValidFrom):
+		return fmt.Sprintf(tr("not valid until %s"), c.ValidFrom.Format(time.DateTime))
+	}
+	return ""
+}
+
+// Validity is the certificate's validity window for display
+func (c SSHCertificate) Validity() string {
+	switch {
+	case c.ValidFrom.IsZero() && c.ValidTo.IsZero():
+		return tr("forever")
+	case c.ValidTo.IsZero():
+		return fmt.Sprintf(tr("from %s"), c.ValidFrom.Format(time.DateTime))
+	case c.ValidFrom.IsZero():
+		return fmt.Sprintf(tr("until %s"), c.ValidTo.Format(time.DateTime))
+	}
+	return fmt.Sprintf(tr("%s to %s"), c.ValidFrom.Format(time.DateTime), c.ValidTo.Format(time.DateTime))
+}
+
+// confirmCertificates warns about expired or not yet valid certificates
+// of host and asks whether to connect anyway; Teleport nodes check the
+// tsh login instead
+func confirmCertificates(host SSHHost) bool {
+	if isTeleport(host) {
+		return confirmTeleportLogin(host)
+	}
+	problems := []string{}
+	for _, cert := range hostCertificates(host) {
+		if problem := cert.Problem(); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", cert.Path, problem))
+		}
+	}
+	if len(problems) == 0 {
+		return true
+	}
+
+	fmt.Println(colorize("1;33", tr("\nCertificate warning:")))
+	for _, problem := range problems {
+		fmt.Println("  " + problem)
+	}
+	fmt.Print(tr("Connect anyway? [y/N]: "))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(input)) == "y"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertRenewMargin renews an issued certificate this long before it expires
+const CertRenewMargin = 5 * time.Minute
+
+// certIssueMu keeps parallel connections from issuing the same
+// certificate several times
+var certIssueMu sync.Mutex
+
+// issuedCertPath is where the certificate issued for host is cached: one
+// per tag, key and user, since hosts sharing a tag may sign in differently
+func issuedCertPath(host SSHHost) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "certs")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(certIdentity(host) + "\x00" + effectiveUser(host)))
+	return filepath.Join(dir, fmt.Sprintf("%s-%016x-cert.pub", host.CertTag, hash.Sum64())), nil
+}
+
+// certIdentity is the key the issued certificate is for: the tag's
+// CertIdentity, else the host's first IdentityFile, else id_ed25519
+func certIdentity(host SSHHost) string {
+	switch {
+	case host.CertIdentity != "":
+		return expandHome(host.CertIdentity)
+	case len(host.IdentityFiles) > 0:
+		return expandHome(host.IdentityFiles[0])
+	}
+	return expandHome("~/.ssh/id_ed25519")
+}
+
+// certArgs makes ssh use the issued certificate and its key
+func certArgs(host SSHHost) []string {
+	if host.CertCommand == "" {
+		return nil
+	}
+	path, err := issuedCertPath(host)
+	if err != nil {
+		return nil
+	}
+	return []string{"-i", certIdentity(host), "-o", "CertificateFile=" + path}
+}
+
+// needsRenewal reports whether a cached certificate is missing, unusable
+// or about to expire
+func needsRenewal(cert SSHCertificate) bool {
+	if cert.Problem() != "" {
+		return true
+	}
+	return !cert.ValidTo.IsZero() && time.Until(cert.ValidTo) < CertRenewMargin
+}
+
+// refreshCertificate runs the CertCommand of host's tag when its cached
+// certificate needs renewal. The command gets SSHTUI_HOST, SSHTUI_USER,
+// SSHTUI_PUBKEY and SSHTUI_CERT; what it prints is saved as the
+// certificate, or it writes $SSHTUI_CERT itself
+func refreshCertificate(host SSHHost) error {
+	if host.CertCommand == "" {
+		return nil
+	}
+
+	certIssueMu.Lock()
+	defer certIssueMu.Unlock()
+
+	path, err := issuedCertPath(host)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !needsRenewal(readCertificate(path)) {
+		return nil
+	}
+
+	cmd := exec.Command("sh", "-c", host.CertCommand)
+	cmd.Env = append(os.Environ(),
+		"SSHTUI_HOST="+host.Alias,
+		"SSHTUI_USER="+effectiveUser(host),
+		"SSHTUI_PUBKEY="+certIdentity(host)+".pub",
+		"SSHTUI_CERT="+path,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf(tr("certificate command for tag %s failed: %v %s"), host.CertTag, err, strings.TrimSpace(stderr.String()))
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		if err := os.WriteFile(path, out, 0600); err != nil {
+			return err
+		}
+	}
+
+	if problem := readCertificate(path).Problem(); problem != "" {
+		return fmt.Errorf(tr("certificate issued for tag %s is unusable: %s"), host.CertTag, problem)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestIssuedCertPath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := func(host SSHHost) string {
+		t.Helper()
+		host.CertTag = "prod"
+		p, err := issuedCertPath(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	alice := path(SSHHost{Alias: "web1", User: "alice"})
+	if other := path(SSHHost{Alias: "web2", User: "alice"}); other != alice {
+		t.Errorf("same user and key: %s and %s differ", alice, other)
+	}
+	if bob := path(SSHHost{Alias: "web1", User: "bob"}); bob == alice {
+		t.Errorf("another user shares %s", alice)
+	}
+	if keyed := path(SSHHost{Alias: "web1", User: "alice", IdentityFiles: []string{"~/.ssh/ops"}}); keyed == alice {
+		t.Errorf("another key shares %s", alice)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyToClipboard puts text on the desktop clipboard with the first
+// clipboard tool found, or else asks the terminal to with OSC 52, which
+// also works when sshtui itself runs over ssh. It returns what was used
+func copyToClipboard(text string) (string, error) {
+	tools := [][]string{}
+	switch {
+	case runtime.GOOS == "darwin":
+		tools = append(tools, []string{"pbcopy"})
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		tools = append(tools, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return tool[0], err
+		}
+		return tool[0], nil
+	}
+
+	fmt.Printf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	return tr("the terminal (OSC 52)"), nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/studiowebux/sshtui/pkg/sshconfig"
+)
+
+// trailingNumber finds the number ending an alias, as in web01
+var trailingNumber = regexp.MustCompile(`^(.*?)(\d+)$`)
+
+// suggestClone proposes the next free alias of a numbered host (web1 gives
+// web2, keeping zero padding) and its hostname, with the same number
+// changed or the last IPv4 octet moved by as much; either may be ""
+func suggestClone(host SSHHost, hosts []SSHHost) (alias, hostName string) {
+	m := trailingNumber.FindStringSubmatch(host.Alias)
+	if m == nil {
+		return "", ""
+	}
+	prefix, digits := m[1], m[2]
+	number, _ := strconv.Atoi(digits)
+	next := number
+	for {
+		next++
+		alias = fmt.Sprintf("%s%0*d", prefix, len(digits), next)
+		if _, taken := findHost(hosts, alias); !taken {
+			break
+		}
+	}
+
+	newDigits := fmt.Sprintf("%0*d", len(digits), next)
+	if ip := net.ParseIP(host.HostName).To4(); ip != nil {
+		if last := int(ip[3]) + next - number; last > 0 && last < 255 {
+			ip[3] = byte(last)
+			hostName = ip.String()
+		}
+	} else if i := strings.LastIndex(host.HostName, digits); i >= 0 {
+		hostName = host.HostName[:i] + newDigits + host.HostName[i+len(digits):]
+	}
+	return alias, hostName
+}
+
+// cloneHost copies a host's settings to a new alias and hostname, for the
+// session or written to ~/.ssh/config, and returns the updated host list
+func cloneHost(hosts []SSHHost, host SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	suggestedAlias, suggestedHostName := suggestClone(host, hosts)
+
+	fmt.Printf(tr("\nClone %s (%s)\n"), host.Alias, valueOr(host.HostName, host.Alias))
+	fmt.Printf(tr("New alias [%s]: "), suggestedAlias)
+	input, _ := reader.ReadString('\n')
+	alias := valueOr(strings.TrimSpace(input), suggestedAlias)
+	if alias == "" || strings.ContainsAny(alias, " \t*?") {
+		fmt.Print(tr("Invalid alias. Press Enter..."))
+		reader.ReadString('\n')
+		return hosts
+	}
+	if _, taken := findHost(hosts, alias); taken {
+		fmt.Printf(tr("%s already exists. Press Enter..."), alias)
+		reader.ReadString('\n')
+		return hosts
+	}
+
+	fmt.Printf(tr("HostName [%s]: "), suggestedHostName)
+	input, _ = reader.ReadString('\n')
+	hostName := valueOr(strings.TrimSpace(input), suggestedHostName)
+	if hostName == "" || strings.ContainsAny(hostName, " \t") {
+		fmt.Print(tr("Invalid hostname. Press Enter..."))
+		reader.ReadString('\n')
+		return hosts
+	}
+
+	// With ManagedHosts, sshtui keeps the clone rather than ~/.ssh/config
+	where, save := "~/.ssh/config", appendSSHConfigClone
+	if appConfig.ManagedHosts {
+		where, save = tr("sshtui's hosts"), saveManagedClone
+	}
+	fmt.Printf(tr("Write it to %s? [y/N]: "), where)
+	input, _ = reader.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+		clone := host
+		clone.Alias, clone.HostName = alias, hostName
+		if fromSSHConfig(host) {
+			// ssh reads the original's block, with the hostname overridden
+			clone.CloneOf = valueOr(host.CloneOf, host.Alias)
+		}
+		clone.Hotkey = ""
+		fmt.Printf(tr("%s added until the config is reloaded. Press Enter..."), alias)
+		reader.ReadString('\n')
+		return append(hosts, clone)
+	}
+
+	if err := save(host, alias, hostName); err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return hosts
+	}
+	newHosts, err := loadHosts(appConfig, false)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return hosts
+	}
+	fmt.Printf(tr("%s written to %s. Press Enter..."), alias, where)
+	reader.ReadString('\n')
+	return newHosts
+}
+
+// hostBlock returns the lines of the Host block of alias in ssh config
+// lines, from its Host line to the next Host or Match, or nil
+func hostBlock(lines []string, alias string) []string {
+	start := -1
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		key := strings.ToLower(fields[0])
+		if start >= 0 && (key == "host" || key == "match") {
+			return lines[start:i]
+		}
+		if key == "host" && strings.Join(fields[1:], " ") == alias {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	return lines[start:]
+}
+
+// cloneBlock renders the ssh config block of the clone: a copy of the
+// original's block when it has one, with Host and HostName replaced,
+// otherwise the settings sshtui knows of
+func cloneBlock(original []string, host SSHHost, alias, hostName string) []string {
+	if original == nil {
+		block := []string{"Host " + alias, "    HostName " + hostName}
+		if host.User != "" {
+			block = append(block, "    User "+host.User)
+		}
+		if host.Port != "" {
+			block = append(block, "    Port "+host.Port)
+		}
+		if host.ProxyJump != "" {
+			block = append(block, "    ProxyJump "+host.ProxyJump)
+		}
+		return block
+	}
+
+	block := []string{}
+	hasHostName := false
+	for i, line := range original {
+		fields := strings.Fields(line)
+		switch {
+		case i == 0:
+			line = "Host " + alias
+		case len(fields) > 0 && strings.ToLower(fields[0]) == "hostname":
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			line = indent + fields[0] + " " + hostName
+			hasHostName = true
+		}
+		block = append(block, line)
+	}
+	if !hasHostName {
+		block = append(block[:1], append([]string{"    HostName " + hostName}, block[1:]...)...)
+	}
+	// Drop the blank lines that separated the original from the next block
+	for len(block) > 1 && strings.TrimSpace(block[len(block)-1]) == "" {
+		block = block[:len(block)-1]
+	}
+	return block
+}
+
+// cloneOriginal returns the block a clone of host copies: its own in
+// ~/.ssh/config or among the managed hosts, nil for other providers' hosts
+func cloneOriginal(host SSHHost, sshConfig []byte) []string {
+	alias := valueOr(host.CloneOf, host.Alias)
+	switch host.Source {
+	case managedSource:
+		return managedBlock(alias)
+	case "", sshConfigSource:
+		return hostBlock(strings.Split(string(sshConfig), "\n"), alias)
+	}
+	return nil
+}
+
+// saveManagedClone adds the clone to the managed hosts, its settings
+// copied from the original's block
+func saveManagedClone(host SSHHost, alias, hostName string) error {
+	var sshConfig []byte
+	if configPath, err := sshconfig.DefaultPath(); err == nil {
+		sshConfig, _ = os.ReadFile(configPath)
+	}
+	return addManagedHost(alias, trimBlock(cloneBlock(cloneOriginal(host, sshConfig), host, alias, hostName)))
+}
+
+// trimBlock returns the settings of a Host block as managed hosts keep
+// them, without the Host line, indentation, blank lines and comments
+func trimBlock(block []string) []string {
+	settings := []string{}
+	for _, line := range block[1:] {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			settings = append(settings, line)
+		}
+	}
+	return settings
+}
+
+// sshConfigPath returns ~/.ssh/config, where blocks are appended
+func sshConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ssh", "config"), nil
+}
+
+// appendSSHConfigClone appends the clone's Host block to ~/.ssh/config
+func appendSSHConfigClone(host SSHHost, alias, hostName string) error {
+	configPath, err := sshConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return appendSSHConfig(cloneBlock(cloneOriginal(host, data), host, alias, hostName))
+}
+
+// appendSSHConfig appends Host blocks to ~/.ssh/config, a blank line
+// before each
+func appendSSHConfig(blocks ...[]string) error {
+	configPath, err := sshConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	texts := []string{}
+	for _, block := range blocks {
+		texts = append(texts, strings.Join(block, "\n")+"\n")
+	}
+	text := strings.Join(texts, "\n")
+	if len(data) > 0 {
+		text = "\n" + text
+		if data[len(data)-1] != '\n' {
+			text = "\n" + text
+		}
+	}
+
+	file, err := os.OpenFile(configPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
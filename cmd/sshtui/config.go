@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/studiowebux/sshtui/pkg/sshconfig"
+)
+
+// SSHHost represents a parsed SSH host
+type SSHHost struct {
+	Alias    string
+	HostName string
+	User     string
+	Port     string
+	Forwards []PortForward
+	Extras   []string // extra ssh options for this connection
+	Tags     []string // from sshtui config Host blocks
+	Source   string   // name of the provider the host came from
+	Status   string   // state reported by the provider, e.g. "offline"
+	Tee      string   // local command receiving session output
+	CloneOf  string   // ssh config host an unsaved clone borrows its settings from
+	RuleUser string   // user from a Tag rule, for hosts without a User
+
+	Meta map[string]string // inventory metadata (site, rack, role...) from providers
+
+	StatusBar bool   // keep a status bar on the top row while attached
+	Hotkey    string // menu key connecting to the host, e.g. F1
+
+	ExternalWindow  bool   // connect in a new terminal window rather than a session
+	TerminalProfile string // profile of that window, for iTerm2 or a Terminal command
+
+	RcSnippet        string         // shell lines run in interactive sessions, from RcFile
+	ShellIntegration bool           // bash prints OSC 133 prompt marks
+	PromptPattern    *regexp.Regexp // shell prompts in its scrollback, nil for the default
+	Dashboard        string         // when the menu polls its load and disks, see DashboardAlways
+	HealthWatch      bool           // probed in the background, its outages reported
+	TOTPSecret       string         // command printing the TOTP secret, see hostTOTPKey
+	TOTP             string         // TOTPType to type codes at prompts, else TOTPShow
+
+	CertTag      string // tag whose CertCommand issues this host's certificate
+	CertCommand  string
+	CertIdentity string
+
+	RemoteCommand    string   // from ssh config, run instead of a shell
+	ProxyJump        string   // from ssh config, or a Tag rule for other providers' hosts
+	ProxyCommand     string   // from ssh config, unexpanded; see sshRoute.Proxy
+	IdentityFiles    []string // from ssh config
+	CertificateFiles []string // from ssh config
+	RequestTTY       string   // from ssh config
+	Keywords         []string // set in its own ssh config block, see settingSources
+	Command          string   // sshtui default command for interactive sessions
+	Connect          string   // sshtui command line connecting instead of ssh
+	Encoding         string   // character set of the host's terminal, see normalizeEncoding
+}
+
+// PortForward represents an SSH port forward
+type PortForward = sshconfig.Forward
+
+// parseSSHConfig lists the hosts of ~/.ssh/config
+func parseSSHConfig() ([]SSHHost, error) {
+	configPath, err := sshconfig.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := sshconfig.ParseFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []SSHHost{}
+	for _, h := range parsed {
+		hosts = append(hosts, sshConfigHost(h))
+	}
+	return hosts, nil
+}
+
+// sshConfigHost takes the settings sshtui uses from an ssh config block
+func sshConfigHost(h sshconfig.Host) SSHHost {
+	return SSHHost{
+		Alias:            h.Alias,
+		HostName:         h.HostName,
+		User:             h.User,
+		Port:             h.Port,
+		Forwards:         h.Forwards,
+		RemoteCommand:    h.RemoteCommand,
+		ProxyJump:        h.ProxyJump,
+		ProxyCommand:     h.ProxyCommand,
+		IdentityFiles:    h.IdentityFiles,
+		CertificateFiles: h.CertificateFiles,
+		RequestTTY:       h.RequestTTY,
+		Keywords:         h.Keywords,
+	}
+}
+
+func findHost(hosts []SSHHost, alias string) (SSHHost, bool) {
+	for _, host := range hosts {
+		if host.Alias == alias {
+			return host, true
+		}
+	}
+	return SSHHost{}, false
+}
+
+// parseForwardSpec parses a quick forward such as "L 8080 localhost:80" or "D 1080"
+func parseForwardSpec(spec string) *PortForward {
+	parts := strings.Fields(spec)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	value := strings.Join(parts[1:], " ")
+	switch strings.ToUpper(parts[0]) {
+	case "L":
+		return sshconfig.ParseLocalForward(value)
+	case "R":
+		return sshconfig.ParseRemoteForward(value)
+	case "D":
+		return sshconfig.ParseDynamicForward(value)
+	}
+	return nil
+}
+
+func buildSSHArgs(host SSHHost) []string {
+	args := []string{}
+
+	// Add port forwards
+	for _, fwd := range host.Forwards {
+		switch fwd.Type {
+		case "L":
+			args = append(args, "-L", fmt.Sprintf("%s:%s", fwd.LocalPort, fwd.RemoteAddr))
+		case "R":
+			args = append(args, "-R", fmt.Sprintf("%s:%s", fwd.LocalPort, fwd.RemoteAddr))
+		case "D":
+			args = append(args, "-D", fwd.LocalPort)
+		}
+	}
+
+	args = append(args, host.Extras...)
+	args = append(args, certArgs(host)...)
+	args = append(args, sshTarget(host)...)
+	return args
+}
+
+// buildShellArgs returns ssh arguments for an interactive session, running
+// the host's default command on a terminal when one is set, else its rc
+// snippet with the shell
+func buildShellArgs(host SSHHost) []string {
+	command := host.Command
+	if command == "" && host.RemoteCommand == "" && (host.RcSnippet != "" || host.ShellIntegration) {
+		// The snippet comes with the shell; other commands go without it
+		command = rcCommand(host.RcSnippet, host.ShellIntegration)
+	}
+
+	args := []string{}
+	switch {
+	case command != "":
+		args = append(args, "-t")
+		if host.RemoteCommand != "" {
+			args = append(args, "-o", "RemoteCommand=none")
+		}
+	case host.RemoteCommand != "" && host.RequestTTY == "":
+		// ssh skips the terminal for a RemoteCommand unless asked
+		args = append(args, "-t")
+	}
+
+	args = append(args, buildSSHArgs(host)...)
+	if command != "" {
+		args = append(args, command)
+	}
+	return args
+}
+
+// buildExecArgs returns ssh arguments running command on host, in place
+// of any RemoteCommand from ssh config
+func buildExecArgs(host SSHHost, command string) []string {
+	args := append(overrideRemoteCommand(host), buildSSHArgs(host)...)
+	return append(args, command)
+}
+
+// overrideRemoteCommand disables the host's RemoteCommand, which ssh
+// refuses to combine with a command line or -N
+func overrideRemoteCommand(host SSHHost) []string {
+	if host.RemoteCommand == "" {
+		return nil
+	}
+	return []string{"-o", "RemoteCommand=none"}
+}
+
+// sshTarget returns the destination arguments for ssh. Hosts from ssh
+// config, and managed ones, are reached by alias; other providers' hosts
+// are spelled out
+func sshTarget(host SSHHost) []string {
+	if host.CloneOf != "" {
+		return []string{"-o", "HostName=" + host.HostName, host.CloneOf}
+	}
+	if fromSSHConfig(host) {
+		if host.User == "" && host.RuleUser != "" {
+			return []string{"-l", host.RuleUser, host.Alias}
+		}
+		return []string{host.Alias}
+	}
+
+	args := []string{}
+	if host.ProxyJump != "" {
+		args = append(args, jumpArgs(host.ProxyJump)...)
+	}
+	if user := effectiveUser(host); user != "" {
+		args = append(args, "-l", user)
+	}
+	if host.Port != "" {
+		args = append(args, "-p", host.Port)
+	}
+	target := host.HostName
+	if target == "" {
+		target = host.Alias
+	}
+	return append(args, target)
+}
+
+// effectiveUser is the user ssh logs in as: the host's own User, else the
+// user given by its tags, else "" for ssh's default
+func effectiveUser(host SSHHost) string {
+	if host.User != "" {
+		return host.User
+	}
+	return host.RuleUser
+}
+
+// splitArgs splits a command line into words, honoring single and double quotes
+func splitArgs(line string) []string {
+	args := []string{}
+	var current strings.Builder
+	inWord := false
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// buildForwardArgs builds args for a forwarding-only connection (no shell)
+func buildForwardArgs(host SSHHost, forwards []PortForward) []string {
+	fwdHost := host
+	fwdHost.Forwards = forwards
+
+	// BatchMode: there is no terminal to answer prompts on
+	args := []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes"}
+	args = append(args, overrideRemoteCommand(host)...)
+	return append(args, buildSSHArgs(fwdHost)...)
+}
+
+func displayForwards(forwards []PortForward) string {
+	if len(forwards) == 0 {
+		return ""
+	}
+
+	parts := []string{}
+	for _, fwd := range forwards {
+		switch fwd.Type {
+		case "L":
+			parts = append(parts, fmt.Sprintf("L:%s→%s", fwd.LocalPort, fwd.RemoteAddr))
+		case "R":
+			parts = append(parts, fmt.Sprintf("R:%s→%s", fwd.LocalPort, fwd.RemoteAddr))
+		case "D":
+			parts = append(parts, fmt.Sprintf("D:%s", fwd.LocalPort))
+		}
+	}
+
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// jumpHosts returns the hostnames of host's ProxyJump chain, without
+// users and ports
+func jumpHosts(host SSHHost) []string {
+	names := []string{}
+	if host.ProxyJump == "" {
+		return names
+	}
+	for _, hop := range strings.Split(host.ProxyJump, ",") {
+		_, name, _ := parseTarget(strings.TrimPrefix(strings.TrimSpace(hop), "ssh://"))
+		names = append(names, name)
+	}
+	return names
+}
+
+// failedJump returns the jump host an ssh error line is about, or ""
+func failedJump(host SSHHost, line string) string {
+	for _, name := range jumpHosts(host) {
+		if strings.Contains(line, name) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// connectPlaceholder matches the {name} fields of a Connect template
+var connectPlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+// sessionCommand returns the command line of an interactive session on
+// host, program first: ssh, the tool of a provider reaching its hosts
+// without ssh, or the host's Connect command run by sh, which wins; under
+// luit for encodings sshtui does not convert
+func sessionCommand(host SSHHost) ([]string, error) {
+	argv, err := hostCommand(host)
+	if err != nil {
+		return nil, err
+	}
+	return encodingCommand(host, argv)
+}
+
+// hostCommand returns the command line reaching host, before any encoding
+// conversion
+func hostCommand(host SSHHost) ([]string, error) {
+	if host.Connect != "" {
+		line, err := expandConnect(host)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"sh", "-c", line}, nil
+	}
+	if argv := providerCommand(host); argv != nil {
+		return argv, nil
+	}
+	return append([]string{"ssh"}, buildShellArgs(host)...), nil
+}
+
+// providerCommand returns the command line of a session on a host its
+// provider reaches without ssh, nil for other hosts
+func providerCommand(host SSHHost) []string {
+	switch {
+	case isSSM(host):
+		return ssmCommand(host)
+	case isTeleport(host):
+		return teleportCommand(host)
+	}
+	return nil
+}
+
+// providerRunCommand returns the command line running a multi-host job on
+// a host its provider reaches without ssh, which cannot take scripts
+func providerRunCommand(host SSHHost, job MultiJob) ([]string, error) {
+	if job.Script != "" {
+		return nil, fmt.Errorf(tr("scripts cannot be uploaded to %s hosts, run a command"), host.Source)
+	}
+	if isSSM(host) {
+		return ssmRunCommand(host, job.Command)
+	}
+	return teleportCommand(host, job.Command), nil
+}
+
+// connectLine returns the command line connecting to host when it is not
+// ssh, for host details; "" for ssh
+func connectLine(host SSHHost) (string, error) {
+	if host.Connect != "" {
+		return expandConnect(host)
+	}
+	return strings.Join(providerCommand(host), " "), nil
+}
+
+// expandConnect fills the placeholders of host's Connect template:
+// {alias}, {hostname}, {user}, {port} and the provider metadata of the
+// host, such as {id} or {zone}. Values are shell-quoted; one the host
+// does not have is an error rather than an empty argument
+func expandConnect(host SSHHost) (string, error) {
+	var missing []string
+	line := connectPlaceholder.ReplaceAllStringFunc(host.Connect, func(field string) string {
+		name := connectPlaceholder.FindStringSubmatch(field)[1]
+		value := connectValue(host, name)
+		if value == "" {
+			missing = append(missing, field)
+		}
+		return shellQuote(value)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf(tr("Connect for %s: no value for %s"), host.Alias, strings.Join(missing, ", "))
+	}
+	return line, nil
+}
+
+// connectValue returns what a placeholder stands for, "" when unknown
+func connectValue(host SSHHost, name string) string {
+	switch strings.ToLower(name) {
+	case "alias":
+		return host.Alias
+	case "hostname":
+		return valueOr(host.HostName, host.Alias)
+	case "user":
+		return effectiveUser(host)
+	case "port":
+		return host.Port
+	}
+	return host.Meta[strings.ToLower(name)]
+}
+
+// connectProgram names what a host is reached with when it is not ssh,
+// the first word of its Connect command or its provider, for the host list
+func connectProgram(host SSHHost) string {
+	if host.Connect == "" && providerCommand(host) != nil {
+		return host.Source
+	}
+	fields := strings.Fields(host.Connect)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ConnectTestTimeout bounds a connection test
+const ConnectTestTimeout = 30 * time.Second
+
+// authMethodPattern finds the method in ssh -v's "Authenticated to ...
+// using "publickey"." line
+var authMethodPattern = regexp.MustCompile(`Authenticated to .* using "([^"]+)"`)
+
+// sshChatter are ssh -v lines that are neither debug output nor banner
+var sshChatter = []string{
+	"OpenSSH_",
+	"Authenticated to ",
+	"Transferred: ",
+	"Bytes per second",
+	"Warning: Permanently added",
+}
+
+// ConnectTest is what a BatchMode ssh run learned about a host
+type ConnectTest struct {
+	Connected     time.Duration // TCP connection established
+	Authenticated time.Duration // login done
+	Total         time.Duration
+	AuthMethod    string
+	Server        string   // remote software version
+	Messages      []string // banner on success, errors otherwise
+	Cause         string   // likely cause of a failure
+	FailedJump    string   // jump host that failed, when the host was not reached
+	Err           error
+}
+
+// runConnectTest runs `ssh -v -o BatchMode=yes host true`, timing the
+// connection and login as ssh reports them. Hosts behind a ProxyJump are
+// tested through it, and a failing jump host is told apart from the host
+func runConnectTest(host SSHHost) ConnectTest {
+	result := ConnectTest{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectTestTimeout)
+	defer cancel()
+
+	if err := refreshCertificate(host); err != nil {
+		result.Err = err
+		return result
+	}
+
+	args := append([]string{"-v", "-o", "BatchMode=yes"}, buildExecArgs(host, "true")...)
+	cmd := commander.Command(ctx, "ssh", args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.Contains(line, "Connection established"):
+			result.Connected = time.Since(start)
+		case strings.Contains(line, "remote software version "):
+			result.Server = line[strings.Index(line, "remote software version ")+len("remote software version "):]
+		}
+		if m := authMethodPattern.FindStringSubmatch(line); m != nil {
+			result.Authenticated = time.Since(start)
+			result.AuthMethod = m[1]
+		}
+		if cause := matchFailure(line); cause != "" {
+			result.Cause = cause
+			result.FailedJump = failedJump(host, line)
+		}
+		if !strings.HasPrefix(line, "debug") && !isSSHChatter(line) && strings.TrimSpace(line) != "" {
+			result.Messages = append(result.Messages, line)
+		}
+	}
+
+	err = cmd.Wait()
+	result.Total = time.Since(start)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf(tr("timed out after %v"), ConnectTestTimeout)
+	case err != nil && cmd.ProcessState.ExitCode() == sshConnectFailure:
+		reason := result.Cause
+		if len(result.Messages) > 0 {
+			reason = result.Messages[len(result.Messages)-1]
+		}
+		if reason == "" {
+			reason = err.Error()
+		}
+		if result.FailedJump != "" {
+			result.Err = JumpError{Jump: result.FailedJump, Reason: result.Cause}
+		} else {
+			result.Err = errors.New(reason)
+		}
+	}
+	return result
+}
+
+// JumpError is a failure of the jump host in front of a host, which says
+// nothing of the host itself
+type JumpError struct {
+	Jump   string
+	Reason string
+}
+
+func (e JumpError) Error() string {
+	return fmt.Sprintf(tr("via %s: %s"), e.Jump, e.Reason)
+}
+
+func isSSHChatter(line string) bool {
+	for _, prefix := range sshChatter {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// testConnection checks that a host can be reached and logged into
+// without a prompt, without opening a session
+func testConnection(host SSHHost) {
+	clearScreen()
+	printHeader(tr("Connection test: ") + host.Alias)
+	fmt.Printf(tr("Running ssh -o BatchMode=yes %s true...\n\n"), host.Alias)
+
+	result := runConnectTest(host)
+	recordConnect(host.Alias, result.Err)
+	recordHealth(host.Alias, testHealth(host, result))
+
+	if host.ProxyJump != "" {
+		fmt.Printf(tr("Via:           %s\n"), host.ProxyJump)
+	} else if route := resolveRoute(host); route.ProxyCommand != "" {
+		fmt.Printf(tr("Via:           %s\n"), route.Proxy())
+	}
+	switch {
+	case result.FailedJump != "":
+		// The host may well be up; only the way to it is known to be down
+		fmt.Printf("%s %v\n", colorize("33", "?"), colorize("33", result.Err.Error()))
+		fmt.Printf(tr("Jump host %s failed (%s), %s was not reached\n"), result.FailedJump, result.Cause, host.Alias)
+	case result.Err != nil:
+		fmt.Printf("%s %v\n", failMark(), colorize("31", result.Err.Error()))
+		if result.Cause != "" {
+			fmt.Printf(tr("Likely cause: %s\n"), colorize("1;31", result.Cause))
+		}
+		if result.AuthMethod == "" && result.Connected > 0 {
+			fmt.Println(tr("Reached the host, but no key or agent login worked (BatchMode skips password prompts)"))
+		}
+	default:
+		fmt.Printf(tr("%s Logged in as %s\n"), okMark(), valueOr(effectiveUser(host), tr("the default user")))
+	}
+
+	if result.Server != "" {
+		fmt.Printf(tr("Server:        %s\n"), result.Server)
+	}
+	if result.AuthMethod != "" {
+		fmt.Printf(tr("Auth method:   %s\n"), result.AuthMethod)
+	}
+	if result.Connected > 0 {
+		fmt.Printf(tr("Connected in:  %v\n"), result.Connected.Round(time.Millisecond))
+	}
+	if result.Authenticated > 0 {
+		fmt.Printf(tr("Logged in in:  %v\n"), result.Authenticated.Round(time.Millisecond))
+	}
+	fmt.Printf(tr("Total:         %v\n"), result.Total.Round(time.Millisecond))
+
+	if result.Err == nil && len(result.Messages) > 0 {
+		fmt.Println(tr("\nBanner:"))
+		for _, line := range result.Messages {
+			fmt.Println("  " + line)
+		}
+	}
+
+	fmt.Print(tr("\nPress Enter..."))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// testHealth is what a connection test says of a host's reachability
+func testHealth(host SSHHost, result ConnectTest) HostHealth {
+	health := HostHealth{Via: host.ProxyJump}
+	if health.Via == "" && host.ProxyCommand != "" {
+		health.Via = tr("proxy command")
+	}
+	switch {
+	case result.FailedJump != "":
+		health.JumpDown, health.Error = result.FailedJump, result.Cause
+	case result.Err == nil || result.Connected > 0:
+		// A host refusing the login still answered
+		health.Reachable = true
+		health.Latency = result.Connected
+		if health.Latency == 0 {
+			health.Latency = result.Total
+		}
+	default:
+		health.Error = result.Err.Error()
+	}
+	return health
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
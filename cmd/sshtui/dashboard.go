@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDashboardInterval = 5 * time.Minute
+	MinDashboardInterval     = 10 * time.Second
+	DashboardTimeout         = 15 * time.Second // per host, connection included
+	DashboardDiskWarn        = 90               // percent of a filesystem shown as full
+	MaxDashboardProbes       = 8                // hosts polled at once
+)
+
+// Dashboard settings: hosts polled all the time, or only while a session
+// to them is open
+const (
+	DashboardOff      = "no"
+	DashboardAlways   = "yes"
+	DashboardSessions = "sessions"
+)
+
+// dashboardCommand prints the load and uptime, the CPU count and the
+// filesystems, in sections split by --; Linux has /proc, BSDs and macOS
+// fall back to uptime and sysctl
+const dashboardCommand = `(cat /proc/loadavg /proc/uptime || uptime) 2>/dev/null; echo --; (nproc || sysctl -n hw.ncpu) 2>/dev/null; echo --; df -Pk 2>/dev/null`
+
+// HostDashboard is the latest poll of a host's stats
+type HostDashboard struct {
+	Checked time.Time
+	Load    string        // 1-minute load average, "" when unknown
+	CPUs    int           // 0 when unknown
+	Uptime  time.Duration // 0 when unknown
+	Disk    string        // fullest filesystem, "/var 71%"
+	Full    []string      // filesystems at DashboardDiskWarn or more
+	Error   string
+}
+
+var (
+	dashboards       = map[string]HostDashboard{}
+	dashboardMu      sync.Mutex
+	dashboardRunning bool
+	dashboardPolled  time.Time
+)
+
+// dashboardHosts are the hosts to poll now: those with Dashboard yes, and
+// those with Dashboard sessions while a session to them is open
+func dashboardHosts(hosts []SSHHost) []SSHHost {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	polled := []SSHHost{}
+	for _, host := range hosts {
+		switch host.Dashboard {
+		case DashboardAlways:
+			polled = append(polled, host)
+		case DashboardSessions:
+			for _, s := range sessions {
+				if s.Host.Alias == host.Alias && s.running() {
+					polled = append(polled, host)
+					break
+				}
+			}
+		}
+	}
+	return polled
+}
+
+// dashboardDue tells when the hosts' stats need polling again: every
+// DashboardInterval, and as soon as a host without stats is to be polled
+func dashboardDue(hosts []SSHHost) bool {
+	polled := dashboardHosts(hosts)
+	dashboardMu.Lock()
+	defer dashboardMu.Unlock()
+	if readOnly || dashboardRunning || len(polled) == 0 {
+		return false
+	}
+	if time.Since(dashboardPolled) > appConfig.DashboardInterval {
+		return true
+	}
+	for _, host := range polled {
+		if _, ok := dashboards[host.Alias]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshDashboards polls the hosts with a dashboard in the background,
+// a few at a time, unless a poll is still running
+func refreshDashboards(hosts []SSHHost) {
+	polled := dashboardHosts(hosts)
+	dashboardMu.Lock()
+	if dashboardRunning {
+		dashboardMu.Unlock()
+		return
+	}
+	dashboardRunning = true
+	dashboardMu.Unlock()
+
+	go func() {
+		slots := make(chan bool, MaxDashboardProbes)
+		var wg sync.WaitGroup
+		for _, host := range polled {
+			wg.Add(1)
+			slots <- true
+			go func(h SSHHost) {
+				defer wg.Done()
+				stats := pollDashboard(h)
+				stats.Checked = time.Now()
+				dashboardMu.Lock()
+				dashboards[h.Alias] = stats
+				dashboardMu.Unlock()
+				<-slots
+			}(host)
+		}
+		wg.Wait()
+
+		dashboardMu.Lock()
+		dashboardRunning, dashboardPolled = false, time.Now()
+		dashboardMu.Unlock()
+	}()
+}
+
+// pollDashboard runs dashboardCommand on a host in BatchMode, through a
+// running ControlMaster when there is one, without its forwards
+func pollDashboard(host SSHHost) HostDashboard {
+	ctx, cancel := context.WithTimeout(context.Background(), DashboardTimeout)
+	defer cancel()
+	args := append([]string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5", "-o", "ClearAllForwardings=yes"}, overrideRemoteCommand(host)...)
+	args = append(args, sshTarget(host)...)
+	out, err := commander.Command(ctx, "ssh", append(args, dashboardCommand)...).Output()
+	if err != nil && len(out) == 0 {
+		return HostDashboard{Error: err.Error()}
+	}
+	return parseDashboard(string(out))
+}
+
+// parseDashboard reads the output of dashboardCommand
+func parseDashboard(out string) HostDashboard {
+	stats := HostDashboard{}
+	sections := strings.SplitN(out, "--\n", 3)
+	for len(sections) < 3 {
+		sections = append(sections, "")
+	}
+
+	for _, line := range strings.Split(sections[0], "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 5 && strings.Contains(fields[3], "/"):
+			// /proc/loadavg
+			stats.Load = fields[0]
+		case len(fields) == 2 && stats.Load != "":
+			// /proc/uptime, in seconds
+			if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				stats.Uptime = time.Duration(seconds) * time.Second
+			}
+		case strings.Contains(line, "load average"):
+			// uptime: "... load average: 0.42, 0.30, 0.25" ("averages" on macOS)
+			_, loads, _ := strings.Cut(line, ":")
+			if loadFields := strings.Fields(strings.ReplaceAll(loads, ",", " ")); len(loadFields) > 0 {
+				stats.Load = loadFields[0]
+			}
+		}
+	}
+	stats.CPUs, _ = strconv.Atoi(strings.TrimSpace(sections[1]))
+
+	fullest := -1
+	scanner := bufio.NewScanner(strings.NewReader(sections[2]))
+	for scanner.Scan() {
+		// Filesystem 1024-blocks Used Available Capacity Mounted-on
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || !strings.HasPrefix(fields[0], "/") || strings.HasPrefix(fields[0], "/dev/loop") {
+			// Pseudo filesystems, and snaps' read-only images always full
+			continue
+		}
+		use, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+		if err != nil {
+			continue
+		}
+		mount := strings.Join(fields[5:], " ")
+		if use >= DashboardDiskWarn {
+			stats.Full = append(stats.Full, fmt.Sprintf("%s %d%%", mount, use))
+		}
+		if use > fullest {
+			fullest, stats.Disk = use, fmt.Sprintf("%s %d%%", mount, use)
+		}
+	}
+	return stats
+}
+
+func hostDashboard(alias string) (HostDashboard, bool) {
+	dashboardMu.Lock()
+	defer dashboardMu.Unlock()
+	stats, ok := dashboards[alias]
+	return stats, ok
+}
+
+// dashboardLabel shows a host's stats in the menu: the load (over the CPU
+// count), uptime and fullest filesystem, or the full ones in red
+func dashboardLabel(host SSHHost) string {
+	if host.Dashboard == "" || host.Dashboard == DashboardOff {
+		return ""
+	}
+	stats, ok := hostDashboard(host.Alias)
+	if !ok {
+		return ""
+	}
+	if stats.Error != "" {
+		return colorize("2", tr(" [no stats]"))
+	}
+
+	parts := []string{}
+	if stats.Load != "" {
+		load, color := tr("load ")+stats.Load, "2"
+		if stats.CPUs > 0 {
+			load += fmt.Sprintf("/%d", stats.CPUs)
+			if value, err := strconv.ParseFloat(stats.Load, 64); err == nil && value > float64(stats.CPUs) {
+				color = "33"
+			}
+		}
+		parts = append(parts, colorize(color, load))
+	}
+	if stats.Uptime > 0 {
+		uptime := formatDuration(int64(stats.Uptime.Seconds()))
+		if days := int(stats.Uptime.Hours() / 24); days > 0 {
+			uptime = fmt.Sprintf(tr("%dd"), days)
+		}
+		parts = append(parts, colorize("2", tr("up ")+uptime))
+	}
+	if len(stats.Full) > 0 {
+		parts = append(parts, colorize("1;31", fmt.Sprintf(tr("%s full"), strings.Join(stats.Full, ", "))))
+	} else if stats.Disk != "" {
+		parts = append(parts, colorize("2", stats.Disk))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// dashboardSnapshot changes whenever a poll result does, for menu redraws
+func dashboardSnapshot() string {
+	dashboardMu.Lock()
+	defer dashboardMu.Unlock()
+	latest := time.Time{}
+	for _, stats := range dashboards {
+		if stats.Checked.After(latest) {
+			latest = stats.Checked
+		}
+	}
+	return latest.String()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const DebugTailLines = 25
+
+// debugConnect is enabled by --debug-connect
+var debugConnect bool
+
+// connectFailure maps an ssh -vvv log pattern to a likely cause
+type connectFailure struct {
+	Pattern string
+	Cause   string
+}
+
+var connectFailures = []connectFailure{
+	{"REMOTE HOST IDENTIFICATION HAS CHANGED", "host key changed"},
+	{"Host key verification failed", "host key verification"},
+	{"Could not resolve hostname", "DNS resolution"},
+	{"Name or service not known", "DNS resolution"},
+	{"nodename nor servname provided", "DNS resolution"},
+	{"Connection timed out", "timeout"},
+	{"Operation timed out", "timeout"},
+	{"Connection refused", "connection refused"},
+	{"No route to host", "network unreachable"},
+	{"Network is unreachable", "network unreachable"},
+	{"Permission denied", "authentication failure"},
+	{"Too many authentication failures", "authentication failure"},
+	{"No more authentication methods", "authentication failure"},
+}
+
+func newDebugLog() (string, error) {
+	file, err := os.CreateTemp("", "sshtui-debug-*.log")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+	return file.Name(), nil
+}
+
+// matchFailure returns the likely cause for a log line, or ""
+func matchFailure(line string) string {
+	for _, f := range connectFailures {
+		if strings.Contains(line, f.Pattern) {
+			return tr(f.Cause)
+		}
+	}
+	return ""
+}
+
+// showConnectDebug displays the tail of the debug log if the session failed
+func showConnectDebug(session *Session) {
+	// The monitor goroutine records the exit status shortly after the PTY
+	// closes; until it marks the session ended, Wait may still be setting it
+	deadline := time.Now().Add(time.Second)
+	var state *os.ProcessState
+	for {
+		sessionsMu.RLock()
+		active := session.running()
+		if !active {
+			state = session.Cmd.ProcessState
+		}
+		sessionsMu.RUnlock()
+		if !active {
+			break
+		}
+		if time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if state == nil || state.Success() {
+		return
+	}
+
+	data, err := os.ReadFile(session.DebugLog)
+	if err != nil {
+		return
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	cause := ""
+	for _, line := range lines {
+		if c := matchFailure(line); c != "" {
+			cause = c
+		}
+	}
+
+	start := 0
+	if len(lines) > DebugTailLines {
+		start = len(lines) - DebugTailLines
+	}
+
+	fmt.Println()
+	printHeader(tr("Connect debug: ") + session.Alias)
+	fmt.Printf(tr("ssh exited with status %d\n"), state.ExitCode())
+	if cause != "" {
+		fmt.Printf(tr("Likely cause: %s\n"), colorize("1;31", cause))
+	}
+	fmt.Printf(tr("Full log: %s\n\n"), session.DebugLog)
+
+	for _, line := range lines[start:] {
+		if matchFailure(line) != "" {
+			if plainMode {
+				line = "CAUSE: " + line
+			}
+			fmt.Println(colorize("1;31", line))
+		} else {
+			fmt.Println(line)
+		}
+	}
+
+	fmt.Print(tr("\nPress Enter..."))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
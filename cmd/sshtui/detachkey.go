@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"time"
+)
+
+const (
+	// DetachKey (Ctrl+Space, a NUL byte) detaches; pressed twice it
+	// reaches the session, for emacs set-mark or a tmux prefix
+	DetachKey = 0x00
+	// DetachEscapeWait is how long a key of sessionKeys, Ctrl+Space
+	// among them, waits for its pair
+	DetachEscapeWait = 400 * time.Millisecond
+)
+
+// sessionKeys are the keys sshtui takes from the input of an attached
+// session. Like DetachKey, each reaches the session when pressed twice, so
+// remote applications using it keep it
+var sessionKeys = []byte{DetachKey, SendFileKey, StatsKey, PauseKey}
+
+// nextSessionKey looks for the first of sessionKeys pressed alone in
+// typed input: data is what comes before it, for the session, each pair
+// made one key; rest is what follows it. found is false when no key was
+// pressed alone, data being all the input. When the input ends with one
+// of the keys, more is asked for what follows, nil when nothing does
+func nextSessionKey(input []byte, more func() []byte) (data []byte, key byte, found bool, rest []byte) {
+	if bytes.IndexAny(input, string(sessionKeys)) < 0 {
+		return input, 0, false, nil
+	}
+	data = make([]byte, 0, len(input))
+	for i := 0; i < len(input); i++ {
+		if bytes.IndexByte(sessionKeys, input[i]) < 0 {
+			data = append(data, input[i])
+			continue
+		}
+		if i+1 == len(input) {
+			next := more()
+			if len(next) == 0 {
+				return data, input[i], true, nil
+			}
+			input = append(input, next...)
+		}
+		if input[i+1] != input[i] {
+			return data, input[i], true, input[i+1:]
+		}
+		data = append(data, input[i])
+		i++
+	}
+	return data, 0, false, nil
+}
+
+// moreStdin reads what is typed within DetachEscapeWait, or nil
+func moreStdin() []byte {
+	if !term.InputWithin(DetachEscapeWait) {
+		return nil
+	}
+	buf := make([]byte, StdinBufSize)
+	n, err := term.Read(buf)
+	if err != nil {
+		return nil
+	}
+	return buf[:n]
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNextSessionKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		more  string // typed within DetachEscapeWait after the input
+		data  string
+		key   byte
+		found bool
+		rest  string
+	}{
+		{"no key", "ls -l\r", "", "ls -l\r", 0, false, ""},
+		{"detach", "\x00", "", "", DetachKey, true, ""},
+		{"detach after keys", "ls\x00", "", "ls", DetachKey, true, ""},
+		{"detach before keys", "\x00x", "", "", DetachKey, true, "x"},
+		{"pair", "a\x00\x00b", "", "a\x00b", 0, false, ""},
+		{"pair split across reads", "a\x00", "\x00b", "a\x00b", 0, false, ""},
+		{"alone at end, then other keys", "a\x00", "b", "a", DetachKey, true, "b"},
+		{"send file", "a\x1db", "", "a", SendFileKey, true, "b"},
+		{"send file pair", "\x1d\x1d", "", "\x1d", 0, false, ""},
+		{"different keys are no pair", "\x1d\x00", "", "", SendFileKey, true, "\x00"},
+		{"stats", "\x1e\x1e\x1ex", "", "\x1e", StatsKey, true, "x"},
+		{"pause", "\x1f", "", "", PauseKey, true, ""},
+		{"pause pair", "\x1f\x1f", "", "\x1f", 0, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			more := func() []byte { return []byte(tt.more) }
+			data, key, found, rest := nextSessionKey([]byte(tt.input), more)
+			if string(data) != tt.data || key != tt.key || found != tt.found || string(rest) != tt.rest {
+				t.Errorf("nextSessionKey(%q) = %q, %#x, %v, %q; want %q, %#x, %v, %q",
+					tt.input, data, key, found, rest, tt.data, tt.key, tt.found, tt.rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// plainMode (--plain) drops box drawing, colors and screen clears so the
+// output reads linearly on screen readers and dumb terminals
+var plainMode bool
+
+// clearScreen clears the terminal and shows any critical session alerts
+func clearScreen() {
+	if plainMode {
+		fmt.Println()
+	} else {
+		fmt.Print("\033[2J\033[H")
+	}
+	printAlerts()
+}
+
+// printHeader draws a boxed title, or labeled lines in plain mode
+func printHeader(lines ...string) {
+	if plainMode {
+		for _, line := range lines {
+			fmt.Printf("== %s ==\n", strings.TrimSpace(line))
+		}
+		fmt.Println()
+		return
+	}
+
+	fmt.Println("╔════════════════════════════════════════╗")
+	for _, line := range lines {
+		fmt.Printf("║ %-39s║\n", line)
+	}
+	fmt.Print("╚════════════════════════════════════════╝\n\n")
+}
+
+// printSeparator draws a horizontal rule between output blocks
+func printSeparator() {
+	if plainMode {
+		fmt.Println("----------------------------------------")
+		return
+	}
+	fmt.Println("─────────────────────────────────────────")
+}
+
+// colorize wraps text in an SGR sequence unless in plain mode
+func colorize(sgr, text string) string {
+	if plainMode {
+		return text
+	}
+	return "\033[" + sgr + "m" + text + "\033[0m"
+}
+
+// highlight marks a search match: reverse video, or brackets in plain mode
+func highlight(text string) string {
+	if plainMode {
+		return "[[" + text + "]]"
+	}
+	return colorize("7", text)
+}
+
+// indexFold returns the byte range of the first case-insensitive match of
+// term in s, or -1, -1. Offsets are in s itself, so they stay valid when
+// case folding changes a character's length
+func indexFold(s, term string) (int, int) {
+	if term == "" {
+		return -1, -1
+	}
+	n := utf8.RuneCountInString(term)
+	for start := range s {
+		end := start
+		for i := 0; i < n && end < len(s); i++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		if strings.EqualFold(s[start:end], term) {
+			return start, end
+		}
+	}
+	return -1, -1
+}
+
+// containsFold reports whether s contains term, ignoring case
+func containsFold(s, term string) bool {
+	start, _ := indexFold(s, term)
+	return start >= 0
+}
+
+// highlightMatches highlights every case-insensitive match of term in
+// line, keeping the line's original casing
+func highlightMatches(line, term string) string {
+	var result strings.Builder
+	for {
+		start, end := indexFold(line, term)
+		if start < 0 {
+			result.WriteString(line)
+			return result.String()
+		}
+		result.WriteString(line[:start])
+		result.WriteString(highlight(line[start:end]))
+		line = line[end:]
+	}
+}
+
+// truncateText shortens text to at most width characters, ending with
+// "..." when cut
+func truncateText(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max(0, width-3)]) + "..."
+}
+
+// okMark and failMark label per-item results
+func okMark() string {
+	if plainMode {
+		return "OK:"
+	}
+	return "✓"
+}
+
+func failMark() string {
+	if plainMode {
+		return "FAILED:"
+	}
+	return "✗"
+}
+
+// stdoutIsTerminal reports whether output goes to a terminal rather than
+// a pipe or a file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
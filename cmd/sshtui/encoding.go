@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodingUTF8 is the Encoding of hosts needing no conversion, to undo
+// the Encoding of a wider Host block
+const EncodingUTF8 = "utf-8"
+
+// charset is a single-byte character set sshtui converts itself: bytes
+// below 0x80 are ASCII, high maps the others
+type charset struct {
+	high    [128]rune
+	reverse map[rune]byte
+}
+
+// charsets are the encodings converted in sshtui, by canonical name;
+// others, multibyte ones such as GBK or Shift_JIS, go through luit
+var charsets = map[string]*charset{
+	"iso-8859-1":   newCharset(nil),
+	"iso-8859-15":  newCharset(map[byte]rune{0xA4: '€', 0xA6: 'Š', 0xA8: 'š', 0xB4: 'Ž', 0xB8: 'ž', 0xBC: 'Œ', 0xBD: 'œ', 0xBE: 'Ÿ'}),
+	"windows-1252": newCharset(cp1252),
+}
+
+// cp1252 is where windows-1252 differs from latin1: printable characters
+// in place of the C1 controls, but for the five bytes it leaves undefined
+var cp1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// encodingNames maps the usual spellings of the built-in encodings, with
+// case, dashes and underscores dropped, to their canonical names
+var encodingNames = map[string]string{
+	"utf8":   EncodingUTF8,
+	"latin1": "iso-8859-1", "iso88591": "iso-8859-1",
+	"latin9": "iso-8859-15", "iso885915": "iso-8859-15",
+	"cp1252": "windows-1252", "windows1252": "windows-1252",
+}
+
+// newCharset builds latin1 with the given bytes mapped elsewhere
+func newCharset(changes map[byte]rune) *charset {
+	c := &charset{reverse: make(map[rune]byte)}
+	for i := range c.high {
+		b := byte(0x80 + i)
+		r, ok := changes[b]
+		if !ok {
+			r = rune(b)
+		}
+		c.high[i] = r
+		c.reverse[r] = b
+	}
+	return c
+}
+
+// normalizeEncoding returns the canonical name of a built-in encoding,
+// other names as written for luit
+func normalizeEncoding(name string) string {
+	key := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	if canonical, ok := encodingNames[key]; ok {
+		return canonical
+	}
+	return name
+}
+
+// converts tells whether host's Encoding asks for any conversion
+func converts(host SSHHost) bool {
+	return host.Encoding != "" && host.Encoding != EncodingUTF8
+}
+
+// luitCommand runs argv under luit, which converts between UTF-8 and
+// encoding on a PTY of its own
+func luitCommand(encoding string, argv []string) ([]string, error) {
+	if _, err := exec.LookPath("luit"); err != nil {
+		return nil, fmt.Errorf(tr("encoding %s needs luit, which is not on PATH"), encoding)
+	}
+	return append([]string{"luit", "-encoding", encoding, "--"}, argv...), nil
+}
+
+// encodingCommand wraps a session's command line in luit for encodings
+// sshtui does not convert itself
+func encodingCommand(host SSHHost, argv []string) ([]string, error) {
+	if !converts(host) || charsets[host.Encoding] != nil {
+		return argv, nil
+	}
+	return luitCommand(host.Encoding, argv)
+}
+
+// encodingCodec converts a session's PTY streams: output to UTF-8, input
+// from it
+type encodingCodec struct {
+	charset *charset
+	partial []byte // start of a UTF-8 sequence the last input ended with
+}
+
+// newEncodingCodec returns the codec of host's sessions, nil when their
+// output is UTF-8 already or luit converts it
+func newEncodingCodec(host SSHHost) *encodingCodec {
+	if !converts(host) || charsets[host.Encoding] == nil {
+		return nil
+	}
+	return &encodingCodec{charset: charsets[host.Encoding]}
+}
+
+// decode converts output to UTF-8; a single-byte charset has no sequence
+// to cut between reads
+func (c *encodingCodec) decode(p []byte) []byte {
+	ascii := true
+	for _, b := range p {
+		if b >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return p
+	}
+	out := make([]byte, 0, len(p)+len(p)/2)
+	for _, b := range p {
+		if b < utf8.RuneSelf {
+			out = append(out, b)
+			continue
+		}
+		out = utf8.AppendRune(out, c.charset.high[b-utf8.RuneSelf])
+	}
+	return out
+}
+
+// encode converts typed UTF-8 to the charset, keeping a sequence cut at
+// the end for the next keys; characters it lacks become ?
+func (c *encodingCodec) encode(p []byte) []byte {
+	p = append(c.partial, p...)
+	c.partial = nil
+	out := make([]byte, 0, len(p))
+	for len(p) > 0 {
+		if p[0] < utf8.RuneSelf {
+			out = append(out, p[0])
+			p = p[1:]
+			continue
+		}
+		if !utf8.FullRune(p) {
+			c.partial = append([]byte{}, p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		p = p[size:]
+		if b, ok := c.charset.reverse[r]; ok {
+			out = append(out, b)
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// decodeOutput converts what a session's PTY printed to UTF-8
+func (s *Session) decodeOutput(p []byte) []byte {
+	if s.codec == nil {
+		return p
+	}
+	return s.codec.decode(p)
+}
+
+// writeInput sends typed keys to a session's PTY, the one of its latest
+// reconnect, in the host's encoding; callers do not hold sessionsMu
+func (s *Session) writeInput(p []byte) (int, error) {
+	if s.codec != nil {
+		p = s.codec.encode(p)
+	}
+	sessionsMu.RLock()
+	ptmx := s.PTY
+	sessionsMu.RUnlock()
+	return ptmx.Write(p)
+}
+
+// encodingDetail describes host's Encoding for host details
+func encodingDetail(host SSHHost) string {
+	if charsets[host.Encoding] != nil {
+		return host.Encoding
+	}
+	return fmt.Sprintf(tr("%s, converted by luit"), host.Encoding)
+}
+
+// decodingWriter converts a multi-host run's output to UTF-8 on its way
+type decodingWriter struct {
+	codec *encodingCodec
+	w     io.Writer
+}
+
+func (d decodingWriter) Write(p []byte) (int, error) {
+	if _, err := d.w.Write(d.codec.decode(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registerProvider("etc-hosts", newEtcHostsProvider)
+	registerProvider("dns-zone", newDNSZoneProvider)
+}
+
+// etcHostsProvider offers the named entries of /etc/hosts
+type etcHostsProvider struct {
+	file  string
+	user  string
+	match string
+}
+
+// newEtcHostsProvider accepts user=, file= and match= (alias glob)
+func newEtcHostsProvider(options map[string]string) (HostProvider, error) {
+	p := &etcHostsProvider{file: "/etc/hosts"}
+	for key, value := range options {
+		switch key {
+		case "user":
+			p.user = value
+		case "file":
+			p.file = expandHome(value)
+		case "match":
+			p.match = value
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), "etc-hosts", key)
+		}
+	}
+	return p, nil
+}
+
+func (p *etcHostsProvider) Name() string { return "etc-hosts" }
+
+func (p *etcHostsProvider) Refresh() {}
+
+func (p *etcHostsProvider) Load() ([]SSHHost, error) {
+	file, err := os.Open(p.file)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hosts := []SSHHost{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isRemoteAddr(fields[0]) {
+			continue
+		}
+		// The first name is canonical, the rest are aliases of the same machine
+		if host, ok := labHost(fields[1], fields[0], p.user, p.match); ok {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, scanner.Err()
+}
+
+// dnsZoneProvider lists the A/AAAA records of a zone through a transfer
+// (dig AXFR), for internal zones that allow it
+type dnsZoneProvider struct {
+	zone   string
+	server string
+	user   string
+	match  string
+	cache  []SSHHost
+}
+
+// newDNSZoneProvider requires zone= and accepts server=, user= and match=
+func newDNSZoneProvider(options map[string]string) (HostProvider, error) {
+	p := &dnsZoneProvider{}
+	for key, value := range options {
+		switch key {
+		case "zone":
+			p.zone = strings.TrimSuffix(value, ".")
+		case "server":
+			p.server = value
+		case "user":
+			p.user = value
+		case "match":
+			p.match = value
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), "dns-zone", key)
+		}
+	}
+	if p.zone == "" {
+		return nil, fmt.Errorf(tr("provider %s: zone= is required"), "dns-zone")
+	}
+	return p, nil
+}
+
+func (p *dnsZoneProvider) Name() string { return "dns-zone" }
+
+func (p *dnsZoneProvider) Refresh() { p.cache = nil }
+
+func (p *dnsZoneProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+
+	args := []string{"AXFR", p.zone, "+noall", "+answer"}
+	if p.server != "" {
+		args = append([]string{"@" + p.server}, args...)
+	}
+	out, err := exec.Command("dig", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("dns-zone: dig: %v", err)
+	}
+
+	hosts := []SSHHost{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// name. TTL IN A address
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || (fields[3] != "A" && fields[3] != "AAAA") {
+			continue
+		}
+		name := strings.TrimSuffix(fields[0], ".")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if host, ok := labHost(name, name, p.user, p.match); ok {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 && strings.Contains(string(out), "Transfer failed") {
+		return nil, fmt.Errorf(tr("dns-zone: transfer of %s refused"), p.zone)
+	}
+	p.cache = hosts
+	return hosts, scanner.Err()
+}
+
+// labHost builds a host with a default user, skipping aliases that do not
+// match the optional glob
+func labHost(alias, hostname, user, match string) (SSHHost, bool) {
+	if match != "" {
+		if ok, _ := filepath.Match(match, alias); !ok {
+			return SSHHost{}, false
+		}
+	}
+	return SSHHost{Alias: alias, HostName: hostname, User: user}, true
+}
+
+// isRemoteAddr reports whether addr is a unicast address of another machine
+func isRemoteAddr(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && !ip.IsLoopback() && !ip.IsMulticast() && !ip.IsUnspecified() && !ip.IsLinkLocalMulticast()
+}
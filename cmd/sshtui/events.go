@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// EventKind tells what an Event is about
+type EventKind int
+
+const (
+	// EventInput is a line, or an instant key, typed at the menu prompt
+	EventInput EventKind = iota
+	// EventSession is a session changing state, ringing the bell, or a
+	// notification being raised
+	EventSession
+	// EventTimer fires every MenuRefreshInterval for work done on a
+	// schedule: health probes, dashboards, anything missed by the others
+	EventTimer
+	// EventResize is the terminal being resized
+	EventResize
+
+	eventKinds
+)
+
+// Event is one thing for the dispatcher to handle
+type Event struct {
+	Kind  EventKind
+	Input string // EventInput only
+	Err   error  // EventInput only: reading stdin failed
+}
+
+// EventQueueSize is how many events can wait for the dispatcher; only
+// input can fill it, other kinds are coalesced
+const EventQueueSize = 16
+
+// Dispatcher hands events posted from any goroutine, one at a time, to
+// the handlers registered for their kind, all on the goroutine calling
+// Run. State touched only by handlers needs no locking
+type Dispatcher struct {
+	events   chan Event
+	handlers [eventKinds][]func(Event)
+	pending  [eventKinds]atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newDispatcher() *Dispatcher {
+	return &Dispatcher{events: make(chan Event, EventQueueSize), stop: make(chan struct{})}
+}
+
+// On registers handle for events of kind; register before Run
+func (d *Dispatcher) On(kind EventKind, handle func(Event)) {
+	d.handlers[kind] = append(d.handlers[kind], handle)
+}
+
+// Post queues e. Input always gets through; any other event is dropped
+// when one of its kind already waits, as handling it once covers both
+func (d *Dispatcher) Post(e Event) {
+	if e.Kind != EventInput && d.pending[e.Kind].Swap(true) {
+		return
+	}
+	select {
+	case d.events <- e:
+	case <-d.stop:
+	}
+}
+
+// Run handles events until Stop
+func (d *Dispatcher) Run() {
+	for {
+		select {
+		case e := <-d.events:
+			d.pending[e.Kind].Store(false)
+			for _, handle := range d.handlers[e.Kind] {
+				handle(e)
+			}
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// Stop ends Run once the event being handled is done
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// activeDispatcher is the menu's dispatcher while it runs, for code
+// anywhere to report changes through postEvent
+var activeDispatcher atomic.Pointer[Dispatcher]
+
+// postEvent posts an event of kind to the running menu, if any
+func postEvent(kind EventKind) {
+	if d := activeDispatcher.Load(); d != nil {
+		d.Post(Event{Kind: kind})
+	}
+}
+
+// startEventSources makes d the active dispatcher and feeds it timer and
+// resize events; the returned function stops them
+func startEventSources(d *Dispatcher) func() {
+	activeDispatcher.Store(d)
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	ticker := time.NewTicker(MenuRefreshInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-winch:
+				d.Post(Event{Kind: EventResize})
+			case <-ticker.C:
+				d.Post(Event{Kind: EventTimer})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		activeDispatcher.CompareAndSwap(d, nil)
+		signal.Stop(winch)
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Terminal emulators sshtui knows how to open a window in
+const (
+	TerminalKitty     = "kitty"
+	TerminalWezTerm   = "wezterm"
+	TerminalAlacritty = "alacritty"
+	TerminalITerm2    = "iterm2"
+)
+
+// detectTerminal guesses the terminal emulator sshtui runs in from the
+// variables each one sets, or ""
+func detectTerminal() string {
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "":
+		return TerminalKitty
+	case os.Getenv("WEZTERM_PANE") != "":
+		return TerminalWezTerm
+	case os.Getenv("ALACRITTY_WINDOW_ID") != "":
+		return TerminalAlacritty
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app":
+		return TerminalITerm2
+	}
+	return ""
+}
+
+// externalCommand builds the command opening the command line argv, ssh
+// or a Connect command, in a new window of terminal: a known emulator, or
+// a command line where {ssh}, {alias} and {profile} are replaced
+// (shell-quoted)
+func externalCommand(terminal string, host SSHHost, argv []string) (*exec.Cmd, error) {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	sshLine := strings.Join(quoted, " ")
+
+	switch terminal {
+	case "":
+		return nil, errors.New(tr("no terminal found, set Terminal in the sshtui config"))
+	case TerminalKitty:
+		return exec.Command("kitty", append([]string{"--detach", "--title", host.Alias}, argv...)...), nil
+	case TerminalWezTerm:
+		return exec.Command("wezterm", append([]string{"cli", "spawn", "--new-window", "--"}, argv...)...), nil
+	case TerminalAlacritty:
+		return exec.Command("alacritty", append([]string{"msg", "create-window", "--title", host.Alias, "-e"}, argv...)...), nil
+	case TerminalITerm2:
+		profile := "default profile"
+		if host.TerminalProfile != "" {
+			profile = "profile " + appleScriptQuote(host.TerminalProfile)
+		}
+		script := fmt.Sprintf(`tell application "iTerm2" to create window with %s command %s`, profile, appleScriptQuote(sshLine))
+		return exec.Command("osascript", "-e", script), nil
+	}
+
+	line := strings.NewReplacer(
+		"{ssh}", sshLine,
+		"{alias}", shellQuote(host.Alias),
+		"{profile}", shellQuote(host.TerminalProfile),
+	).Replace(terminal)
+	return exec.Command("sh", "-c", line), nil
+}
+
+// appleScriptQuote quotes s as an AppleScript string
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// openExternal connects to host in a new window of the terminal emulator
+// instead of a session of sshtui; it is not listed, scrolled back or
+// recorded, so recorded hosts stay inside sshtui
+func openExternal(host SSHHost) {
+	if tag := recordTag(host); tag != "" {
+		fmt.Printf(tr("%s is recorded (tagged %q) and opens inside sshtui\n"), host.Alias, tag)
+		createSession(host)
+		return
+	}
+	if !confirmHost(host, ConfirmConnect) {
+		return
+	}
+	reason := ""
+	if tag := reasonTag(host); tag != "" {
+		var ok bool
+		if reason, ok = askReason([]string{host.Alias}, tag); !ok {
+			return
+		}
+	}
+	if err := refreshCertificate(host); err != nil {
+		fmt.Printf(tr("Warning: %v\n"), err)
+	}
+
+	terminal := valueOr(appConfig.Terminal, detectTerminal())
+	argv, err := sessionCommand(host)
+	if err == nil && newEncodingCodec(host) != nil {
+		// The window has no sshtui PTY in between to convert its streams
+		argv, err = luitCommand(host.Encoding, argv)
+	}
+	var cmd *exec.Cmd
+	if err == nil {
+		cmd, err = externalCommand(terminal, host, argv)
+	}
+	if err == nil {
+		// The window outlives sshtui and must not share its terminal
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err = cmd.Start(); err == nil {
+			go cmd.Wait()
+			markHostTouched(host.Alias)
+		}
+	}
+	auditConnect(host, reason, nil, err)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+}
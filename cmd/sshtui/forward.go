@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func manageForwards(hosts []SSHHost) {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		clearScreen()
+		printHeader(tr("Port Forward Management"))
+
+		fmt.Println(tr("Configured Forwards:"))
+		hasForwards := false
+		for _, host := range hosts {
+			if len(host.Forwards) > 0 {
+				hasForwards = true
+				fmt.Printf("\n  %s:\n", host.Alias)
+				for _, fwd := range host.Forwards {
+					switch fwd.Type {
+					case "L":
+						fmt.Printf(tr("    Local:   %s → %s\n"), fwd.LocalPort, fwd.RemoteAddr)
+					case "R":
+						fmt.Printf(tr("    Remote:  %s → %s\n"), fwd.LocalPort, fwd.RemoteAddr)
+					case "D":
+						fmt.Printf(tr("    Dynamic: %s (SOCKS)\n"), fwd.LocalPort)
+					}
+				}
+			}
+		}
+
+		if !hasForwards {
+			fmt.Println(tr("  No port forwards configured"))
+		}
+
+		fmt.Println(tr("\n\nActive Session Forwards:"))
+		hasActiveForwards := false
+		web := 0 // web forwards are numbered for o and c
+		sessionsMu.RLock()
+		for i, session := range sessions {
+			if len(session.Forwards) == 0 {
+				continue
+			}
+			hasActiveForwards = true
+			status := coloredStatus(session)
+			kind := ""
+			if session.Kind == SessionForward {
+				kind = tr(" [forward only]")
+			}
+			fmt.Printf(tr("\n  Session [!%d] %s (%s)%s:\n"), i+1, session.Alias, status, kind)
+			for _, fwd := range session.Forwards {
+				switch fwd.Type {
+				case "L":
+					fmt.Printf("    L: %s → %s", fwd.LocalPort, fwd.RemoteAddr)
+					if url := forwardURL(fwd); url != "" {
+						web++
+						fmt.Printf("  [%d] %s", web, url)
+					}
+					fmt.Println()
+				case "R":
+					fmt.Printf("    R: %s → %s\n", fwd.LocalPort, fwd.RemoteAddr)
+				case "D":
+					fmt.Printf("    D: %s\n", fwd.LocalPort)
+				}
+				if usage := forwardUsage(session, fwd); usage != "" {
+					fmt.Printf("       %s\n", colorize("2", usage))
+				}
+			}
+		}
+		sessionsMu.RUnlock()
+
+		if !hasActiveForwards {
+			fmt.Println(tr("  No active forwards"))
+		}
+
+		fmt.Println(tr("\n\nNote: Port forwards are configured in ~/.ssh/config"))
+		fmt.Println(tr("Format:"))
+		fmt.Println(tr("  LocalForward 8080 remote:80"))
+		fmt.Println(tr("  RemoteForward 9090 localhost:80"))
+		fmt.Println(tr("  DynamicForward 1080"))
+
+		fmt.Println(tr("\nCommands:"))
+		fmt.Println(tr("  n         - New quick forward (ssh -N)"))
+		fmt.Println(tr("  t         - Tunnel a host's configured forwards (ssh -N)"))
+		fmt.Println(tr("  x!number  - Close forward session"))
+		if web > 0 {
+			fmt.Println(tr("  o[number] - Open web forward in the browser"))
+			fmt.Println(tr("  c[number] - Check web forward (HTTP request)"))
+		}
+		fmt.Println(tr("  q         - Back to main menu"))
+		fmt.Print("\n> ")
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		switch {
+		case input == "q":
+			return
+
+		case input == "n":
+			if !refuseReadOnly(reader) {
+				quickForward(hosts, reader)
+			}
+
+		case input == "t":
+			if !refuseReadOnly(reader) {
+				tunnelHost(hosts, reader)
+			}
+
+		case strings.HasPrefix(input, "o"), strings.HasPrefix(input, "c"):
+			var num int
+			_, err := fmt.Sscanf(input[1:], "%d", &num)
+			sessionsMu.RLock()
+			urls := webForwards()
+			sessionsMu.RUnlock()
+			if err != nil || num < 1 || num > len(urls) {
+				fmt.Println(tr("Invalid number. Press Enter..."))
+				reader.ReadString('\n')
+				continue
+			}
+			url := urls[num-1]
+			if input[0] == 'o' {
+				if err := openBrowser(url); err != nil {
+					fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+					reader.ReadString('\n')
+				}
+				continue
+			}
+			if refuseReadOnly(reader) {
+				continue
+			}
+			fmt.Printf("\nGET %s\n", url)
+			fmt.Println("  " + checkHTTP(url))
+			fmt.Print(tr("\nPress Enter..."))
+			reader.ReadString('\n')
+
+		case strings.HasPrefix(input, "x!"):
+			if refuseReadOnly(reader) {
+				continue
+			}
+			var num int
+			if _, err := fmt.Sscanf(input, "x!%d", &num); err != nil || !closeForwardSession(num) {
+				fmt.Println(tr("Not a forward session. Press Enter..."))
+				reader.ReadString('\n')
+			}
+		}
+	}
+}
+
+// quickForward prompts for a host and a one-line forward spec, then starts it
+func quickForward(hosts []SSHHost, reader *bufio.Reader) {
+	fmt.Println(tr("\nHosts:"))
+	for i, host := range hosts {
+		fmt.Printf("  [%d] %s\n", i+1, host.Alias)
+	}
+	fmt.Print(tr("\nHost number: "))
+
+	numStr, _ := reader.ReadString('\n')
+	var num int
+	if _, err := fmt.Sscanf(strings.TrimSpace(numStr), "%d", &num); err != nil || num < 1 || num > len(hosts) {
+		fmt.Println(tr("Invalid host number. Press Enter..."))
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Print(tr("Forward (L 8080 localhost:80 | R 9090 localhost:80 | D 1080), ? scans the host's ports: "))
+	spec, _ := reader.ReadString('\n')
+	if strings.TrimSpace(spec) == "?" {
+		fwd, ok := pickRemotePort(hosts[num-1], reader)
+		if ok {
+			createForwardSession(hosts[num-1], []PortForward{*fwd})
+		}
+		return
+	}
+	fwd := parseForwardSpec(spec)
+	if fwd == nil {
+		fmt.Println(tr("Invalid forward. Press Enter..."))
+		reader.ReadString('\n')
+		return
+	}
+
+	createForwardSession(hosts[num-1], []PortForward{*fwd})
+}
+
+// tunnelHost starts a forward-only session with a host's configured forwards
+func tunnelHost(hosts []SSHHost, reader *bufio.Reader) {
+	candidates := []SSHHost{}
+	for _, host := range hosts {
+		if len(host.Forwards) > 0 {
+			candidates = append(candidates, host)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Println(tr("No hosts with configured forwards. Press Enter..."))
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Println(tr("\nHosts with forwards:"))
+	for i, host := range candidates {
+		fmt.Printf("  [%d] %s%s\n", i+1, host.Alias, displayForwards(host.Forwards))
+	}
+	fmt.Print(tr("\nHost number: "))
+
+	numStr, _ := reader.ReadString('\n')
+	var num int
+	if _, err := fmt.Sscanf(strings.TrimSpace(numStr), "%d", &num); err != nil || num < 1 || num > len(candidates) {
+		fmt.Println(tr("Invalid host number. Press Enter..."))
+		reader.ReadString('\n')
+		return
+	}
+
+	createForwardSession(candidates[num-1], candidates[num-1].Forwards)
+}
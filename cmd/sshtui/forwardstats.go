@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ForwardRelay listens on a forward's local port in place of ssh and
+// relays each connection to ssh, listening on an internal loopback port,
+// counting connections and bytes on the way
+type ForwardRelay struct {
+	Forward  PortForward // as requested
+	Internal PortForward // as given to ssh
+	listener net.Listener
+
+	Connections atomic.Int64 // accepted so far
+	Open        atomic.Int64 // currently relayed
+	BytesIn     atomic.Int64 // from the local client to the remote side
+	BytesOut    atomic.Int64 // back to the local client
+	LastUsed    atomic.Int64 // unix time of the last connection, 0 never
+}
+
+// relayListenAddress is where the relay listens for a forward's
+// LocalPort ("8080", "127.0.0.1:8080" or "*:8080"); ok is false for
+// forwards it cannot relay, such as Unix sockets
+func relayListenAddress(localPort string) (string, bool) {
+	bind, port := "localhost", localPort
+	if i := strings.LastIndex(localPort, ":"); i >= 0 {
+		bind, port = localPort[:i], localPort[i+1:]
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", false
+	}
+	if bind == "*" {
+		bind = ""
+	}
+	return net.JoinHostPort(strings.Trim(bind, "[]"), port), true
+}
+
+// startForwardRelays opens a relay for every local and dynamic forward.
+// Remote forwards are left to ssh, as their connections arrive on the host
+func startForwardRelays(forwards []PortForward) ([]*ForwardRelay, error) {
+	relays := []*ForwardRelay{}
+	for _, fwd := range forwards {
+		address, ok := relayListenAddress(fwd.LocalPort)
+		if fwd.Type == "R" || !ok {
+			continue
+		}
+		relay, err := newForwardRelay(fwd, address)
+		if err != nil {
+			closeForwardRelays(relays)
+			return nil, err
+		}
+		relays = append(relays, relay)
+	}
+	return relays, nil
+}
+
+// sshForwards are a session's forwards as given to ssh, relayed ones
+// listening on their internal port
+func sshForwards(session *Session) []PortForward {
+	forwards := []PortForward{}
+	for _, fwd := range session.Forwards {
+		for _, relay := range session.Relays {
+			if relay.Forward == fwd {
+				fwd = relay.Internal
+				break
+			}
+		}
+		forwards = append(forwards, fwd)
+	}
+	return forwards
+}
+
+func newForwardRelay(fwd PortForward, address string) (*ForwardRelay, error) {
+	// Reserve a free loopback port for ssh, released right before it starts
+	internal, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	internalPort := internal.Addr().(*net.TCPAddr).Port
+	internal.Close()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	relay := &ForwardRelay{Forward: fwd, Internal: fwd, listener: listener}
+	relay.Internal.LocalPort = fmt.Sprintf("127.0.0.1:%d", internalPort)
+	go relay.serve()
+	return relay, nil
+}
+
+func (r *ForwardRelay) serve() {
+	for {
+		client, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		r.Connections.Add(1)
+		r.LastUsed.Store(time.Now().Unix())
+		go r.relay(client)
+	}
+}
+
+// relay copies one connection both ways until either side closes
+func (r *ForwardRelay) relay(client net.Conn) {
+	defer client.Close()
+
+	upstream, err := net.Dial("tcp", r.Internal.LocalPort)
+	if err != nil {
+		// ssh is restarting; the client sees the connection close, as
+		// it would without the relay
+		return
+	}
+	defer upstream.Close()
+	r.Open.Add(1)
+	defer r.Open.Add(-1)
+
+	done := make(chan bool, 2)
+	go func() {
+		n, _ := io.Copy(upstream, client)
+		r.BytesIn.Add(n)
+		upstream.(*net.TCPConn).CloseWrite()
+		done <- true
+	}()
+	go func() {
+		n, _ := io.Copy(client, upstream)
+		r.BytesOut.Add(n)
+		client.(*net.TCPConn).CloseWrite()
+		done <- true
+	}()
+	<-done
+	<-done
+}
+
+// Close stops accepting connections; open ones end with ssh
+func (r *ForwardRelay) Close() error {
+	return r.listener.Close()
+}
+
+func closeForwardRelays(relays []*ForwardRelay) {
+	for _, relay := range relays {
+		relay.Close()
+	}
+}
+
+// Usage is the one-line summary shown in the forward screen
+func (r *ForwardRelay) Usage() string {
+	usage := fmt.Sprintf(tr("%d connections (%d open), %s in, %s out"),
+		r.Connections.Load(), r.Open.Load(), formatBytes(r.BytesIn.Load()), formatBytes(r.BytesOut.Load()))
+	if last := r.LastUsed.Load(); last > 0 {
+		usage += fmt.Sprintf(tr(", last %s ago"), formatDuration(time.Now().Unix()-last))
+	} else {
+		usage += tr(", never used")
+	}
+	return usage
+}
+
+// forwardUsage finds the relay counting fwd in a session, or ""; callers
+// hold sessionsMu
+func forwardUsage(session *Session, fwd PortForward) string {
+	for _, relay := range session.Relays {
+		if relay.Forward == fwd {
+			return relay.Usage()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// handoffDir holds imported sessions in the state directory until
+	// the next menu picks them up
+	handoffDir = "handoff"
+	// MaxHandoffFile bounds one file read from a state archive
+	MaxHandoffFile = 64 * 1024 * 1024
+)
+
+// handoffStateFiles are the state files that follow the user to another
+// machine. Certificates, the audit log and recordings stay where they
+// were made
+var handoffStateFiles = []string{"config", "history.json", "commands.json", "timetrack.json", "extras.json", managedHostsFile, sessionOrderFile}
+
+// handoffStateDirs are the state directories whose files follow the user
+// too, host notes
+var handoffStateDirs = []string{notesDir}
+
+// HandoffSession describes a session for another machine, where it is
+// reopened on demand; its scrollback is a file next to it
+type HandoffSession struct {
+	Alias       string        `json:"alias"`
+	Host        string        `json:"host"`
+	Kind        string        `json:"kind"`
+	Forwards    []PortForward `json:"forwards,omitempty"`
+	State       string        `json:"state"`
+	Archived    bool          `json:"archived,omitempty"`
+	Ended       time.Time     `json:"ended"`
+	LastCommand string        `json:"last_command,omitempty"`
+	Scrollback  string        `json:"scrollback,omitempty"`
+}
+
+// handoffSessions are the sessions imported from another machine that
+// were not reopened yet; guarded by sessionsMu
+var handoffSessions []HandoffSession
+
+// defaultStateArchive names an export after this machine and the date
+func defaultStateArchive() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("sshtui-state-%s-%s.tar.gz", valueOr(strings.Split(host, ".")[0], "local"), time.Now().Format("20060102"))
+}
+
+// exportState writes the state files, and the sessions and archived
+// scrollbacks of this run when withSessions is set, to a gzipped tarball
+func exportState(archivePath string, withSessions bool) (int, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	count := 0
+	err = func() error {
+		for _, name := range handoffStateFiles {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := add(name, data); err != nil {
+				return err
+			}
+		}
+		for _, stateDir := range handoffStateDirs {
+			entries, err := os.ReadDir(filepath.Join(dir, stateDir))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(dir, stateDir, entry.Name()))
+				if err != nil {
+					return err
+				}
+				if err := add(path.Join(stateDir, entry.Name()), data); err != nil {
+					return err
+				}
+			}
+		}
+		if !withSessions {
+			return nil
+		}
+
+		// Copy what is needed under the lock, the writing goes without
+		described, scrollbacks := describeSessions()
+		count = len(described)
+		for i, data := range scrollbacks {
+			if err := add(path.Join(handoffDir, described[i].Scrollback), data); err != nil {
+				return err
+			}
+		}
+		index, err := json.MarshalIndent(described, "", "  ")
+		if err != nil {
+			return err
+		}
+		return add(path.Join(handoffDir, "sessions.json"), index)
+	}()
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return 0, err
+	}
+	return count, nil
+}
+
+// describeSessions lists the running then archived sessions with a copy
+// of their scrollback, in the same order
+func describeSessions() ([]HandoffSession, [][]byte) {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	described := []HandoffSession{}
+	scrollbacks := [][]byte{}
+	describe := func(s *Session, archived bool) {
+		described = append(described, HandoffSession{
+			Alias:       s.Alias,
+			Host:        s.Host.Alias,
+			Kind:        s.Kind,
+			Forwards:    s.Forwards,
+			State:       s.State,
+			Archived:    archived,
+			Ended:       s.Ended,
+			LastCommand: s.LastCommand,
+			Scrollback:  fmt.Sprintf("%d.log", len(described)+1),
+		})
+		scrollbacks = append(scrollbacks, append([]byte{}, fullScrollback(s)...))
+	}
+	for _, s := range sessions {
+		describe(s, false)
+	}
+	for _, s := range archivedSessions {
+		describe(s, true)
+	}
+	return described, scrollbacks
+}
+
+// importState unpacks an exported tarball into the state directory. A
+// state file already there is kept with a .before-import suffix; sessions
+// wait in the handoff directory for the next menu
+func importState(archivePath string) ([]string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	archive := tar.NewReader(gz)
+
+	handoff := filepath.Join(dir, handoffDir)
+	imported := []string{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+		if header.Typeflag != tar.TypeReg || header.Size > MaxHandoffFile {
+			continue
+		}
+		target := ""
+		name := path.Clean(header.Name)
+		if dirName, base := path.Split(name); dirName == handoffDir+"/" && base != "" && !strings.HasPrefix(base, ".") {
+			if err := os.MkdirAll(handoff, 0700); err != nil {
+				return imported, err
+			}
+			target = filepath.Join(handoff, base)
+		} else if isHandoffStateFile(name) {
+			target = filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return imported, err
+			}
+			if _, err := os.Stat(target); err == nil {
+				if err := os.Rename(target, target+".before-import"); err != nil {
+					return imported, err
+				}
+			}
+		} else {
+			// Only what export-state writes is taken
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(archive, MaxHandoffFile))
+		if err != nil {
+			return imported, err
+		}
+		if err := os.WriteFile(target, data, 0600); err != nil {
+			return imported, err
+		}
+		imported = append(imported, name)
+	}
+	return imported, nil
+}
+
+// isHandoffStateFile tells whether an archive entry is a state file, or a
+// file of a state directory
+func isHandoffStateFile(name string) bool {
+	for _, stateFile := range handoffStateFiles {
+		if name == stateFile {
+			return true
+		}
+	}
+	dirName, base := path.Split(name)
+	for _, stateDir := range handoffStateDirs {
+		if dirName == stateDir+"/" && base != "" && !strings.HasPrefix(base, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHandoff picks up the sessions of an import: their scrollbacks join
+// the archive and they wait to be reopened. The handoff directory is
+// removed, so they are offered once
+func loadHandoff() {
+	dir, err := stateDir()
+	if err != nil {
+		return
+	}
+	handoff := filepath.Join(dir, handoffDir)
+	data, err := os.ReadFile(filepath.Join(handoff, "sessions.json"))
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(handoff)
+	var described []HandoffSession
+	if err := json.Unmarshal(data, &described); err != nil {
+		notify(tr("Imported sessions could not be read: %v"), err)
+		return
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	for i := len(described) - 1; i >= 0; i-- {
+		d := described[i]
+		scrollback, _ := os.ReadFile(filepath.Join(handoff, filepath.Base(d.Scrollback)))
+		ended := d.Ended
+		if ended.IsZero() {
+			ended = time.Now()
+		}
+		imported := &Session{
+			Alias:       d.Alias + tr(" (imported)"),
+			Host:        SSHHost{Alias: d.Host},
+			Kind:        d.Kind,
+			Forwards:    d.Forwards,
+			State:       StateDead,
+			Scrollback:  scrollback,
+			Ended:       ended,
+			LastCommand: d.LastCommand,
+		}
+		compactScrollback(imported)
+		archivedSessions = append([]*Session{imported}, archivedSessions...)
+		if !d.Archived && d.State != StateDead {
+			handoffSessions = append([]HandoffSession{d}, handoffSessions...)
+		}
+	}
+	if len(archivedSessions) > MaxArchivedSessions {
+		archivedSessions = archivedSessions[:MaxArchivedSessions]
+	}
+	if len(handoffSessions) > 0 {
+		notify(tr("%d sessions imported from another machine, u[number] reopens them"), len(handoffSessions))
+	}
+}
+
+// handoffSummary lists the imported sessions left to reopen for the
+// menu; callers hold sessionsMu
+func handoffSummary() string {
+	if len(handoffSessions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintln(&b, tr("Imported (u[number] reopens, scrollback archived):"))
+	for i, d := range handoffSessions {
+		kind := ""
+		if d.Kind == SessionForward {
+			kind = tr(" forward-only") + displayForwards(d.Forwards)
+		}
+		fmt.Fprintf(&b, "  [u%d] %s%s\n", i+1, d.Alias, kind)
+	}
+	return b.String()
+}
+
+// reopenHandoff starts imported session num again on the host of the
+// same alias here
+func reopenHandoff(hosts []SSHHost, num int) {
+	sessionsMu.Lock()
+	if num < 1 || num > len(handoffSessions) {
+		sessionsMu.Unlock()
+		fmt.Print(tr("Invalid number. Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+	d := handoffSessions[num-1]
+	host, ok := findHost(hosts, d.Host)
+	if ok {
+		handoffSessions = append(handoffSessions[:num-1], handoffSessions[num:]...)
+	}
+	sessionsMu.Unlock()
+
+	if !ok {
+		fmt.Printf(tr("%s is not a host here. Press Enter..."), d.Host)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+	if d.Kind == SessionForward {
+		createForwardSession(host, d.Forwards)
+		return
+	}
+	createSession(host)
+}
+
+// exportStateMenu asks where to write the state and sessions of this run
+func exportStateMenu() {
+	reader := bufio.NewReader(os.Stdin)
+	suggested := defaultStateArchive()
+	fmt.Printf(tr("Export sessions, scrollback, history and config to [%s]: "), suggested)
+	input, _ := reader.ReadString('\n')
+	archivePath := valueOr(strings.TrimSpace(input), suggested)
+	count, err := exportState(archivePath, true)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return
+	}
+	fmt.Printf(tr("%d sessions exported to %s; on the other machine run sshtui import-state %s. Press Enter..."), count, archivePath, filepath.Base(archivePath))
+	reader.ReadString('\n')
+}
+
+// stateCommand runs export-state [FILE] or import-state FILE and returns
+// the exit code. Sessions live in the running menu, so only its E command
+// exports them
+func stateCommand(name string, args []string) int {
+	if len(args) > 1 || (name == "import-state" && len(args) == 0) {
+		fmt.Fprintf(os.Stderr, tr("Usage: sshtui %s (see --help)\n"), name)
+		return ExitConfig
+	}
+
+	if name == "export-state" {
+		archivePath := defaultStateArchive()
+		if len(args) == 1 {
+			archivePath = args[0]
+		}
+		if _, err := exportState(archivePath, false); err != nil {
+			fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+			return ExitFailures
+		}
+		fmt.Printf(tr("History, config and time log exported to %s\n"), archivePath)
+		fmt.Println(tr("Sessions and their scrollback are exported from the menu, with E"))
+		return ExitOK
+	}
+
+	imported, err := importState(args[0])
+	for _, file := range imported {
+		fmt.Printf(tr("  imported %s\n"), file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		return ExitFailures
+	}
+	if len(imported) == 0 {
+		fmt.Fprintln(os.Stderr, tr("Nothing to import: not an sshtui state export"))
+		return ExitFailures
+	}
+	fmt.Println(tr("Replaced files were kept with a .before-import suffix; sessions are offered in the menu"))
+	return ExitOK
+}
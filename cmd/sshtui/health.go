@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HealthProbeTimeout    = 5 * time.Second  // per host
+	HealthRefreshInterval = 30 * time.Second // while the menu sorts by latency
+	MaxHealthProbes       = 16               // probes running at once
+)
+
+// HostHealth is the latest reachability of a host: by a probe of the
+// latency sort, a connection test or a workspace health gate
+type HostHealth struct {
+	Checked   time.Time
+	Reachable bool
+	Latency   time.Duration
+	Via       string // jump host or proxy command it was reached through
+	JumpDown  string // jump host that failed, the host itself is unknown
+	Error     string
+}
+
+var (
+	healthResults   = map[string]HostHealth{}
+	healthMu        sync.Mutex
+	healthRunning   bool      // a refresh is in progress
+	healthRefreshed time.Time // when the last refresh ended
+)
+
+func recordHealth(alias string, health HostHealth) {
+	health.Checked = time.Now()
+	healthMu.Lock()
+	healthResults[alias] = health
+	healthMu.Unlock()
+	watchHealth(alias, health)
+}
+
+func hostHealth(alias string) (HostHealth, bool) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	health, ok := healthResults[alias]
+	return health, ok
+}
+
+// sshRoute is where ssh really connects for a host, after ~/.ssh/config
+// patterns and includes: `ssh -G` resolves them without connecting
+type sshRoute struct {
+	Alias        string
+	HostName     string
+	Port         string
+	User         string
+	ProxyJump    string
+	ProxyCommand string // as written, see Proxy
+}
+
+func resolveRoute(host SSHHost) sshRoute {
+	route := sshRoute{
+		Alias:        host.Alias,
+		HostName:     valueOr(host.HostName, host.Alias),
+		Port:         valueOr(host.Port, "22"),
+		User:         effectiveUser(host),
+		ProxyJump:    host.ProxyJump,
+		ProxyCommand: host.ProxyCommand,
+	}
+	if !sshClient.Supports(FeatureResolve) {
+		return route
+	}
+	out, err := commander.Command(context.Background(), "ssh", append([]string{"-G"}, buildSSHArgs(host)...)...).Output()
+	if err != nil {
+		return route
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		if value == "" || value == "none" {
+			continue
+		}
+		switch key {
+		case "hostname":
+			route.HostName = value
+		case "port":
+			route.Port = value
+		case "user":
+			route.User = value
+		case "proxyjump":
+			route.ProxyJump = value
+		case "proxycommand":
+			route.ProxyCommand = value
+		}
+	}
+	return route
+}
+
+// Proxy returns the ProxyCommand ssh runs to reach the host, with the
+// tokens ssh expands filled in: %h the hostname, %p the port, %r the
+// user, %n the alias as given and %% a percent sign. Others are left
+func (r sshRoute) Proxy() string {
+	var b strings.Builder
+	for i := 0; i < len(r.ProxyCommand); i++ {
+		c := r.ProxyCommand[i]
+		if c != '%' || i+1 == len(r.ProxyCommand) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch r.ProxyCommand[i] {
+		case 'h':
+			b.WriteString(r.HostName)
+		case 'p':
+			b.WriteString(r.Port)
+		case 'r':
+			b.WriteString(r.User)
+		case 'n':
+			b.WriteString(r.Alias)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(r.ProxyCommand[i])
+		}
+	}
+	return b.String()
+}
+
+// probeHost measures how fast a host answers. A direct host is timed by
+// a TCP connection to its ssh port; behind a jump host or proxy command,
+// by a BatchMode ssh run through it, as only ssh knows the way
+func probeHost(host SSHHost) HostHealth {
+	route := resolveRoute(host)
+	if route.ProxyJump == "" && route.ProxyCommand == "" {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(route.HostName, route.Port), HealthProbeTimeout)
+		if err != nil {
+			return HostHealth{Error: err.Error()}
+		}
+		conn.Close()
+		return HostHealth{Reachable: true, Latency: time.Since(start)}
+	}
+
+	health := HostHealth{Via: valueOr(route.ProxyJump, tr("proxy command"))}
+	var stderr bytes.Buffer
+	args := append([]string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5"}, overrideRemoteCommand(host)...)
+	args = append(args, sshTarget(host)...)
+	cmd := commander.Command(context.Background(), "ssh", append(args, "true")...)
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+	if err == nil {
+		health.Reachable, health.Latency = true, elapsed
+		return health
+	}
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if jump := failedJump(host, line); jump != "" {
+			health.JumpDown, health.Error = jump, strings.TrimSpace(line)
+			return health
+		}
+		// Turned away at login: the host answered
+		if strings.Contains(line, "Permission denied") {
+			health.Reachable, health.Latency = true, elapsed
+			return health
+		}
+	}
+	health.Error = valueOr(strings.TrimSpace(stderr.String()), err.Error())
+	return health
+}
+
+// refreshHealth probes hosts in the background, a few at a time, unless a
+// refresh is still running
+func refreshHealth(hosts []SSHHost) {
+	healthMu.Lock()
+	if healthRunning {
+		healthMu.Unlock()
+		return
+	}
+	healthRunning = true
+	healthMu.Unlock()
+
+	go func() {
+		slots := make(chan bool, MaxHealthProbes)
+		var wg sync.WaitGroup
+		for _, host := range hosts {
+			wg.Add(1)
+			slots <- true
+			go func(h SSHHost) {
+				defer wg.Done()
+				recordHealth(h.Alias, probeHost(h))
+				<-slots
+			}(host)
+		}
+		wg.Wait()
+
+		healthMu.Lock()
+		healthRunning, healthRefreshed = false, time.Now()
+		healthMu.Unlock()
+	}()
+}
+
+// healthRefreshDue tells when the latency sort needs fresh probes
+func healthRefreshDue() bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	return !healthRunning && time.Since(healthRefreshed) > HealthRefreshInterval
+}
+
+// sortByHealth orders hosts for the on-call view: reachable ones by
+// latency, then those not probed yet, then those behind a failed jump
+// host, then unreachable ones; the order is otherwise kept
+func sortByHealth(hosts []SSHHost) []SSHHost {
+	rank := func(alias string) (int, time.Duration) {
+		health, ok := hostHealth(alias)
+		switch {
+		case !ok:
+			return 1, 0
+		case health.Reachable:
+			return 0, health.Latency
+		case health.JumpDown != "":
+			return 2, 0
+		default:
+			return 3, 0
+		}
+	}
+	sorted := append([]SSHHost{}, hosts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, li := rank(sorted[i].Alias)
+		rj, lj := rank(sorted[j].Alias)
+		if ri != rj {
+			return ri < rj
+		}
+		return li < lj
+	})
+	return sorted
+}
+
+// healthLabel shows a host's latency or why it is down in the menu
+func healthLabel(alias string) string {
+	health, ok := hostHealth(alias)
+	switch {
+	case !ok:
+		return colorize("2", tr(" [probing...]"))
+	case health.Reachable && health.Via != "":
+		return colorize("32", fmt.Sprintf(tr(" [%v via %s]"), roundLatency(health.Latency), health.Via))
+	case health.Reachable:
+		return colorize("32", fmt.Sprintf(" [%v]", roundLatency(health.Latency)))
+	case health.JumpDown != "":
+		return colorize("33", fmt.Sprintf(tr(" [? jump host %s down]"), health.JumpDown))
+	default:
+		return colorize("1;31", tr(" [DOWN]"))
+	}
+}
+
+// roundLatency keeps three significant digits or so: 1.2ms, 85ms, 1.4s
+func roundLatency(d time.Duration) time.Duration {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond)
+	case d < time.Second:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(100 * time.Millisecond)
+	}
+}
+
+// healthSnapshot changes whenever a probe result does, for menu redraws
+func healthSnapshot() string {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	latest := time.Time{}
+	for _, health := range healthResults {
+		if health.Checked.After(latest) {
+			latest = health.Checked
+		}
+	}
+	return latest.String()
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthInterval  = time.Minute
+	MinHealthInterval      = 10 * time.Second
+	DefaultHealthDownAfter = 5 * time.Minute
+	HealthHookTimeout      = 30 * time.Second // per hook or webhook call
+)
+
+// Host transitions passed to HealthHook and HealthWebhook
+const (
+	HealthDown = "down"
+	HealthUp   = "up"
+)
+
+// hostWatch is what the health watch remembers of a host between probes
+type hostWatch struct {
+	downSince time.Time // first failed probe of the outage, zero while up
+	alerted   bool      // the outage lasted HealthDownAfter and was reported
+}
+
+// HealthEvent is a host going down or coming back up, as hooks get it
+type HealthEvent struct {
+	Event    string    `json:"event"` // HealthDown or HealthUp
+	Host     string    `json:"host"`
+	HostName string    `json:"hostname"`
+	Since    time.Time `json:"since"`            // first failed probe
+	Downtime float64   `json:"downtime_seconds"` // so far, or in all once up
+	Error    string    `json:"error,omitempty"`  // of the last failed probe
+	Text     string    `json:"text"`             // a summary, for chat webhooks
+}
+
+// healthSettings are the config's health watch settings, copied for the
+// watch so that a reload never changes them under it
+type healthSettings struct {
+	Interval  time.Duration
+	DownAfter time.Duration
+	Hook      string
+	Webhook   string
+}
+
+// healthSettingsOf copies the health watch settings of cfg
+func healthSettingsOf(cfg AppConfig) healthSettings {
+	return healthSettings{Interval: cfg.HealthInterval, DownAfter: cfg.HealthDownAfter, Hook: cfg.HealthHook, Webhook: cfg.HealthWebhook}
+}
+
+var (
+	watchedHosts  = map[string]SSHHost{} // by alias, the hosts with HealthWatch
+	hostWatches   = map[string]*hostWatch{}
+	watchSettings healthSettings
+	healthWatchMu sync.Mutex
+)
+
+// setWatchedHosts picks the hosts with HealthWatch out of the host list,
+// after a load or a reload, along with the settings to watch them with;
+// hosts no longer watched are forgotten
+func setWatchedHosts(hosts []SSHHost, settings healthSettings) {
+	healthWatchMu.Lock()
+	defer healthWatchMu.Unlock()
+
+	watchSettings = settings
+	watchedHosts = map[string]SSHHost{}
+	for _, host := range hosts {
+		if host.HealthWatch {
+			watchedHosts[host.Alias] = host
+		}
+	}
+	for alias := range hostWatches {
+		if _, ok := watchedHosts[alias]; !ok {
+			delete(hostWatches, alias)
+		}
+	}
+}
+
+// checkWebhookURL makes sure a HealthWebhook is an http or https URL
+func checkWebhookURL(value string) error {
+	u, err := neturl.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(tr("HealthWebhook must be an http or https URL"))
+	}
+	return nil
+}
+
+// startHealthWatch probes the watched hosts every HealthInterval, in the
+// background whatever the menu shows, except in read-only mode; the
+// returned function stops it
+func startHealthWatch() func() {
+	if readOnly {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			healthWatchMu.Lock()
+			hosts := make([]SSHHost, 0, len(watchedHosts))
+			for _, host := range watchedHosts {
+				hosts = append(hosts, host)
+			}
+			interval := watchSettings.Interval
+			healthWatchMu.Unlock()
+			probeWatched(hosts)
+
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// probeWatched probes hosts a few at a time and waits for them all
+func probeWatched(hosts []SSHHost) {
+	slots := make(chan bool, MaxHealthProbes)
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		slots <- true
+		go func(h SSHHost) {
+			defer wg.Done()
+			recordHealth(h.Alias, probeHost(h))
+			<-slots
+		}(host)
+	}
+	wg.Wait()
+}
+
+// watchHealth follows a watched host's probes, from the health watch or
+// any other: down once it failed them for HealthDownAfter, up again at
+// the first that succeeds. A jump host down says nothing of the host
+func watchHealth(alias string, health HostHealth) {
+	healthWatchMu.Lock()
+	host, ok := watchedHosts[alias]
+	if !ok || (!health.Reachable && health.JumpDown != "") {
+		healthWatchMu.Unlock()
+		return
+	}
+	settings := watchSettings
+	w := hostWatches[alias]
+	if w == nil {
+		w = &hostWatch{}
+		hostWatches[alias] = w
+	}
+
+	event := HealthEvent{Host: alias, HostName: valueOr(host.HostName, alias), Since: w.downSince}
+	switch {
+	case health.Reachable:
+		if w.alerted {
+			event.Event = HealthUp
+		}
+		*w = hostWatch{}
+	case w.downSince.IsZero():
+		w.downSince, event.Since = health.Checked, health.Checked
+		fallthrough
+	default:
+		if !w.alerted && health.Checked.Sub(w.downSince) >= settings.DownAfter {
+			event.Event, w.alerted = HealthDown, true
+		}
+	}
+	healthWatchMu.Unlock()
+
+	if event.Event == "" {
+		return
+	}
+	event.Downtime = health.Checked.Sub(event.Since).Round(time.Second).Seconds()
+	event.Error = health.Error
+	if event.Event == HealthDown {
+		event.Text = fmt.Sprintf(tr("%s is down since %s: %s"), alias, event.Since.Local().Format(time.TimeOnly), valueOr(health.Error, tr("unreachable")))
+	} else {
+		event.Text = fmt.Sprintf(tr("%s is back up after %v down"), alias, time.Duration(event.Downtime)*time.Second)
+	}
+	notify("%s", event.Text)
+	go fireHealthHooks(event, settings)
+}
+
+// fireHealthHooks runs HealthHook and calls HealthWebhook for a host
+// transition; failures become notifications
+func fireHealthHooks(event HealthEvent, settings healthSettings) {
+	if settings.Hook != "" {
+		if err := runHealthHook(settings.Hook, event); err != nil {
+			notify(tr("HealthHook for %s failed: %v"), event.Host, err)
+		}
+	}
+	if settings.Webhook != "" {
+		if err := postHealthWebhook(settings.Webhook, event); err != nil {
+			notify(tr("HealthWebhook for %s failed: %v"), event.Host, err)
+		}
+	}
+}
+
+// runHealthHook runs command with sh, the event in SSHTUI_ variables
+func runHealthHook(command string, event HealthEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), HealthHookTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"SSHTUI_EVENT="+event.Event,
+		"SSHTUI_HOST="+event.Host,
+		"SSHTUI_HOSTNAME="+event.HostName,
+		"SSHTUI_SINCE="+event.Since.Format(time.RFC3339),
+		fmt.Sprintf("SSHTUI_DOWNTIME=%.0f", event.Downtime),
+		"SSHTUI_ERROR="+event.Error,
+		"SSHTUI_TEXT="+event.Text,
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// postHealthWebhook posts the event as JSON to url. Errors leave the URL
+// out, as chat webhooks carry their token in it
+func postHealthWebhook(url string, event HealthEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.New(tr("invalid URL"))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sshtui/"+version)
+	client := &http.Client{Timeout: HealthHookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
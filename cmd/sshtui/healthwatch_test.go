@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchHealthUsesItsSettings(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	t.Cleanup(func() { setWatchedHosts(nil, healthSettings{}) })
+	setWatchedHosts([]SSHHost{{Alias: "web", HealthWatch: true}}, healthSettings{
+		DownAfter: time.Minute,
+		Hook:      `echo "$SSHTUI_EVENT $SSHTUI_HOST" >> ` + events,
+	})
+	// A reload elsewhere changes the config, not the watch
+	oldConfig := appConfig
+	appConfig.HealthDownAfter, appConfig.HealthHook = 0, ""
+	t.Cleanup(func() { appConfig = oldConfig })
+
+	start := time.Now()
+	watchHealth("web", HostHealth{Checked: start, Error: "timeout"})
+	watchHealth("web", HostHealth{Checked: start.Add(30 * time.Second), Error: "timeout"})
+	watchHealth("web", HostHealth{Checked: start.Add(time.Minute), Error: "timeout"})
+	watchHealth("web", HostHealth{Checked: start.Add(2 * time.Minute), Reachable: true})
+
+	waitFor(t, "the hooks", func() bool {
+		data, _ := os.ReadFile(events)
+		return strings.Count(string(data), "\n") == 2
+	})
+	// Each hook runs on its own, in any order
+	data, _ := os.ReadFile(events)
+	lines := strings.Fields(strings.ReplaceAll(string(data), " ", "_"))
+	slices.Sort(lines)
+	if !slices.Equal(lines, []string{"down_web", "up_web"}) {
+		t.Errorf("hooks got %q, want one down and one up", data)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+// sshConnectFailure is the exit status ssh uses for its own errors
+const sshConnectFailure = 255
+
+// ConnectAttempt is the outcome of the last connection to a host
+type ConnectAttempt struct {
+	Time   time.Time `json:"time"`
+	Failed bool      `json:"failed"`
+	Error  string    `json:"error,omitempty"`
+	Jump   string    `json:"jump,omitempty"` // the jump host failed, not the host
+}
+
+// MaxCommandHistory bounds the multi-host commands kept for recall
+const MaxCommandHistory = 200
+
+// CommandRun is a multi-host command as it was last run
+type CommandRun struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Hosts   []string  `json:"hosts"`
+}
+
+// loadHistory returns the last connection attempt per host alias
+func loadHistory() map[string]ConnectAttempt {
+	history := make(map[string]ConnectAttempt)
+	loadState("history.json", &history)
+	return history
+}
+
+// recordConnect stores the outcome of a connection; err nil means success
+func recordConnect(alias string, err error) {
+	attempt := ConnectAttempt{Time: time.Now()}
+	if err != nil {
+		attempt.Failed = true
+		attempt.Error = err.Error()
+		var jumpErr JumpError
+		if errors.As(err, &jumpErr) {
+			attempt.Jump = jumpErr.Jump
+		}
+	}
+
+	history := loadHistory()
+	history[alias] = attempt
+	saveState("history.json", history)
+}
+
+// loadCommands returns past multi-host commands, most recent first
+func loadCommands() []CommandRun {
+	commands := []CommandRun{}
+	loadState("commands.json", &commands)
+	return commands
+}
+
+// recordCommand remembers a multi-host command and its hosts; running the
+// same command on the same hosts again moves it to the front
+func recordCommand(command string, hosts []SSHHost) {
+	run := CommandRun{Time: time.Now(), Command: command}
+	for _, h := range hosts {
+		run.Hosts = append(run.Hosts, h.Alias)
+	}
+
+	commands := []CommandRun{run}
+	for _, c := range loadCommands() {
+		if c.Command != run.Command || strings.Join(c.Hosts, " ") != strings.Join(run.Hosts, " ") {
+			commands = append(commands, c)
+		}
+	}
+	if len(commands) > MaxCommandHistory {
+		commands = commands[:MaxCommandHistory]
+	}
+	saveState("commands.json", commands)
+}
+
+// recordExit records how ssh ended: its own error status means the
+// connection failed, anything else means the host was reached
+func recordExit(alias string, state *os.ProcessState, reason string) {
+	if state == nil || state.ExitCode() != sshConnectFailure {
+		recordConnect(alias, nil)
+		return
+	}
+	if reason == "" {
+		reason = state.String()
+	}
+	recordConnect(alias, errors.New(reason))
+}
+
+// lastOutputLine returns the last non-empty line ssh printed, which is
+// usually the reason it failed
+func lastOutputLine(scrollback []byte) string {
+	tail := ptysession.TailAtBoundary(scrollback, ScrollbackReplaySize)
+	lines := strings.Split(string(tail), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// failedHosts keeps hosts whose last connection failed and that have no
+// running session since
+func failedHosts(hosts []SSHHost) []SSHHost {
+	history := loadHistory()
+
+	sessionsMu.RLock()
+	alive := make(map[string]bool)
+	for _, s := range sessions {
+		if s.running() {
+			alive[s.Alias] = true
+		}
+	}
+	sessionsMu.RUnlock()
+
+	result := []SSHHost{}
+	for _, host := range hosts {
+		if history[host.Alias].Failed && !alive[host.Alias] {
+			result = append(result, host)
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Keys of the history search
+const (
+	searchOlderKey  = 0x12 // Ctrl+R: next older match
+	searchCancel    = 0x07 // Ctrl+G
+	searchInterrupt = 0x03 // Ctrl+C
+	searchEscape    = 0x1b
+)
+
+// historyEntry is a past connection or multi-host command
+type historyEntry struct {
+	Time    time.Time
+	Alias   string   // set for a connection
+	Command string   // set for a multi-host command
+	Hosts   []string // hosts of the command
+}
+
+// String is how an entry is shown and matched
+func (e historyEntry) String() string {
+	if e.Command == "" {
+		return "ssh " + e.Alias
+	}
+	return fmt.Sprintf("[%s] %s", strings.Join(e.Hosts, ","), e.Command)
+}
+
+// historyEntries merges connections and commands, most recent first
+func historyEntries() []historyEntry {
+	entries := []historyEntry{}
+	for alias, attempt := range loadHistory() {
+		entries = append(entries, historyEntry{Time: attempt.Time, Alias: alias})
+	}
+	for _, run := range loadCommands() {
+		entries = append(entries, historyEntry{Time: run.Time, Command: run.Command, Hosts: run.Hosts})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries
+}
+
+// searchHistory is a Ctrl+R style reverse incremental search over past
+// connections and multi-host commands; the chosen entry is run again
+func searchHistory(hosts []SSHHost) {
+	entries := historyEntries()
+	if len(entries) == 0 {
+		fmt.Println(tr("No history yet. Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	entry, ok := reverseSearch(entries)
+	if !ok {
+		return
+	}
+
+	if entry.Command == "" {
+		if host, found := findHost(hosts, entry.Alias); found {
+			connectHost(host)
+			return
+		}
+		fmt.Printf(tr("Host %s is no longer configured. Press Enter..."), entry.Alias)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	selected := []SSHHost{}
+	for _, alias := range entry.Hosts {
+		if host, found := findTarget(hosts, alias); found {
+			selected = append(selected, host)
+		} else {
+			fmt.Printf(tr("Skipping %s: no longer configured\n"), alias)
+		}
+	}
+	executeMultiHost(selected, entry.Command)
+}
+
+// reverseSearch reads keys in raw mode: typing narrows the search, Ctrl+R
+// steps to older matches, Enter picks, Esc or Ctrl+G cancels
+func reverseSearch(entries []historyEntry) (historyEntry, bool) {
+	clearScreen()
+	printHeader(tr("History Search"))
+	fmt.Println(tr("Type to search, Ctrl+R for older matches, Enter to run, Esc to cancel"))
+	fmt.Println()
+
+	restoreTerm, err := term.MakeRaw()
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return historyEntry{}, false
+	}
+	defer restoreTerm()
+	defer term.Drain()
+
+	query := ""
+	match := findEntry(entries, query, 0)
+	render := func() {
+		shown := tr("no match")
+		if match >= 0 {
+			shown = highlightMatches(entries[match].String(), query)
+		}
+		line := fmt.Sprintf(tr("(reverse-i-search)`%s': %s"), query, shown)
+		if plainMode {
+			fmt.Print(line + "\r\n")
+		} else {
+			fmt.Print("\r\033[K" + line)
+		}
+	}
+	render()
+
+	buf := make([]byte, 64)
+	for {
+		n, err := term.Read(buf)
+		if err != nil {
+			return historyEntry{}, false
+		}
+		for _, b := range buf[:n] {
+			switch {
+			case b == '\r' || b == '\n':
+				fmt.Print("\r\n")
+				if match < 0 {
+					return historyEntry{}, false
+				}
+				return entries[match], true
+			case b == searchCancel || b == searchInterrupt || b == searchEscape:
+				fmt.Print("\r\n")
+				return historyEntry{}, false
+			case b == searchOlderKey:
+				if next := findEntry(entries, query, match+1); next >= 0 {
+					match = next
+				}
+			case b == 0x7f || b == 0x08:
+				if query != "" {
+					_, size := utf8.DecodeLastRuneInString(query)
+					query = query[:len(query)-size]
+					match = findEntry(entries, query, 0)
+				}
+			case b >= 0x20:
+				query = string(append([]byte(query), b))
+				// A longer query can only match the current entry or older ones
+				match = findEntry(entries, query, max(match, 0))
+			}
+		}
+		render()
+	}
+}
+
+// findEntry returns the index of the first entry from start matching
+// query, or -1
+func findEntry(entries []historyEntry, query string, start int) int {
+	for i := start; i < len(entries); i++ {
+		if query == "" || containsFold(entries[i].String(), query) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// showHostDetail displays what sshtui knows about a host until Enter,
+// offering to edit its notes
+func showHostDetail(host SSHHost) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		printHostDetail(host)
+		if readOnly {
+			fmt.Print(tr("\nPress Enter..."))
+			reader.ReadString('\n')
+			return
+		}
+		fmt.Print(tr("\ne edits the notes, Enter returns: "))
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "e" {
+			return
+		}
+		if err := editNotes(host.Alias); err != nil {
+			fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+			reader.ReadString('\n')
+		}
+	}
+}
+
+// printHostDetail shows what sshtui knows about a host: its target,
+// where it came from, its inventory metadata and notes, the certificates
+// ssh may offer for it and the settings ssh resolves
+func printHostDetail(host SSHHost) {
+	clearScreen()
+	printHeader(tr("Host: ") + host.Alias)
+
+	fmt.Printf(tr("Target:       %s\n"), valueOr(menuTarget(host), host.Alias))
+	if host.Port != "" {
+		fmt.Printf(tr("Port:         %s\n"), host.Port)
+	}
+	fmt.Printf(tr("Source:       %s\n"), valueOr(host.Source, sshConfigSource))
+	if line, err := connectLine(host); err != nil {
+		fmt.Printf(tr("Connect:      %s\n"), err)
+	} else if line != "" {
+		fmt.Printf(tr("Connect:      %s\n"), line)
+	}
+	if converts(host) {
+		fmt.Printf(tr("Encoding:     %s\n"), encodingDetail(host))
+	}
+	if isTeleport(host) {
+		fmt.Printf(tr("Teleport:     %s\n"), teleportLogin(host))
+	}
+	// ssh -G sees what the host's own block does not: Host patterns and
+	// includes can set the way there too
+	route := resolveRoute(host)
+	if route.ProxyJump != "" {
+		fmt.Printf(tr("Jump hosts:   %s\n"), route.ProxyJump)
+	}
+	if proxy := route.Proxy(); proxy != "" {
+		fmt.Printf(tr("Proxy:        %s\n"), proxy)
+		if proxy != route.ProxyCommand {
+			fmt.Printf(tr("              (%s as written)\n"), route.ProxyCommand)
+		}
+	}
+	if len(host.Tags) > 0 {
+		fmt.Printf(tr("Tags:         %s\n"), strings.Join(host.Tags, " "))
+	}
+	if keys := metaKeys(host); len(keys) > 0 {
+		fmt.Println(tr("\nInventory:"))
+		for _, key := range keys {
+			fmt.Printf("  %-12s %s\n", key+":", host.Meta[key])
+		}
+	}
+	if len(host.IdentityFiles) > 0 {
+		fmt.Printf(tr("Identities:   %s\n"), strings.Join(host.IdentityFiles, " "))
+	}
+	if notes := hostNotes(host.Alias); notes != "" {
+		fmt.Println(tr("\nNotes:"))
+		for _, line := range renderMarkdown(notes) {
+			fmt.Println("  " + line)
+		}
+	}
+
+	certs := hostCertificates(host)
+	if len(certs) == 0 {
+		fmt.Println(tr("\nCertificates: none"))
+	} else {
+		fmt.Println(tr("\nCertificates:"))
+	}
+	for _, cert := range certs {
+		fmt.Printf("  %s\n", cert.Path)
+		if cert.Err != nil {
+			fmt.Printf("    %s\n", colorize("31", cert.Problem()))
+			continue
+		}
+		fmt.Printf(tr("    Key ID:     %s\n"), cert.KeyID)
+		fmt.Printf(tr("    Valid:      %s\n"), cert.Validity())
+		fmt.Printf(tr("    Principals: %s\n"), valueOr(strings.Join(cert.Principals, ", "), tr("(any)")))
+		if problem := cert.Problem(); problem != "" {
+			fmt.Printf("    %s %s\n", failMark(), colorize("31", problem))
+		}
+	}
+	// Hosts reached without ssh have no ssh settings to show
+	if line, _ := connectLine(host); line == "" {
+		printSettingSources(host)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// HostIndex finds hosts by text without scanning them all: each host's
+// searchable text (alias, hostname, user, tags, metadata) is indexed by
+// trigram
+type HostIndex struct {
+	hosts    []SSHHost
+	text     []string         // lowercased searchable text per host
+	trigrams map[string][]int // trigram -> host positions, ascending
+}
+
+func newHostIndex(hosts []SSHHost) *HostIndex {
+	ix := &HostIndex{hosts: hosts, trigrams: make(map[string][]int)}
+	for i, h := range hosts {
+		fields := append([]string{h.Alias, h.HostName, effectiveUser(h)}, h.Tags...)
+		for _, key := range metaKeys(h) {
+			fields = append(fields, h.Meta[key])
+		}
+		text := strings.ToLower(strings.Join(fields, " "))
+		ix.text = append(ix.text, text)
+		seen := make(map[string]bool)
+		for _, t := range trigramsOf(text) {
+			if !seen[t] {
+				seen[t] = true
+				ix.trigrams[t] = append(ix.trigrams[t], i)
+			}
+		}
+	}
+	return ix
+}
+
+// trigramsOf returns every three-rune window of s
+func trigramsOf(s string) []string {
+	runes := []rune(s)
+	grams := []string{}
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
+
+// Search returns the hosts containing every space-separated word of
+// query, ignoring case, in their original order; "" returns all hosts
+func (ix *HostIndex) Search(query string) []SSHHost {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return ix.hosts
+	}
+
+	candidates := ix.candidates(words)
+	result := []SSHHost{}
+	for _, i := range candidates {
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(ix.text[i], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, ix.hosts[i])
+		}
+	}
+	return result
+}
+
+// candidates narrows the hosts to those having every trigram of the
+// words; words shorter than a trigram do not narrow anything
+func (ix *HostIndex) candidates(words []string) []int {
+	lists := [][]int{}
+	for _, word := range words {
+		for _, t := range trigramsOf(word) {
+			lists = append(lists, ix.trigrams[t])
+		}
+	}
+	if len(lists) == 0 {
+		all := make([]int, len(ix.hosts))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	// Intersect from the shortest list
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	result := lists[0]
+	for _, list := range lists[1:] {
+		result = intersectSorted(result, list)
+	}
+	return result
+}
+
+// intersectSorted returns the values present in both ascending lists
+func intersectSorted(a, b []int) []int {
+	result := []int{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
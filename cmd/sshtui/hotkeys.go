@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// functionKeys names the escape sequences of F1-F12 sent by xterm-like
+// terminals
+var functionKeys = map[string]string{
+	"\x1bOP": "F1", "\x1bOQ": "F2", "\x1bOR": "F3", "\x1bOS": "F4",
+	"\x1b[11~": "F1", "\x1b[12~": "F2", "\x1b[13~": "F3", "\x1b[14~": "F4",
+	"\x1b[15~": "F5", "\x1b[17~": "F6", "\x1b[18~": "F7", "\x1b[19~": "F8",
+	"\x1b[20~": "F9", "\x1b[21~": "F10", "\x1b[23~": "F11", "\x1b[24~": "F12",
+}
+
+// menuCommandKeys are the letters starting a main menu command, which
+// cannot be hotkeys
+const menuCommandKeys = "abcefhimnoqrstuvwxACDEFILMNPTW"
+
+// parseHotkey checks a Hotkey setting: F1 to F12, or a letter that is not
+// a menu command. Function keys are returned as F1...F12
+func parseHotkey(value string) (string, error) {
+	upper := strings.ToUpper(value)
+	for _, name := range functionKeys {
+		if upper == name {
+			return name, nil
+		}
+	}
+	if len(value) == 1 && (value[0] >= 'a' && value[0] <= 'z' || value[0] >= 'A' && value[0] <= 'Z') {
+		if strings.Contains(menuCommandKeys, value) {
+			return "", fmt.Errorf(tr("hotkey %s is a menu command"), value)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf(tr("invalid hotkey %q, use F1 to F12 or a letter"), value)
+}
+
+// hostHotkeys maps each hotkey to its host
+func hostHotkeys(hosts []SSHHost) map[string]SSHHost {
+	hotkeys := map[string]SSHHost{}
+	for _, host := range hosts {
+		if host.Hotkey != "" {
+			hotkeys[host.Hotkey] = host
+		}
+	}
+	return hotkeys
+}
+
+// hotkeyLabel is the " [F1]" shown after a host in the menu, or ""
+func hotkeyLabel(host SSHHost) string {
+	if host.Hotkey == "" {
+		return ""
+	}
+	return colorize("1;36", " ["+host.Hotkey+"]")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// HTTPCheckTimeout bounds the health check of a forwarded web port
+const HTTPCheckTimeout = 5 * time.Second
+
+// webPorts are remote ports served over HTTP, true for HTTPS
+var webPorts = map[int]bool{
+	80: false, 443: true, 3000: false, 5000: false, 5601: false,
+	8000: false, 8080: false, 8443: true, 8888: false, 9000: false,
+	9090: false, 9200: false, 15672: false,
+}
+
+// forwardURL is the local URL of a local forward to a web port, or ""
+func forwardURL(fwd PortForward) string {
+	if fwd.Type != "L" {
+		return ""
+	}
+	_, remotePort, err := net.SplitHostPort(fwd.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	port, _ := strconv.Atoi(remotePort)
+	https, ok := webPorts[port]
+	if !ok {
+		return ""
+	}
+
+	bind, localPort := "localhost", fwd.LocalPort
+	if i := strings.LastIndex(fwd.LocalPort, ":"); i >= 0 {
+		bind, localPort = fwd.LocalPort[:i], fwd.LocalPort[i+1:]
+	}
+	if bind == "*" || bind == "" || bind == "0.0.0.0" {
+		bind = "localhost"
+	}
+	if _, err := strconv.Atoi(localPort); err != nil {
+		return ""
+	}
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(strings.Trim(bind, "[]"), localPort))
+}
+
+// webForwards lists the URLs of the web forwards of running sessions, in
+// the order of the forward screen; callers hold sessionsMu
+func webForwards() []string {
+	urls := []string{}
+	for _, session := range sessions {
+		for _, fwd := range session.Forwards {
+			if url := forwardURL(fwd); url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// openBrowser opens url with the desktop's default browser
+func openBrowser(url string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	cmd := exec.Command(opener, url)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// checkHTTP requests url once and describes the answer: status, server
+// and response time. Certificates are not checked, as the name is
+// localhost
+func checkHTTP(url string) string {
+	client := &http.Client{
+		Timeout: HTTPCheckTimeout,
+		// Report redirects rather than follow them off the tunnel
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Sprintf("%s %s", failMark(), colorize("31", err.Error()))
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	mark := okMark()
+	if resp.StatusCode >= 500 {
+		mark = failMark()
+	}
+	result := fmt.Sprintf("%s %s in %v", mark, resp.Status, elapsed)
+	if server := resp.Header.Get("Server"); server != "" {
+		result += fmt.Sprintf(tr(", server %s"), server)
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		result += fmt.Sprintf(tr(", redirects to %s"), location)
+	}
+	return result
+}
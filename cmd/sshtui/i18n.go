@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// locale selects the message catalog; English strings are the catalog keys
+var locale = "en"
+
+// catalogs maps a locale to translations of the English UI strings
+var catalogs = map[string]map[string]string{
+	"fr": catalogFR,
+}
+
+// tr returns the translation of an English UI string for the current locale
+func tr(msg string) string {
+	if translated, ok := catalogs[locale][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// detectLocale picks the locale from the config, then SSHTUI_LANG,
+// LC_ALL, LC_MESSAGES and LANG
+func detectLocale(configured string) string {
+	candidates := []string{
+		configured,
+		os.Getenv("SSHTUI_LANG"),
+		os.Getenv("LC_ALL"),
+		os.Getenv("LC_MESSAGES"),
+		os.Getenv("LANG"),
+	}
+
+	for _, value := range candidates {
+		if value == "" {
+			continue
+		}
+		// fr_CA.UTF-8 -> fr
+		lang := strings.ToLower(value)
+		if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+			lang = lang[:i]
+		}
+		if lang == "en" || catalogs[lang] != nil {
+			return lang
+		}
+	}
+	return "en"
+}
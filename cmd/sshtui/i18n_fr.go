@@ -0,0 +1,745 @@
+package main
+
+// catalogFR holds the French UI strings
+var catalogFR = map[string]string{
+	// Main menu
+	"   sshtui - Session Manager":                     "   sshtui - Gestionnaire de sessions",
+	"Active Sessions:":                                "Sessions actives :",
+	"Connections (last attempt failed, F shows all):": "Connexions (dernière tentative échouée, F affiche tout) :",
+	"  none":                        "  aucune",
+	" - %s ago: %s":                 " - il y a %s : %s",
+	"Connections:":                  "Connexions :",
+	"\nCommands:":                   "\nCommandes :",
+	"  [number]  - Connect to host": "  [numéro]  - Se connecter à l'hôte",
+	"  o[number] - Connect with extra ssh options":                      "  o[numéro] - Se connecter avec des options ssh",
+	"  t[number] - Test connection (BatchMode, no session)":             "  t[numéro] - Tester la connexion (BatchMode, sans session)",
+	"  h[number] - Host details and certificates":                       "  h[numéro] - Détails de l'hôte et certificats",
+	"  [!number] - Resume session":                                      "  [!numéro] - Reprendre la session",
+	"  v         - View scrollback/history":                             "  v         - Voir l'historique",
+	"  c!number  - Toggle critical watch (alert, alert+reconnect, off)": "  c!numéro  - Surveillance critique (alerte, alerte+reconnexion, off)",
+	"  m         - Multi-host command":                                  "  m         - Commande multi-hôtes",
+	"  f         - Port forwards":                                       "  f         - Redirections de ports",
+	"  w         - Open workspace":                                      "  w         - Ouvrir un espace de travail",
+	"  s words   - Filter hosts (s alone clears); > and < page through": "  s mots    - Filtrer les hôtes (s seul efface) ; > et < changent de page",
+	"  matching %q: %d hosts, s clears\n":                               "  correspondant à %q : %d hôtes, s efface\n",
+	"  hosts %d-%d of %d, > next page, < previous\n":                    "  hôtes %d-%d sur %d, > page suivante, < précédente\n",
+	"  /         - Search past connections and commands (Ctrl+R style)": "  /         - Rechercher les connexions et commandes passées (façon Ctrl+R)",
+	"  F         - Toggle hosts whose last connection failed":           "  F         - Afficher/masquer les hôtes dont la dernière connexion a échoué",
+	"  r         - Reload SSH and sshtui config":                        "  r         - Recharger la configuration",
+	"  x         - Close active shell session":                          "  x         - Fermer la session shell active",
+	"  q         - Quit all":                                            "  q         - Tout quitter",
+	"\nIn session: Ctrl+Space to detach":                                "\nEn session : Ctrl+Espace pour détacher",
+
+	// Session states and labels
+	" <- needs input, attach or P to answer": " <- attend une saisie, attachez-vous ou P pour répondre",
+	"connecting":                             "connexion",
+	"auth prompt":                            "authentification",
+	"touch key":                              "toucher la clé",
+	"ready":                                  "prête",
+	"detached":                               "détachée",
+	"attached":                               "attachée",
+	"dead":                                   "terminée",
+	"reconnecting":                           "reconnexion",
+	" forward-only":                          " redirection seule",
+	" [forward only]":                        " [redirection seule]",
+	" [critical]":                            " [critique]",
+	" [critical+reconnect]":                  " [critique+reconnexion]",
+	" (reconnecting...)":                     " (reconnexion...)",
+	"CRITICAL: session [!%d] %s is down":     "CRITIQUE : la session [!%d] %s est tombée",
+
+	// Prompts and errors
+	"Press Enter to continue...":                         "Appuyez sur Entrée pour continuer...",
+	"\nPress Enter...":                                   "\nAppuyez sur Entrée...",
+	"Error: %v\n":                                        "Erreur : %v\n",
+	"Error: %v\nPress Enter...":                          "Erreur : %v\nAppuyez sur Entrée...",
+	"Error reading input: %v\n":                          "Erreur de lecture : %v\n",
+	"\nError reading input: %v\n":                        "\nErreur de lecture : %v\n",
+	"Error reloading config: %v\nPress Enter...":         "Erreur de rechargement : %v\nAppuyez sur Entrée...",
+	"SSH config reloaded (%d hosts)\nPress Enter...":     "Configuration rechargée (%d hôtes)\nAppuyez sur Entrée...",
+	"Invalid command. Press Enter to continue...":        "Commande invalide. Appuyez sur Entrée pour continuer...",
+	"Invalid format: %s (expected !number)\n":            "Format invalide : %s (attendu !numéro)\n",
+	"Invalid host number":                                "Numéro d'hôte invalide",
+	"Invalid host number. Press Enter to continue...":    "Numéro d'hôte invalide. Appuyez sur Entrée pour continuer...",
+	"Invalid host number. Press Enter...":                "Numéro d'hôte invalide. Appuyez sur Entrée...",
+	"Invalid session number":                             "Numéro de session invalide",
+	"Invalid session number. Press Enter to continue...": "Numéro de session invalide. Appuyez sur Entrée pour continuer...",
+	"Invalid session number: %d (have %d sessions)\n":    "Numéro de session invalide : %d (%d sessions)\n",
+	"No active sessions":                                 "Aucune session active",
+	"Which session? [!number or ~number]: ":              "Quelle session ? [!numéro ou ~numéro] : ",
+	"Unknown option: %s (see --help)\n":                  "Option inconnue : %s (voir --help)\n",
+	"Warning: could not remember options: %v\n":          "Attention : impossible de mémoriser les options : %v\n",
+	"Warning: debug capture disabled: %v\n":              "Attention : capture de débogage désactivée : %v\n",
+	"connection timeout after %v":                        "délai de connexion dépassé après %v",
+
+	// Help
+	"sshtui - SSH session manager":      "sshtui - gestionnaire de sessions SSH",
+	"Version: %s\n\n":                   "Version : %s\n\n",
+	"Usage: sshtui [options] [host]":    "Utilisation : sshtui [options] [hôte]",
+	"\nOptions:":                        "\nOptions :",
+	"  -v, --version      Show version": "  -v, --version      Afficher la version",
+	"  -h, --help         Show help":    "  -h, --help         Afficher l'aide",
+	"  --debug-connect    Capture ssh -vvv logs and diagnose failed connects":         "  --debug-connect    Capturer les journaux ssh -vvv et diagnostiquer les échecs",
+	"  --plain            Screen-reader friendly output (no boxes, colors or clears)": "  --plain            Sortie adaptée aux lecteurs d'écran (sans cadres ni couleurs)",
+	"  --pick             Choose a host, print its alias and exit":                    "  --pick             Choisir un hôte, afficher son alias et quitter",
+	"\nWith a host alias, connect to it before showing the menu.":                     "\nAvec un alias d'hôte, s'y connecter avant d'afficher le menu.",
+	"Unknown host: %s\n": "Hôte inconnu : %s\n",
+	"       sshtui --run COMMAND [--quiet] host|glob...":                                                    "       sshtui --run COMMANDE [--quiet] hôte|motif...",
+	"       sshtui --test [--quiet] host|glob...":                                                           "       sshtui --test [--quiet] hôte|motif...",
+	"  --run COMMAND      Run COMMAND on the hosts, output prefixed by host":                                "  --run COMMANDE     Lancer COMMANDE sur les hôtes, sortie préfixée par hôte",
+	"  --test             Test logging into the hosts (BatchMode)":                                          "  --test             Tester la connexion aux hôtes (BatchMode)",
+	"  -q, --quiet        With --run or --test, print only failures":                                        "  -q, --quiet        Avec --run ou --test, n'afficher que les échecs",
+	"--run and --test exit with 0 when all hosts succeed, 1 when some fail,\n2 on a config or usage error.": "--run et --test renvoient 0 si tous les hôtes réussissent, 1 si certains\néchouent, 2 en cas d'erreur de configuration ou d'utilisation.",
+	"--run needs a command (see --help)":                                                                    "--run attend une commande (voir --help)",
+	"--run and --test cannot be combined":                                                                   "--run et --test ne peuvent pas être combinés",
+	"No hosts given (see --help)":                                                                           "Aucun hôte indiqué (voir --help)",
+	"Only one host can be connected to at once (see --help)":                                                "Un seul hôte peut être connecté à la fois (voir --help)",
+	"no host matches %s":                                   "aucun hôte ne correspond à %s",
+	"tagged %s, needs confirmation":                        "étiqueté %s, confirmation requise",
+	"  --list             Print all host aliases and exit": "  --list             Afficher tous les alias d'hôtes et quitter",
+	"Pick a Host":                                          "Choisir un hôte",
+	"\nNumber or alias (q or empty to cancel): ":           "\nNuméro ou alias (q ou vide pour annuler) : ",
+
+	// Sessions
+	"\nConnecting to %s...\n":                          "\nConnexion à %s...\n",
+	"Connected: ":                                      "Connecté : ",
+	"Session has ended. Press Enter...":                "La session est terminée. Appuyez sur Entrée...",
+	"\n--- [Scrollback end, live session resumed] ---": "\n--- [Fin de l'historique, session reprise] ---",
+	"\n\n[Detached]\n":                                 "\n\n[Détaché]\n",
+	"\n\nPanic recovered: %v\n":                        "\n\nPanique récupérée : %v\n",
+	"Terminal state restored. Press Enter...":          "Terminal restauré. Appuyez sur Entrée...",
+	"\r\n[sshtui] reconnected (attempt %d)\r\n":        "\r\n[sshtui] reconnecté (tentative %d)\r\n",
+
+	// Session detail
+	"  i!number  - Session process tree and signals": "  i!numéro  - Arbre de processus et signaux",
+	"Session: ":     "Session : ",
+	"Status:  %s\n": "État :     %s\n",
+	"Command: %s\n": "Commande : %s\n",
+	"No process":    "Aucun processus",
+	"PID:     %d\n": "PID :      %d\n",
+	"Exit:    %s\n": "Sortie :   %s\n",
+	"\nProcess tree (PID, RSS, CPU, elapsed, command):": "\nArbre de processus (PID, RSS, CPU, durée, commande) :",
+	"  i         - Send SIGINT":                         "  i         - Envoyer SIGINT",
+	"  t         - Send SIGTERM":                        "  t         - Envoyer SIGTERM",
+	"  k         - Send SIGKILL":                        "  k         - Envoyer SIGKILL",
+	"  r         - Refresh":                             "  r         - Rafraîchir",
+
+	// Bulk operations
+	"  b         - Bulk session operations":                   "  b         - Opérations groupées sur les sessions",
+	"Bulk Session Operations":                                 "Opérations groupées",
+	"  e         - Close all ended sessions":                  "  e         - Fermer les sessions terminées",
+	"  g         - Close sessions matching a host glob":       "  g         - Fermer les sessions selon un motif d'hôte",
+	"  r         - Reconnect all ended sessions":              "  r         - Reconnecter les sessions terminées",
+	"  d         - Detach all sessions attached elsewhere":    "  d         - Détacher les sessions attachées ailleurs",
+	"  X         - Close all sessions, running ones included": "  X         - Fermer toutes les sessions, même actives",
+	"Detach":                               "Détacher",
+	"No matching sessions. Press Enter...": "Aucune session correspondante. Appuyez sur Entrée...",
+	"\n%s %d session(s):\n":                "\n%s %d session(s) :\n",
+	"\nProceed? [y/N]: ":                   "\nContinuer ? [y/N] : ",
+	"Close":                                "Fermer",
+	"Reconnect":                            "Reconnecter",
+	"Host glob (e.g. web*): ":              "Motif d'hôte (ex. web*) : ",
+	"Invalid pattern. Press Enter...":      "Motif invalide. Appuyez sur Entrée...",
+
+	// Send file
+	"Ctrl+^ to toggle I/O stats":                      "Ctrl+^ : statistiques d'E/S",
+	"Ctrl+] to send a file":                           "Ctrl+] pour envoyer un fichier",
+	"\r\n[sshtui] Send local file (Esc cancels): ":    "\r\n[sshtui] Envoyer un fichier local (Échap annule) : ",
+	"[sshtui] %v\r\n":                                 "[sshtui] %v\r\n",
+	"[sshtui] file too large (%d bytes, max %d)\r\n":  "[sshtui] fichier trop gros (%d octets, max %d)\r\n",
+	"[sshtui] Remote name [%s]: ":                     "[sshtui] Nom distant [%s] : ",
+	"[sshtui] Transfer with [b]ase64 or [c]at? [b]: ": "[sshtui] Transférer en [b]ase64 ou avec [c]at ? [b] : ",
+
+	// Host providers
+	"unknown provider %s (add a command for a script provider)": "fournisseur %s inconnu (ajoutez une commande pour un fournisseur script)",
+	"provider %s: unknown option %s":                            "fournisseur %s : option %s inconnue",
+	"provider %s: offline must be hide or show":                 "fournisseur %s : offline doit valoir hide ou show",
+	"offline":                                 "hors ligne",
+	"provider %s: zone= is required":          "fournisseur %s : zone= est obligatoire",
+	"dns-zone: transfer of %s refused":        "dns-zone : transfert de %s refusé",
+	"provider %s: expected key=value, got %q": "fournisseur %s : key=value attendu, reçu %q",
+	"  Provider error: %s\n":                  "  Erreur de fournisseur : %s\n",
+
+	// Output tee
+	"%s (exited: %s)":                              "%s (terminée : %s)",
+	"%s (%d chunks dropped)":                       "%s (%d blocs perdus)",
+	"[sshtui] tee failed: %v\r\n":                  "[sshtui] échec du tee : %v\r\n",
+	"I/O:     %s out, %s in\n":                     "E/S :     %s sortis, %s entrés\n",
+	"Tee:     %s\n":                                "Tee :     %s\n",
+	"  p         - Pipe output to a local command": "  p         - Envoyer la sortie à une commande locale",
+	"\nLocal command fed with the session output, e.g. grep --line-buffered ERROR >> alerts.log": "\nCommande locale recevant la sortie de la session, ex. grep --line-buffered ERROR >> alerts.log",
+	"Enter keeps the current one, '-' removes it: ":                                              "Entrée garde l'actuelle, '-' la supprime : ",
+
+	// Remote tmux
+	"  a[number] - Remote tmux sessions of host": "  a[numéro] - Sessions tmux distantes de l'hôte",
+	"tmux is not installed on the host":          "tmux n'est pas installé sur l'hôte",
+	"\nListing tmux sessions on %s...\n":         "\nListe des sessions tmux sur %s...\n",
+	"tmux sessions: ":                            "Sessions tmux : ",
+	"No tmux sessions":                           "Aucune session tmux",
+	" (attached)":                                " (attachée)",
+	"  [%d] %s - %s windows%s\n":                 "  [%d] %s - %s fenêtres%s\n",
+	"  [number]  - Attach to tmux session":       "  [numéro]  - S'attacher à la session tmux",
+	"  n [name]  - New tmux session":             "  n [nom]   - Nouvelle session tmux",
+
+	// Extra options
+	"\nExtra ssh options (e.g. -i ~/.ssh/key -4 -o ServerAliveInterval=30)": "\nOptions ssh supplémentaires (ex. -i ~/.ssh/cle -4 -o ServerAliveInterval=30)",
+	"Enter keeps last used: %s, '-' clears, 'q' cancels\n":                  "Entrée garde les dernières : %s, '-' efface, 'q' annule\n",
+	"Enter for none, 'q' cancels":                                           "Entrée pour aucune, 'q' annule",
+
+	// Scrollback viewer
+	"Scrollback: ": "Historique : ",
+	"No scrollback available. Press Enter...": "Aucun historique. Appuyez sur Entrée...",
+	"Search: ":             "Recherche : ",
+	"Matches: %d":          "Résultats : %d",
+	"\n[Line %d/%d %d%%] ": "\n[Ligne %d/%d %d%%] ",
+	"[Match %d/%d] ":       "[Résultat %d/%d] ",
+	"Command: ":            "Commande : ",
+
+	// Host selection and multi-host
+	"Select Hosts (space to toggle)":            "Sélection des hôtes",
+	"  [number]  - Toggle selection":            "  [numéro]  - Basculer la sélection",
+	"  a         - Select all but this machine": "  a         - Tout sélectionner sauf cette machine",
+	"  c         - Clear all":                   "  c         - Tout désélectionner",
+	"  d         - Done (execute)":              "  d         - Terminé (exécuter)",
+	"  q         - Cancel":                      "  q         - Annuler",
+	"No hosts selected. Press Enter...":         "Aucun hôte sélectionné. Appuyez sur Entrée...",
+	"\nEnter command to execute (or @script.sh args to upload and run a script): ": "\nCommande à exécuter (ou @script.sh args pour envoyer et lancer un script) : ",
+	"upload failed: %s":                               "échec de l'envoi : %s",
+	"upload failed: %v":                               "échec de l'envoi : %v",
+	"upload failed: no temp path returned":            "échec de l'envoi : aucun chemin temporaire",
+	"\nDisplay mode:\n":                               "\nMode d'affichage :\n",
+	"  [1] Live streaming (see output as it arrives)": "  [1] En direct (sortie au fil de l'eau)",
+	"  [2] Collected results (all at once)":           "  [2] Résultats groupés (en une fois)",
+	"Multi-Host Execution (Live)":                     "Exécution multi-hôtes (direct)",
+	"Multi-Host Execution (Collecting...)":            "Exécution multi-hôtes (collecte...)",
+	"Multi-Host Results":                              "Résultats multi-hôtes",
+	"Command: %s\n\n":                                 "Commande : %s\n\n",
+	"Host: %s\n":                                      "Hôte : %s\n",
+	"error starting: %v":                              "erreur au démarrage : %v",
+	"Type c and Enter to cancel, p<number> to pin a host full-screen, p to unpin\n": "Tapez c puis Entrée pour annuler, p<numéro> pour afficher un hôte en plein écran, p pour revenir\n",
+	"Pinned: ":                "Épinglé : ",
+	"p to unpin, c to cancel": "p pour désépingler, c pour annuler",
+	"Type c and Enter to cancel the hosts still running\n\n": "Tapez c puis Entrée pour annuler les hôtes encore en cours\n\n",
+	"\nTimeout per host (e.g. 30s, 5m, 0 for none) [%v]: ":   "\nDélai par hôte (ex. 30s, 5m, 0 pour aucun) [%v] : ",
+	"Cancelling...":                        "Annulation...",
+	"exit status %d":                       "code de sortie %d",
+	"cancelled":                            "annulé",
+	"invalid timeout %q":                   "délai invalide %q",
+	"timed out after %v":                   "délai dépassé après %v",
+	"\nExecution complete. Press Enter...": "\nExécution terminée. Appuyez sur Entrée...",
+
+	// Port forwards
+	"Port Forward Management":                                 "Gestion des redirections de ports",
+	"Configured Forwards:":                                    "Redirections configurées :",
+	"    Local:   %s → %s\n":                                  "    Locale :    %s → %s\n",
+	"    Remote:  %s → %s\n":                                  "    Distante :  %s → %s\n",
+	"    Dynamic: %s (SOCKS)\n":                               "    Dynamique : %s (SOCKS)\n",
+	"  No port forwards configured":                           "  Aucune redirection configurée",
+	"\n\nActive Session Forwards:":                            "\n\nRedirections des sessions actives :",
+	"\n  Session [!%d] %s (%s)%s:\n":                          "\n  Session [!%d] %s (%s)%s :\n",
+	"  No active forwards":                                    "  Aucune redirection active",
+	"\n\nNote: Port forwards are configured in ~/.ssh/config": "\n\nNote : les redirections se configurent dans ~/.ssh/config",
+	"Format:": "Format :",
+	"  n         - New quick forward (ssh -N)":                   "  n         - Nouvelle redirection rapide (ssh -N)",
+	"  t         - Tunnel a host's configured forwards (ssh -N)": "  t         - Tunnel des redirections d'un hôte (ssh -N)",
+	"  x!number  - Close forward session":                        "  x!numéro  - Fermer une session de redirection",
+	"  q         - Back to main menu":                            "  q         - Retour au menu principal",
+	"\nHosts:":                                                   "\nHôtes :",
+	"\nHost number: ":                                            "\nNuméro d'hôte : ",
+	"\nHosts with forwards:":                                     "\nHôtes avec redirections :",
+	"No hosts with configured forwards. Press Enter...":          "Aucun hôte avec redirections. Appuyez sur Entrée...",
+	"Forward (L 8080 localhost:80 | R 9090 localhost:80 | D 1080), ? scans the host's ports: ": "Redirection (L 8080 localhost:80 | R 9090 localhost:80 | D 1080), ? scanne les ports de l'hôte : ",
+	"Invalid forward. Press Enter...":                  "Redirection invalide. Appuyez sur Entrée...",
+	"\nStarting forward%s on %s...\n":                  "\nDémarrage de la redirection%s sur %s...\n",
+	"Forward running as session [!%d]\nPress Enter...": "Redirection active en session [!%d]\nAppuyez sur Entrée...",
+
+	// Config reload
+	"\nHost changes:":  "\nChangements d'hôtes :",
+	"\nApply? [Y/n]: ": "\nAppliquer ? [Y/n] : ",
+
+	// Time report
+	"  T         - Time report":      "  T         - Rapport de temps",
+	"Time Report (last %d days)":     "Rapport de temps (%d derniers jours)",
+	"  No attached time recorded":    "  Aucun temps enregistré",
+	"Total":                          "Total",
+	"  d         - Today":            "  d         - Aujourd'hui",
+	"  w         - Last 7 days":      "  w         - 7 derniers jours",
+	"  m         - Last 30 days":     "  m         - 30 derniers jours",
+	"  e         - Export CSV":       "  e         - Exporter en CSV",
+	"Exported to %s\nPress Enter...": "Exporté vers %s\nAppuyez sur Entrée...",
+
+	// Tag confirmation
+	"\n%s is tagged %q. Type the alias to confirm: ": "\n%s porte le tag %q. Retapez l'alias pour confirmer : ",
+	"Not confirmed, skipping. Press Enter...":        "Non confirmé, ignoré. Appuyez sur Entrée...",
+
+	// Workspaces
+	"\nWorkspaces:":        "\nEspaces de travail :",
+	"\nWorkspace number: ": "\nNuméro d'espace : ",
+	"No workspaces configured (see README). Press Enter...":     "Aucun espace de travail configuré (voir README). Appuyez sur Entrée...",
+	"\nOpening workspace %s...\n":                               "\nOuverture de l'espace %s...\n",
+	"    waiting for %s...\n":                                   "    attente de %s...\n",
+	"  %s %s: not in ssh config\n":                              "  %s %s : absent de la configuration ssh\n",
+	"  %s %s: skipped, %s is not up\n":                          "  %s %s : ignoré, %s n'est pas joignable\n",
+	"  %s %s: no response after %v\n":                           "  %s %s : pas de réponse après %v\n",
+	"\nWorkspace opened. Sessions are detached. Press Enter...": "\nEspace ouvert. Les sessions sont détachées. Appuyez sur Entrée...",
+	"dependency cycle at %s":                                    "cycle de dépendances sur %s",
+	"%s is not a member of workspace %s":                        "%s n'appartient pas à l'espace %s",
+
+	// Config file
+	"%s:%d: missing value for %s":                      "%s:%d : valeur manquante pour %s",
+	"%s:%d: unknown option %s":                         "%s:%d : option inconnue %s",
+	"%s:%d: %s outside of a %s block":                  "%s:%d : %s hors d'un bloc %s",
+	"%s:%d: PageSize must be a positive number":        "%s:%d: PageSize doit être un nombre positif",
+	"%s:%d: Confirm must be yes, no, connect or multi": "%s:%d : Confirm doit valoir yes, no, connect ou multi",
+	"after needs a host list":                          "after attend une liste d'hôtes",
+	"unexpected %q in Open":                            "%q inattendu dans Open",
+
+	// Control masters
+	"\nLingering SSH control masters:": "\nConnexions maîtres SSH restantes :",
+	"\nClose them? [y/N]: ":            "\nLes fermer ? [y/N] : ",
+
+	// Connect debugging
+	"Connect debug: ":             "Débogage connexion : ",
+	"ssh exited with status %d\n": "ssh s'est terminé avec le code %d\n",
+	"Likely cause: %s\n":          "Cause probable : %s\n",
+	"Full log: %s\n\n":            "Journal complet : %s\n\n",
+	"host key changed":            "clé d'hôte modifiée",
+	"host key verification":       "vérification de la clé d'hôte",
+	"DNS resolution":              "résolution DNS",
+	"timeout":                     "délai dépassé",
+	"connection refused":          "connexion refusée",
+	"network unreachable":         "réseau injoignable",
+	"authentication failure":      "échec d'authentification",
+
+	// Connection test
+	"Connection test: ":                           "Test de connexion : ",
+	"Running ssh -o BatchMode=yes %s true...\n\n": "Exécution de ssh -o BatchMode=yes %s true...\n\n",
+	"Reached the host, but no key or agent login worked (BatchMode skips password prompts)": "Hôte joint, mais aucune connexion par clé ou agent n'a réussi (BatchMode saute les mots de passe)",
+	"%s Logged in as %s\n": "%s Connecté en tant que %s\n",
+	"the default user":     "l'utilisateur par défaut",
+	"Server:        %s\n":  "Serveur :          %s\n",
+	"Auth method:   %s\n":  "Authentification : %s\n",
+	"Connected in:  %v\n":  "Connecté en :      %v\n",
+	"Logged in in:  %v\n":  "Identifié en :     %v\n",
+	"Total:         %v\n":  "Total :            %v\n",
+	"\nBanner:":            "\nBannière :",
+
+	// I/O stats
+	"out %s/s, %s total, in %s": "sortie %s/s, %s au total, entrée %s",
+
+	// Status bar
+	"Ctrl+Space detach": "Ctrl+Espace détacher",
+	"Ctrl+] send file":  "Ctrl+] envoyer un fichier",
+	"Ctrl+^ I/O stats":  "Ctrl+^ stats E/S",
+
+	// History search
+	"No history yet. Press Enter...":                                        "Pas encore d'historique. Appuyez sur Entrée...",
+	"Host %s is no longer configured. Press Enter...":                       "L'hôte %s n'est plus configuré. Appuyez sur Entrée...",
+	"Skipping %s: no longer configured\n":                                   "%s ignoré : n'est plus configuré\n",
+	"History Search":                                                        "Recherche dans l'historique",
+	"Type to search, Ctrl+R for older matches, Enter to run, Esc to cancel": "Tapez pour chercher, Ctrl+R pour les plus anciens, Entrée pour lancer, Échap pour annuler",
+	"no match":                   "aucun résultat",
+	"(reverse-i-search)`%s': %s": "(recherche-inverse)`%s' : %s",
+	"\nCommand: %s\n":            "\nCommande : %s\n",
+
+	// Attach lock
+	"Session %s is attached elsewhere. Detach it and take over? [y/N]: ": "La session %s est attachée ailleurs. La détacher et la reprendre ? [y/N] : ",
+	" <- attached elsewhere, resuming takes it over":                     " <- attachée ailleurs, la reprendre la détache",
+
+	// Certificates and host details
+	"unexpected validity %q":  "validité inattendue %q",
+	"unreadable: %v":          "illisible : %v",
+	"expired on %s":           "expiré le %s",
+	"not valid until %s":      "pas valide avant le %s",
+	"forever":                 "pour toujours",
+	"from %s":                 "à partir du %s",
+	"until %s":                "jusqu'au %s",
+	"%s to %s":                "du %s au %s",
+	"\nCertificate warning:":  "\nAvertissement de certificat :",
+	"Connect anyway? [y/N]: ": "Se connecter quand même ? [y/N] : ",
+	"Host: ":                  "Hôte : ",
+	"Target:       %s\n":      "Cible :        %s\n",
+	"Port:         %s\n":      "Port :         %s\n",
+	"Source:       %s\n":      "Source :       %s\n",
+	"Tags:         %s\n":      "Étiquettes :   %s\n",
+	"Identities:   %s\n":      "Identités :    %s\n",
+	"\nCertificates: none":    "\nCertificats : aucun",
+	"\nCertificates:":         "\nCertificats :",
+	"    Key ID:     %s\n":    "    ID de clé : %s\n",
+	"    Valid:      %s\n":    "    Validité :  %s\n",
+	"    Principals: %s\n":    "    Principaux : %s\n",
+	"(any)":                   "(tous)",
+
+	// Issued certificates
+	"Warning: %v\n": "Attention : %v\n",
+	"certificate command for tag %s failed: %v %s":  "la commande de certificat du tag %s a échoué : %v %s",
+	"certificate issued for tag %s is unusable: %s": "le certificat émis pour le tag %s est inutilisable : %s",
+
+	// Watch patterns
+	"MATCH: %s %s: %s":                                      "CORRESPONDANCE : %s %s : %s",
+	"MATCH: %d more, n to view all":                         "CORRESPONDANCE : %d de plus, n pour tout voir",
+	"[closed]":                                              "[fermée]",
+	"Watch pattern matches":                                 "Correspondances des motifs surveillés",
+	"No WatchPattern in the sshtui config":                  "Aucun WatchPattern dans la config sshtui",
+	"Patterns: %s\n\n":                                      "Motifs : %s\n\n",
+	"\nc clears the list, Enter goes back: ":                "\nc vide la liste, Entrée pour revenir : ",
+	"  n         - Watch pattern matches in session output": "  n         - Correspondances des motifs surveillés dans les sessions",
+
+	// Bell
+	" [bell]": " [cloche]",
+
+	// Duplicate sessions
+	"\n%s already has an open session:\n":                          "\n%s a déjà une session ouverte :\n",
+	"a attaches to the last one, n opens another, Enter cancels: ": "a rejoint la dernière, n en ouvre une autre, Entrée annule : ",
+
+	// NetBox and inventory metadata
+	"provider %s: url= is required": "fournisseur %s : url= est obligatoire",
+	"%s returned %s":                "%s a répondu %s",
+	"\nInventory:":                  "\nInventaire :",
+
+	// Regulated hosts
+	"\n%s tagged %q: ticket or reason (empty cancels): ": "\n%s étiqueté %q : ticket ou motif (vide pour annuler) : ",
+	"Warning: audit log: %v\n":                           "Attention : journal d'audit : %v\n",
+	"Skipping %s\n":                                      "%s ignoré\n",
+	"%s:%d: %s must be yes or no":                        "%s:%d: %s doit valoir yes ou no",
+	"[sshtui] recording failed: %v\r\n":                  "[sshtui] échec de l'enregistrement : %v\r\n",
+	"[sshtui] tagged %s, recording to %s\r\n":            "[sshtui] étiqueté %s, enregistrement dans %s\r\n",
+	"tagged %s, needs a reason":                          "étiqueté %s, motif requis",
+
+	// Updates
+	"       sshtui version | self-update":            "       sshtui version | self-update",
+	"sshtui %s is available (sshtui self-update)":    "sshtui %s est disponible (sshtui self-update)",
+	"sshtui %s is available (sshtui self-update)\n":  "sshtui %s est disponible (sshtui self-update)\n",
+	"Could not check for updates: %v\n":              "Impossible de vérifier les mises à jour : %v\n",
+	"Up to date":                                     "À jour",
+	"no release build for %s, build from source":     "aucune version publiée pour %s, compilez depuis les sources",
+	"sshtui v%s is up to date\n":                     "sshtui v%s est à jour\n",
+	"release %s has no %s with a .sha256 checksum":   "la version %s n'a pas de %s avec une somme .sha256",
+	"Downloading sshtui %s...\n":                     "Téléchargement de sshtui %s...\n",
+	"checksum mismatch, the binary was not replaced": "somme de contrôle différente, le binaire n'a pas été remplacé",
+	"Updated %s to %s\n":                             "%s mis à jour en %s\n",
+
+	// Host colors
+	"Hosts: %s\n\n": "Hôtes : %s\n\n",
+
+	// Jump hosts
+	"%s via %s":           "%s via %s",
+	"via %s: %s":          "via %s : %s",
+	"Via:           %s\n": "Via :              %s\n",
+	"Jump host %s failed (%s), %s was not reached\n": "Le rebond %s a échoué (%s), %s n'a pas été atteint\n",
+
+	// PTY size
+	"%s:%d: PTYSize must be auto or COLUMNSxROWS, e.g. 200x50": "%s:%d: PTYSize doit être auto ou COLONNESxLIGNES, par ex. 200x50",
+
+	// Archived sessions
+	"Archived (scrollback only, v to view):": "Archivées (historique seulement, v pour voir) :",
+	"  [~%d] %s, ended %s\n":                 "  [~%d] %s, terminée à %s\n",
+	"  and %d more\n":                        "  et %d de plus\n",
+	"  e         - Clear ended sessions (scrollback kept as archived)": "  e         - Retirer les sessions terminées (historique archivé)",
+
+	// Hotkeys
+	"hotkey %s is a menu command":                           "le raccourci %s est une commande du menu",
+	"invalid hotkey %q, use F1 to F12 or a letter":          "raccourci %q invalide, utiliser F1 à F12 ou une lettre",
+	"  [key]     - Connect to the host shown with that key": "  [touche]  - Se connecter à l'hôte affiché avec cette touche",
+
+	// External terminal windows
+	"no terminal found, set Terminal in the sshtui config":                              "aucun terminal trouvé, définir Terminal dans la config sshtui",
+	"%s is recorded (tagged %q) and opens inside sshtui\n":                              "%s est enregistré (étiqueté %q) et s'ouvre dans sshtui\n",
+	"%s:%d: Terminal must be kitty, wezterm, alacritty, iterm2 or a command with {ssh}": "%s:%d: Terminal doit être kitty, wezterm, alacritty, iterm2 ou une commande avec {ssh}",
+	"  W[number] - Connect in a new terminal window":                                    "  W[numéro] - Se connecter dans une nouvelle fenêtre du terminal",
+
+	// Remote port scan
+	"Scanning listening ports on %s...\n":          "Recherche des ports en écoute sur %s...\n",
+	"No listening TCP ports found. Press Enter...": "Aucun port TCP en écoute. Appuyez sur Entrée...",
+	"\nListening ports:":                           "\nPorts en écoute :",
+	"\nPort number: ":                              "\nNuméro du port : ",
+	"Invalid number. Press Enter...":               "Numéro invalide. Appuyez sur Entrée...",
+	"Local port [%d]: ":                            "Port local [%d] : ",
+
+	// Forward usage
+	"%d connections (%d open), %s in, %s out": "%d connexions (%d ouvertes), %s entrants, %s sortants",
+	", last %s ago": ", dernière il y a %s",
+	", never used":  ", jamais utilisée",
+
+	// Web forwards
+	", server %s":       ", serveur %s",
+	", redirects to %s": ", redirige vers %s",
+	"  o[number] - Open web forward in the browser":  "  o[numéro] - Ouvrir la redirection web dans le navigateur",
+	"  c[number] - Check web forward (HTTP request)": "  c[numéro] - Vérifier la redirection web (requête HTTP)",
+
+	// Host cloning
+	"\nClone %s (%s)\n":                                     "\nCloner %s (%s)\n",
+	"New alias [%s]: ":                                      "Nouvel alias [%s] : ",
+	"Invalid alias. Press Enter...":                         "Alias invalide. Appuyez sur Entrée...",
+	"%s already exists. Press Enter...":                     "%s existe déjà. Appuyez sur Entrée...",
+	"HostName [%s]: ":                                       "HostName [%s] : ",
+	"Invalid hostname. Press Enter...":                      "Nom d'hôte invalide. Appuyez sur Entrée...",
+	"Write it to %s? [y/N]: ":                               "L'écrire dans %s ? [y/N] : ",
+	"%s added until the config is reloaded. Press Enter...": "%s ajouté jusqu'au rechargement de la config. Appuyez sur Entrée...",
+	"%s written to %s. Press Enter...":                      "%s écrit dans %s. Appuyez sur Entrée...",
+	"  C[number] - Clone host to a new alias and hostname":  "  C[numéro] - Cloner l'hôte vers un nouvel alias et nom d'hôte",
+
+	// Numeric ranges
+	"invalid range [%s-%s] in %s":      "plage [%s-%s] invalide dans %s",
+	"%s expands to more than %d names": "%s donne plus de %d noms",
+	"provider %s: %v":                  "fournisseur %s : %v",
+	"provider %s: hosts= is required":  "fournisseur %s : hosts= est obligatoire",
+
+	// Output pause
+	"\r\n[sshtui] output paused, Ctrl+_ resumes (typing still goes to the session)\r\n": "\r\n[sshtui] affichage en pause, Ctrl+_ reprend (la saisie va toujours à la session)\r\n",
+	"\r\n[sshtui] %s skipped, see the scrollback (v)\r\n":                               "\r\n[sshtui] %s sautés, voir l'historique (v)\r\n",
+	"Ctrl+_ to pause output": "Ctrl+_ pour mettre l'affichage en pause",
+
+	// Type-ahead while connecting
+	"(%d typed characters discarded)\n": "(%d caractères tapés ignorés)\n",
+
+	// Ctrl+Space escape
+	"Ctrl+Space to detach (twice sends it)": "Ctrl+Espace pour détacher (deux fois l'envoie)",
+
+	// Notifications
+	"%d more, N to view all":                        "%d de plus, N pour tout voir",
+	"Notifications":                                 "Notifications",
+	"session %s ended: %s":                          "session %s terminée : %s",
+	"session %s reconnected (attempt %d)":           "session %s reconnectée (tentative %d)",
+	"forward %s%s failed: ssh exited %d times (%v)": "redirection %s%s en échec : ssh s'est arrêté %d fois (%v)",
+	"config reloaded (%d hosts)":                    "configuration rechargée (%d hôtes)",
+	"%d ended sessions archived":                    "%d sessions terminées archivées",
+	"Notifications (N to view and dismiss):":        "Notifications (N pour les voir et les effacer) :",
+	"  N         - Notifications (sessions ended, forwards failed, reloads)": "  N         - Notifications (sessions terminées, redirections en échec, rechargements)",
+
+	// Control masters screen
+	"SSH connection sharing":                                          "Partage de connexion SSH",
+	"Checking control masters...":                                     "Vérification des ControlMaster...",
+	"  none: no host connected from here has a running ControlMaster": "  aucun : aucun hôte connecté depuis ici n'a de ControlMaster actif",
+	"  [%d] %s, pid %s, %d sessions, up %s\n      %s\n":               "  [%d] %s, pid %s, %d sessions, actif depuis %s\n      %s\n",
+	"\nc[number] checks, x[number] stops, Enter goes back: ":          "\nc[numéro] vérifie, x[numéro] arrête, Entrée pour revenir : ",
+	"  %s %s stopped\n":                                               "  %s %s arrêté\n",
+	"%d sessions of %s go through it and will drop. Stop it? [y/N]: ": "%d sessions de %s passent par lui et seront coupées. L'arrêter ? [y/N] : ",
+	"  M         - SSH connection sharing (control masters)":          "  M         - Partage de connexion SSH (ControlMaster)",
+
+	// Rc snippet
+	"%s:%d: %s goes before the first block or in a Host block": "%s:%d: %s se place avant le premier bloc ou dans un bloc Host",
+
+	// Prompt segmentation in the scrollback viewer
+	"[Command %d/%d: %s] ":                       "[Commande %d/%d : %s] ",
+	"No shell prompts found (see PromptPattern)": "Aucune invite du shell trouvée (voir PromptPattern)",
+	"Copy failed (%s): %v":                       "Échec de la copie (%s) : %v",
+	"Copied %d lines of output of %q with %s":    "%d lignes de sortie de %q copiées avec %s",
+	"    ... %d lines of output (c expands)":     "    ... %d lignes de sortie (c les déplie)",
+	"the terminal (OSC 52)":                      "le terminal (OSC 52)",
+	"%s:%d: invalid PromptPattern: %v":           "%s:%d: PromptPattern invalide : %v",
+
+	// Latency sort
+	"proxy command":          "commande proxy",
+	" [probing...]":          " [sondage...]",
+	" [%v via %s]":           " [%v via %s]",
+	" [? jump host %s down]": " [? rebond %s hors ligne]",
+	" [DOWN]":                " [HORS LIGNE]",
+	"Connections (by latency, L restores the order):":                 "Connexions (par latence, L rétablit l'ordre) :",
+	"  L         - Toggle sorting by latency, unreachable hosts last": "  L         - Trier par latence, hôtes injoignables en dernier",
+
+	// Read-only mode
+	"  --read-only        Only look at sessions, scrollback and health; no connects": "  --read-only        Seulement consulter sessions, historique et santé ; aucune connexion",
+	"--read-only cannot connect to a host or run a command":                          "--read-only ne peut pas se connecter à un hôte ni lancer une commande",
+	"Disabled in read-only mode. Press Enter...":                                     "Désactivé en mode lecture seule. Appuyez sur Entrée...",
+	"READ-ONLY: viewing only, connecting and attaching are disabled":                 "LECTURE SEULE : consultation uniquement, connexion et rattachement désactivés",
+
+	// State export and import
+	"       sshtui export-state [FILE] | import-state FILE": "       sshtui export-state [FICHIER] | import-state FICHIER",
+	"Imported sessions could not be read: %v":               "Les sessions importées n'ont pas pu être lues : %v",
+	" (imported)": " (importée)",
+	"%d sessions imported from another machine, u[number] reopens them":                           "%d sessions importées d'une autre machine, u[numéro] les rouvre",
+	"Imported (u[number] reopens, scrollback archived):":                                          "Importées (u[numéro] rouvre, historique archivé) :",
+	"%s is not a host here. Press Enter...":                                                       "%s n'est pas un hôte ici. Appuyez sur Entrée...",
+	"Export sessions, scrollback, history and config to [%s]: ":                                   "Exporter sessions, historique de sortie, historique et config vers [%s] : ",
+	"%d sessions exported to %s; on the other machine run sshtui import-state %s. Press Enter...": "%d sessions exportées vers %s ; sur l'autre machine lancez sshtui import-state %s. Appuyez sur Entrée...",
+	"Usage: sshtui %s (see --help)\n":                                                             "Usage : sshtui %s (voir --help)\n",
+	"History, config and time log exported to %s\n":                                               "Historique, config et journal de temps exportés vers %s\n",
+	"Sessions and their scrollback are exported from the menu, with E":                            "Les sessions et leur historique s'exportent depuis le menu, avec E",
+	"  imported %s\n": "  importé %s\n",
+	"Nothing to import: not an sshtui state export":                                           "Rien à importer : pas un export d'état sshtui",
+	"Replaced files were kept with a .before-import suffix; sessions are offered in the menu": "Les fichiers remplacés sont gardés avec le suffixe .before-import ; les sessions sont proposées dans le menu",
+	"  E         - Export sessions, scrollback and state for another machine":                 "  E         - Exporter sessions, historique et état pour une autre machine",
+
+	// Host dashboard
+	"%s:%d: %s must be yes, sessions or no": "%s:%d: %s doit valoir yes, sessions ou no",
+	"%s:%d: %s must be at least %v":         "%s:%d: %s doit être d'au moins %v",
+	" [no stats]":                           " [pas de stats]",
+	"load ":                                 "charge ",
+	"%dd":                                   "%dj",
+	"up ":                                   "actif depuis ",
+	"%s full":                               "%s plein",
+
+	// Crash recovery
+	"A previous sshtui did not exit cleanly; its ssh processes still run:": "Un sshtui précédent ne s'est pas arrêté proprement ; ses processus ssh tournent encore :",
+	"  [%d] %s, pid %d, started %s%s\n":                                    "  [%d] %s, pid %d, démarré %s%s\n",
+	"      recording: %s\n":                                                "      enregistrement : %s\n",
+	"      debug log: %s\n":                                                "      journal de débogage : %s\n",
+	"%d ssh processes left by a crashed sshtui":                            "%d processus ssh laissés par un sshtui planté",
+	"End them? [k]ill, Enter leaves them running: ":                        "Les arrêter ? [k] pour tuer, Entrée les laisse tourner : ",
+	"  %s (pid %d): %v\n":                                                  "  %s (pid %d) : %v\n",
+
+	// Session order
+	"  k [!n]    - Move session up (again without a number)":          "  k [!n]    - Monter la session (encore, sans numéro)",
+	"  j [!n]    - Move session down (again without a number)":        "  j [!n]    - Descendre la session (encore, sans numéro)",
+	"  m !n !to  - Move session to a number; the order is remembered": "  m !n !à   - Placer la session à un numéro ; l'ordre est retenu",
+	" <- moving":               " <- déplacée",
+	"no session !%d":           "pas de session !%d",
+	"not a session number: %s": "pas un numéro de session : %s",
+	"usage: %s [number]":       "usage : %s [numéro]",
+	"usage: m FROM TO":         "usage : m DE À",
+
+	// ProxyCommand display
+	"Jump hosts:   %s\n":              "Rebonds :      %s\n",
+	"Proxy:        %s\n":              "Proxy :        %s\n",
+	"              (%s as written)\n": "               (%s tel qu'écrit)\n",
+
+	// TOTP
+	"%s:%d: %s must be show or type":                     "%s:%d: %s doit valoir show ou type",
+	", typed at the prompt":                              ", tapé à l'invite",
+	"TOTP code: %s (%v left)":                            "Code TOTP : %s (encore %v)",
+	"TOTP for %s: %v":                                    "TOTP pour %s : %v",
+	"TOTPSecret command failed: %v %s":                   "échec de la commande TOTPSecret : %v %s",
+	"the TOTP secret is not base32 or an otpauth:// URI": "le secret TOTP n'est ni en base32 ni une URI otpauth://",
+	"unsupported TOTP algorithm %s":                      "algorithme TOTP non pris en charge : %s",
+	"TOTP: %v":                                           "TOTP : %v",
+
+	// Setting sources
+	"\nssh -G failed: %v\n":                             "\nssh -G a échoué : %v\n",
+	"\nSSH settings (%s, %s, %s; defaults left out):\n": "\nRéglages SSH (%s, %s, %s ; valeurs par défaut omises) :\n",
+	"host block":                       "bloc de l'hôte",
+	"shared":                           "partagé",
+	"shared: patterns, Match, Include": "partagé : motifs, Match, Include",
+	"added by sshtui":                  "ajouté par sshtui",
+
+	// Managed hosts
+	"%s already exists":               "%s existe déjà",
+	"%s is not one of sshtui's hosts": "%s n'est pas un des hôtes de sshtui",
+	"ssh does not know sshtui's hosts: add \"Include %s\" before the first Host of ~/.ssh/config": "ssh ne connaît pas les hôtes de sshtui : ajoutez \"Include %s\" avant le premier Host de ~/.ssh/config",
+	"Adding hosts needs \"ManagedHosts yes\" in the sshtui config. Press Enter...":                "Ajouter des hôtes demande \"ManagedHosts yes\" dans la config sshtui. Appuyez sur Entrée...",
+	"\nAdd a host (Enter leaves a setting out)":                                                   "\nAjouter un hôte (Entrée omet un réglage)",
+	"Alias: ":                    "Alias : ",
+	"HostName [alias]: ":         "HostName [alias] : ",
+	"Invalid %s. Press Enter...": "%s invalide. Appuyez sur Entrée...",
+	"%s added. Press Enter...":   "%s ajouté. Appuyez sur Entrée...",
+	"%s is not one of sshtui's hosts (it comes from %s). Press Enter...": "%s n'est pas un des hôtes de sshtui (il vient de %s). Appuyez sur Entrée...",
+	"Delete %s from sshtui's hosts? [y/N]: ":                             "Supprimer %s des hôtes de sshtui ? [y/N] : ",
+	"%s deleted. Press Enter...":                                         "%s supprimé. Appuyez sur Entrée...",
+	"sshtui's hosts":                                                     "les hôtes de sshtui",
+	"  D[number] - Delete one of sshtui's hosts":                         "  D[numéro] - Supprimer un des hôtes de sshtui",
+	"  A         - Add a host to sshtui's hosts":                         "  A         - Ajouter un hôte aux hôtes de sshtui",
+
+	// ssh client probing
+	"ssh -V failed (%v): connections will fail until ssh is on PATH": "ssh -V a échoué (%v) : les connexions échoueront tant que ssh n'est pas dans le PATH",
+	"%s lacks %s": "%s ne gère pas %s",
+	"host details show sshtui's own reading of ~/.ssh/config, not what ssh resolves": "les détails d'hôte montrent la lecture de ~/.ssh/config par sshtui, pas ce que ssh résout",
+	"a single jump host goes through ProxyCommand ssh -W; chains of jump hosts fail": "un seul hôte de rebond passe par ProxyCommand ssh -W ; les chaînes d'hôtes de rebond échouent",
+	"sshtui's hosts are reached by hostname, user and port only":                     "les hôtes de sshtui sont joints par nom d'hôte, utilisateur et port seulement",
+	"connection sharing (M) is off":                                                  "le partage de connexion (M) est désactivé",
+	"  unavailable: %s lacks %s or %s\n":                                             "  indisponible : %s ne gère pas %s ou %s\n",
+	"ssh: %v\n":                                                                      "ssh : %v\n",
+	"ssh: %s\n":                                                                      "ssh : %s\n",
+
+	// Root shells
+	"ROOT":                            "ROOT",
+	" ROOT: this shell runs as root ": " ROOT : ce shell tourne en root ",
+
+	// Local pseudo-host
+	" (this machine, without ssh)": " (cette machine, sans ssh)",
+
+	// Host notes
+	"\ne edits the notes, Enter returns: ": "\ne modifie les notes, Entrée pour revenir : ",
+	"\nNotes:":                             "\nNotes :",
+	"editor %s: %v":                        "éditeur %s : %v",
+
+	// Connect commands
+	"Connect for %s: no value for %s": "Connect de %s : aucune valeur pour %s",
+	"Connect:      %s\n":              "Connect :      %s\n",
+
+	// AWS SSM
+	"scripts cannot be uploaded to %s hosts, run a command": "impossible d'envoyer un script aux hôtes %s, lancez une commande",
+
+	// Teleport
+	"%s on %s, certificate expired on %s":                                    "%s sur %s, certificat expiré le %s",
+	"%s on %s, certificate valid until %s (%s left)":                         "%s sur %s, certificat valide jusqu'au %s (encore %s)",
+	"%s: not logged in, run tsh login then r":                                "%s : non connecté, lancez tsh login puis r",
+	"%s: tsh is not on PATH":                                                 "%s : tsh n'est pas dans le PATH",
+	"Log in with tsh login? [Y/n]: ":                                         "Se connecter avec tsh login ? [Y/n] : ",
+	"Teleport certificate expired on %s: connecting to a node logs in again": "Certificat Teleport expiré le %s : se connecter à un nœud relance la connexion",
+	"Teleport certificate expires at %s":                                     "Le certificat Teleport expire à %s",
+	"Teleport:     %s\n":                                                     "Teleport :     %s\n",
+	"\nNot logged in to Teleport.":                                           "\nNon connecté à Teleport.",
+	"\nTeleport certificate expired on %s.":                                  "\nCertificat Teleport expiré le %s.",
+	"\nTeleport certificate expires at %s.":                                  "\nLe certificat Teleport expire à %s.",
+	"not logged in":                                                          "non connecté",
+
+	// Hardware keys
+	"  %s: touch your security key":             "  %s : touchez votre clé de sécurité",
+	" <- touch your security key":               " <- touchez votre clé de sécurité",
+	"%s waits for a touch of your security key": "%s attend que vous touchiez votre clé de sécurité",
+
+	// Host import
+	"  I         - Import hosts from pasted text (user@host, host:port, CSV)":                     "  I         - Importer des hôtes d'un texte collé (user@hôte, hôte:port, CSV)",
+	"%d hosts added until the config is reloaded. Press Enter...":                                 "%d hôtes ajoutés jusqu'au rechargement de la config. Entrée pour continuer...",
+	"%d hosts written to %s. Press Enter...":                                                      "%d hôtes écrits dans %s. Entrée pour continuer...",
+	"An empty line ends the list.":                                                                "Une ligne vide termine la liste.",
+	"\"alias hostname\", or CSV (alias,hostname,user,port, or with a header).":                    "\"alias hôte\", ou CSV (alias,hôte,utilisateur,port, ou avec un en-tête).",
+	"\nNothing to import. Press Enter...":                                                         "\nRien à importer. Entrée pour continuer...",
+	"\nPaste hosts, one per line: user@host, host:port, ssh command lines,":                       "\nCollez les hôtes, un par ligne : user@hôte, hôte:port, lignes de commande ssh,",
+	"\nWrite %d hosts to %s? y writes, n adds them until the config is reloaded, Enter cancels: ": "\nÉcrire %d hôtes dans %s ? y écrit, n les ajoute jusqu'au rechargement de la config, Entrée annule : ",
+	"alias %s already used by line %d":                                                            "alias %s déjà pris par la ligne %d",
+	"alias %s is taken by %s":                                                                     "alias %s déjà pris par %s",
+	"already in the list as %s":                                                                   "déjà dans la liste sous le nom %s",
+	"duplicate of line %d":                                                                        "doublon de la ligne %d",
+	"invalid alias %q":                                                                            "alias invalide %q",
+	"invalid hostname %q":                                                                         "nom d'hôte invalide %q",
+	"invalid port %q":                                                                             "port invalide %q",
+	"invalid user %q":                                                                             "utilisateur invalide %q",
+	"line %d: %s":                                                                                 "ligne %d : %s",
+	"no hostname":                                                                                 "pas de nom d'hôte",
+
+	// encoding.go
+	"encoding %s needs luit, which is not on PATH": "l'encodage %s nécessite luit, absent du PATH",
+	"%s, converted by luit":                        "%s, converti par luit",
+	"Encoding:     %s\n":                           "Encodage :     %s\n",
+
+	// promptrelay.go
+	"  P         - Answer the auth prompts of detached sessions, one by one": "  P         - Répondre aux invites d'authentification des sessions détachées, une par une",
+	"Sessions waiting at a prompt: %d, P answers them one by one":            "Sessions en attente d'une invite : %d, P y répond une par une",
+	"\nSessions waiting at a prompt: %d. Answer them now? [Y/n]: ":           "\nSessions en attente d'une invite : %d. Y répondre maintenant ? [Y/n] : ",
+	"Auth prompts": "Invites d'authentification",
+	"Enter on an empty answer skips a host, Esc leaves": "Entrée sur une réponse vide passe un hôte, Échap quitte",
+	"%s answer: ":                                    "réponse pour %s : ",
+	"Waiting for %s...\n":                            "En attente de %s...\n",
+	"\nNo more prompts. Press Enter...":              "\nPlus d'invite. Appuyez sur Entrée...",
+	"\nNo session waits at a prompt. Press Enter...": "\nAucune session n'attend à une invite. Appuyez sur Entrée...",
+
+	// healthwatch.go
+	"%s is down since %s: %s":                    "%s est injoignable depuis %s : %s",
+	"unreachable":                                "injoignable",
+	"%s is back up after %v down":                "%s répond de nouveau après %v d'indisponibilité",
+	"HealthHook for %s failed: %v":               "HealthHook pour %s a échoué : %v",
+	"HealthWebhook for %s failed: %v":            "HealthWebhook pour %s a échoué : %v",
+	"invalid URL":                                "URL invalide",
+	"HealthWebhook must be an http or https URL": "HealthWebhook doit être une URL http ou https",
+
+	// Scrollback history
+	"%s older output in %s":                 "%s de sortie plus ancienne dans %s",
+	"invalid size %q, use e.g. 512K or 64M": "taille invalide %q, par exemple 512K ou 64M",
+
+	// Multi-host results pager
+	" (%d lines)": " (%d lignes)",
+	"Command: %s": "Commande : %s",
+	"j/k hosts, c folds, / searches, e exports, q returns": "j/k hôtes, c replie, / cherche, e exporte, q revient",
+	"%d of %d hosts failed, r runs them again":             "%d hôtes sur %d en échec, r les relance",
+	"[Host %d/%d: %s] ": "[Hôte %d/%d : %s] ",
+	"No host #%s":       "Pas d'hôte n°%s",
+	"No host failed":    "Aucun hôte en échec",
+	"Export failed: %v": "Échec de l'export : %v",
+	"Exported to %s":    "Exporté dans %s",
+
+	// Multi-host selection
+	"\nHosts: %s\n": "\nHôtes : %s\n",
+
+	// Forward screen
+	"Not a forward session. Press Enter...": "Pas une session de redirection. Appuyez sur Entrée...",
+}
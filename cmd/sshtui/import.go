@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// importSource marks hosts imported for the session only, until the
+// config is reloaded; ssh does not know their aliases
+const importSource = "import"
+
+// ImportEntry is a host read from one pasted line
+type ImportEntry struct {
+	Line     int // 1-based, in the pasted text
+	Alias    string
+	HostName string
+	User     string
+	Port     string
+	Problem  string // why it is left out, "" when it is imported
+}
+
+// importColumns names the CSV header columns the wizard understands
+var importColumns = map[string]string{
+	"alias": "alias", "name": "alias", "host alias": "alias",
+	"host": "hostname", "hostname": "hostname", "ip": "hostname", "address": "hostname",
+	"fqdn": "hostname", "server": "hostname",
+	"user": "user", "username": "user", "login": "user",
+	"port": "port",
+}
+
+// sshArgFlags are the ssh options taking an argument, skipped in pasted
+// ssh command lines along with it
+const sshArgFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+
+var (
+	importHostName = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+	importUser     = regexp.MustCompile(`^[A-Za-z0-9._][A-Za-z0-9._-]*$`)
+)
+
+// parseImport reads pasted lines: user@host, host:port, ssh command lines,
+// "alias hostname" pairs, or CSV (commas, semicolons or tabs) with or
+// without a header naming the columns. Blank lines and # comments are
+// skipped
+func parseImport(lines []string) []ImportEntry {
+	entries := []ImportEntry{}
+	var columns []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry := ImportEntry{Line: i + 1}
+		if sep := strings.IndexAny(line, ",;\t"); sep >= 0 {
+			fields := strings.Split(line, string(line[sep]))
+			for j := range fields {
+				fields[j] = strings.Trim(strings.TrimSpace(fields[j]), `"'`)
+			}
+			if header := importHeader(fields); header != nil {
+				columns = header
+				continue
+			}
+			if columns != nil {
+				entry.fromColumns(columns, fields)
+			} else {
+				entry.fromFields(fields)
+			}
+		} else {
+			entry.fromFields(sshWords(strings.Fields(line), &entry))
+		}
+		entries = append(entries, entry.validate())
+	}
+	return entries
+}
+
+// importHeader returns what each field of a CSV header line stands for,
+// or nil when the line is data
+func importHeader(fields []string) []string {
+	columns := make([]string, len(fields))
+	known := false
+	for i, field := range fields {
+		columns[i] = importColumns[strings.ToLower(field)]
+		known = known || columns[i] == "hostname"
+	}
+	if !known {
+		return nil
+	}
+	return columns
+}
+
+// fromColumns fills the entry from CSV fields under a header
+func (e *ImportEntry) fromColumns(columns, fields []string) {
+	for i, field := range fields {
+		if i >= len(columns) || field == "" {
+			continue
+		}
+		switch columns[i] {
+		case "alias":
+			e.Alias = field
+		case "hostname":
+			e.User, e.HostName, e.Port = mergeTarget(e.User, e.Port, field)
+		case "user":
+			e.User = field
+		case "port":
+			e.Port = field
+		}
+	}
+}
+
+// fromFields fills the entry from fields nothing names: a port is a
+// number, a target has an @; of the other words, the hostname is the
+// first with a dot (an IP or a domain name), the alias a word before it
+// and the user one after it
+func (e *ImportEntry) fromFields(fields []string) {
+	names := []string{}
+	for _, field := range fields {
+		switch {
+		case field == "":
+		case isPort(field):
+			e.Port = field
+		case strings.Contains(field, "@") || strings.Contains(field, "://"):
+			e.User, e.HostName, e.Port = mergeTarget(e.User, e.Port, field)
+		default:
+			names = append(names, field)
+		}
+	}
+	if e.HostName != "" {
+		if len(names) > 0 {
+			e.Alias = names[0]
+		}
+		return
+	}
+
+	host := 0
+	for i, name := range names {
+		if strings.Contains(name, ".") || strings.Contains(name, ":") {
+			host = i
+			break
+		}
+		if i == 1 {
+			// "alias hostname" when neither looks like an address
+			host = 1
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	e.User, e.HostName, e.Port = mergeTarget(e.User, e.Port, names[host])
+	if host > 0 {
+		e.Alias = names[host-1]
+	}
+	if host+1 < len(names) && e.User == "" {
+		e.User = names[host+1]
+	}
+}
+
+// sshWords drops a leading ssh and its options from pasted words, taking
+// the user of -l and the port of -p into the entry
+func sshWords(words []string, e *ImportEntry) []string {
+	if len(words) == 0 || words[0] != "ssh" {
+		return words
+	}
+	rest := []string{}
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		if len(word) < 2 || word[0] != '-' {
+			rest = append(rest, word)
+			continue
+		}
+		flag, value := word[1], word[2:]
+		if !strings.ContainsRune(sshArgFlags, rune(flag)) {
+			continue
+		}
+		if value == "" && i+1 < len(words) {
+			i++
+			value = words[i]
+		}
+		switch flag {
+		case 'l':
+			e.User = value
+		case 'p':
+			e.Port = value
+		}
+	}
+	return rest
+}
+
+// mergeTarget reads [ssh://][user@]host[:port], keeping the user and port
+// already known when the target has none
+func mergeTarget(user, port, target string) (string, string, string) {
+	targetUser, hostName, targetPort := parseTarget(strings.TrimSuffix(strings.TrimPrefix(target, "ssh://"), "/"))
+	return valueOr(targetUser, user), strings.Trim(hostName, "[]"), valueOr(targetPort, port)
+}
+
+// isPort tells whether s is a TCP port number
+func isPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n < 65536
+}
+
+// importAlias is the alias of a host pasted without one: the first label
+// of its name, or the whole address
+func importAlias(hostName string) string {
+	if net.ParseIP(hostName) != nil {
+		return hostName
+	}
+	return strings.SplitN(hostName, ".", 2)[0]
+}
+
+// validate fills the alias of an entry that has none and says what is
+// wrong with it, if anything
+func (e ImportEntry) validate() ImportEntry {
+	switch {
+	case e.HostName == "":
+		e.Problem = tr("no hostname")
+	case net.ParseIP(e.HostName) == nil && !importHostName.MatchString(e.HostName):
+		e.Problem = fmt.Sprintf(tr("invalid hostname %q"), e.HostName)
+	case e.Port != "" && !isPort(e.Port):
+		e.Problem = fmt.Sprintf(tr("invalid port %q"), e.Port)
+	case e.User != "" && !importUser.MatchString(e.User):
+		e.Problem = fmt.Sprintf(tr("invalid user %q"), e.User)
+	}
+	if e.Alias == "" {
+		e.Alias = importAlias(e.HostName)
+	}
+	if e.Problem == "" && strings.ContainsAny(e.Alias, " \t*?") {
+		e.Problem = fmt.Sprintf(tr("invalid alias %q"), e.Alias)
+	}
+	return e
+}
+
+// target is the entry's [user@]hostname[:port]
+func (e ImportEntry) target() string {
+	target := e.HostName
+	if e.User != "" {
+		target = e.User + "@" + target
+	}
+	if e.Port != "" {
+		target += ":" + e.Port
+	}
+	return target
+}
+
+// dedupImport leaves out entries repeating an earlier line or a host of
+// the list, and those whose alias another host already has. A line
+// reaching the target of an earlier one repeats it, whatever its alias
+func dedupImport(entries []ImportEntry, hosts []SSHHost) []ImportEntry {
+	seen := make(map[string]ImportEntry)    // alias -> first entry
+	targets := make(map[string]ImportEntry) // target -> first entry
+	for i, e := range entries {
+		if e.Problem != "" {
+			continue
+		}
+		if first, ok := seen[e.Alias]; ok {
+			if first.target() == e.target() {
+				entries[i].Problem = fmt.Sprintf(tr("duplicate of line %d"), first.Line)
+			} else {
+				entries[i].Problem = fmt.Sprintf(tr("alias %s already used by line %d"), e.Alias, first.Line)
+			}
+			continue
+		}
+		if first, ok := targets[e.target()]; ok {
+			entries[i].Problem = fmt.Sprintf(tr("duplicate of line %d"), first.Line)
+			continue
+		}
+		for _, host := range hosts {
+			// A host whose alias is its name has no HostName
+			sameTarget := valueOr(host.HostName, host.Alias) == e.HostName && (e.User == "" || effectiveUser(host) == e.User) && valueOr(host.Port, "22") == valueOr(e.Port, "22")
+			switch {
+			case sameTarget:
+				entries[i].Problem = fmt.Sprintf(tr("already in the list as %s"), host.Alias)
+			case host.Alias == e.Alias:
+				entries[i].Problem = fmt.Sprintf(tr("alias %s is taken by %s"), e.Alias, valueOr(host.HostName, host.Alias))
+			default:
+				continue
+			}
+			break
+		}
+		if entries[i].Problem == "" {
+			seen[e.Alias] = e
+			targets[e.target()] = e
+		}
+	}
+	return entries
+}
+
+// importBlock renders an entry as an ssh config Host block
+func importBlock(e ImportEntry) []string {
+	block := []string{"Host " + e.Alias, "    HostName " + e.HostName}
+	if e.User != "" {
+		block = append(block, "    User "+e.User)
+	}
+	if e.Port != "" {
+		block = append(block, "    Port "+e.Port)
+	}
+	return block
+}
+
+// importHosts reads pasted hosts until an empty line, shows what it makes
+// of them and adds the valid ones to ~/.ssh/config, sshtui's hosts with
+// ManagedHosts, or the session only; it returns the updated host list
+func importHosts(hosts []SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println(tr("\nPaste hosts, one per line: user@host, host:port, ssh command lines,"))
+	fmt.Println(tr("\"alias hostname\", or CSV (alias,hostname,user,port, or with a header)."))
+	fmt.Println(tr("An empty line ends the list."))
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.TrimSpace(line) == "" || err != nil {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	entries := dedupImport(parseImport(lines), hosts)
+	valid := []ImportEntry{}
+	fmt.Println()
+	for _, e := range entries {
+		if e.Problem != "" {
+			fmt.Printf("  %s %s\n", failMark(), fmt.Sprintf(tr("line %d: %s"), e.Line, e.Problem))
+			continue
+		}
+		fmt.Printf("  %s %-20s %s\n", okMark(), e.Alias, e.target())
+		valid = append(valid, e)
+	}
+	if len(valid) == 0 {
+		fmt.Print(tr("\nNothing to import. Press Enter..."))
+		reader.ReadString('\n')
+		return hosts
+	}
+
+	// With ManagedHosts, sshtui keeps the hosts rather than ~/.ssh/config
+	where, save := "~/.ssh/config", appendImportSSHConfig
+	if appConfig.ManagedHosts {
+		where, save = tr("sshtui's hosts"), saveManagedImport
+	}
+	fmt.Printf(tr("\nWrite %d hosts to %s? y writes, n adds them until the config is reloaded, Enter cancels: "), len(valid), where)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+	case "n", "no":
+		imported := []SSHHost{}
+		for _, e := range valid {
+			imported = append(imported, SSHHost{Alias: e.Alias, HostName: e.HostName, User: e.User, Port: e.Port, Source: importSource})
+		}
+		imported = applyHostSettings(imported, appConfig)
+		for i := range imported {
+			// Hotkeys stay with the hosts of the config
+			imported[i].Hotkey = ""
+		}
+		fmt.Printf(tr("%d hosts added until the config is reloaded. Press Enter..."), len(imported))
+		reader.ReadString('\n')
+		return append(hosts, imported...)
+	default:
+		return hosts
+	}
+
+	if err := save(valid); err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return hosts
+	}
+	newHosts, err := loadHosts(appConfig, false)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return hosts
+	}
+	fmt.Printf(tr("%d hosts written to %s. Press Enter..."), len(valid), where)
+	reader.ReadString('\n')
+	return newHosts
+}
+
+// appendImportSSHConfig appends the entries' Host blocks to ~/.ssh/config
+func appendImportSSHConfig(entries []ImportEntry) error {
+	blocks := [][]string{}
+	for _, e := range entries {
+		blocks = append(blocks, importBlock(e))
+	}
+	return appendSSHConfig(blocks...)
+}
+
+// saveManagedImport adds the entries to the managed hosts
+func saveManagedImport(entries []ImportEntry) error {
+	managed := []ManagedHost{}
+	for _, e := range entries {
+		managed = append(managed, ManagedHost{Alias: e.Alias, Settings: trimBlock(importBlock(e))})
+	}
+	return addManagedHosts(managed...)
+}
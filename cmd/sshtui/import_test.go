@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestDedupImport(t *testing.T) {
+	hosts := []SSHHost{
+		{Alias: "server1.example.com"},
+		{Alias: "db", HostName: "10.0.0.9"},
+	}
+	entries := dedupImport(parseImport([]string{
+		"server1.example.com",
+		"web 10.0.0.1",
+		"cache 10.0.0.1",
+		"10.0.0.9",
+		"app 10.0.0.2",
+	}), hosts)
+
+	want := []string{
+		"already in the list as server1.example.com",
+		"",
+		"duplicate of line 2",
+		"already in the list as db",
+		"",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Problem != want[i] {
+			t.Errorf("line %d (%s): problem %q, want %q", e.Line, e.target(), e.Problem, want[i])
+		}
+	}
+}
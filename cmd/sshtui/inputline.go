@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MenuRefreshInterval is how often the menu gets an EventTimer
+// to redraw while waiting for input
+const MenuRefreshInterval = time.Second
+
+// Line editing keys
+const (
+	keyLineStart = 0x01 // Ctrl+A
+	keyEOF       = 0x04 // Ctrl+D
+	keyLineEnd   = 0x05 // Ctrl+E
+	keyBackspace = 0x08
+	keyKillLine  = 0x15 // Ctrl+U
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// InputLine is a line of text being typed, kept apart from the screen so
+// a redraw of the view above it can draw it again
+type InputLine struct {
+	Prompt string
+	text   []rune
+	cursor int // rune position of the cursor in text
+}
+
+// Draw redraws the prompt and text on the current terminal line and puts
+// the cursor back in place
+func (l *InputLine) Draw() {
+	fmt.Print("\r\033[K" + l.Prompt + string(l.text))
+	if back := len(l.text) - l.cursor; back > 0 {
+		fmt.Printf("\033[%dD", back)
+	}
+}
+
+func (l *InputLine) insert(r rune) {
+	l.text = append(l.text[:l.cursor], append([]rune{r}, l.text[l.cursor:]...)...)
+	l.cursor++
+}
+
+// feed applies one key sequence; done is true once Enter was pressed
+func (l *InputLine) feed(seq []byte) (done bool) {
+	switch string(seq) {
+	case "\r", "\n":
+		return true
+	case "\x1b[D", "\x1bOD":
+		l.cursor = max(l.cursor-1, 0)
+	case "\x1b[C", "\x1bOC":
+		l.cursor = min(l.cursor+1, len(l.text))
+	case "\x1b[H", "\x1bOH", "\x1b[1~", string(rune(keyLineStart)):
+		l.cursor = 0
+	case "\x1b[F", "\x1bOF", "\x1b[4~", string(rune(keyLineEnd)):
+		l.cursor = len(l.text)
+	case "\x1b[3~":
+		if l.cursor < len(l.text) {
+			l.text = append(l.text[:l.cursor], l.text[l.cursor+1:]...)
+		}
+	case string(rune(keyDelete)), string(rune(keyBackspace)):
+		if l.cursor > 0 {
+			l.text = append(l.text[:l.cursor-1], l.text[l.cursor:]...)
+			l.cursor--
+		}
+	case string(rune(keyKillLine)):
+		l.text = l.text[l.cursor:]
+		l.cursor = 0
+	default:
+		if r, _ := utf8.DecodeRune(seq); r >= 0x20 && r != utf8.RuneError {
+			l.insert(r)
+		}
+	}
+	return false
+}
+
+// nextKey splits the first key sequence off buf: an escape sequence, a
+// whole UTF-8 character or a single byte. ok is false while buf ends in
+// the middle of one
+func nextKey(buf []byte) (seq []byte, ok bool) {
+	if buf[0] == keyEscape {
+		if len(buf) < 2 {
+			return nil, false
+		}
+		if buf[1] != '[' && buf[1] != 'O' {
+			return buf[:2], true
+		}
+		// CSI or SS3: parameters up to a final letter or ~
+		for i := 2; i < len(buf); i++ {
+			if buf[i] >= 0x40 && buf[i] <= 0x7e {
+				return buf[:i+1], true
+			}
+		}
+		return nil, false
+	}
+	if buf[0] >= utf8.RuneSelf && !utf8.FullRune(buf) {
+		return nil, false
+	}
+	_, size := utf8.DecodeRune(buf)
+	return buf[:size], true
+}
+
+// readMenuInput reads a line at the menu prompt with line editing, while
+// redraw repaints the menu and the typed text for each receive on
+// redraws. A key for which instant is true, typed on an empty line, is
+// returned at once, function keys by name (F1). Plain mode and
+// non-terminal input read a plain line
+func readMenuInput(prompt string, redraw func(), redraws <-chan struct{}, instant func(key string) bool) (string, error) {
+	if plainMode {
+		return bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+	restoreTerm, err := term.MakeCbreak()
+	if err != nil {
+		return bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+	defer restoreTerm()
+
+	var mu sync.Mutex
+	line := &InputLine{Prompt: prompt}
+	line.Draw()
+
+	done := make(chan bool)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-redraws:
+			case <-done:
+				return
+			}
+			mu.Lock()
+			redraw()
+			line.Draw()
+			mu.Unlock()
+		}
+	}()
+
+	buf := []byte{}
+	chunk := make([]byte, 64)
+	for {
+		n, err := term.Read(chunk)
+		if err != nil {
+			return "", err
+		}
+		buf = append(buf, chunk[:n]...)
+
+		mu.Lock()
+		for len(buf) > 0 {
+			if buf[0] == keyEOF && len(line.text) == 0 {
+				mu.Unlock()
+				fmt.Println()
+				return "", io.EOF
+			}
+			seq, ok := nextKey(buf)
+			if !ok {
+				break
+			}
+			buf = buf[len(seq):]
+			if len(line.text) == 0 {
+				key := valueOr(functionKeys[string(seq)], string(seq))
+				if instant(key) {
+					mu.Unlock()
+					fmt.Println(key)
+					return key + "\n", nil
+				}
+			}
+			if line.feed(seq) {
+				mu.Unlock()
+				fmt.Println()
+				return strings.TrimSpace(string(line.text)) + "\n", nil
+			}
+		}
+		line.Draw()
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// StatsKey (Ctrl+^) toggles the I/O status line while attached;
+	// pressed twice it reaches the session, for vim's alternate file
+	StatsKey = 0x1e
+	// StatsInterval is how often the status line's rate is measured
+	StatsInterval = time.Second
+	// StatusLineWidth keeps the line from leaving stale characters behind
+	// when the numbers get shorter
+	StatusLineWidth = 44
+)
+
+// showIOStats is toggled with StatsKey and kept across attaches
+var showIOStats atomic.Bool
+
+// IOStats counts a session's traffic; Out is fed by the output pipeline,
+// In by the attached view
+type IOStats struct {
+	Out  atomic.Int64
+	In   atomic.Int64
+	Rate atomic.Int64 // output bytes over the last StatsInterval, while attached
+}
+
+// Write counts output; IOStats is the session's stats subscriber
+func (s *IOStats) Write(p []byte) (int, error) {
+	s.Out.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// String is the one-line summary shown in the status line and details
+func (s *IOStats) String() string {
+	return fmt.Sprintf(tr("out %s/s, %s total, in %s"),
+		formatBytes(s.Rate.Load()), formatBytes(s.Out.Load()), formatBytes(s.In.Load()))
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// trackIOStats measures the output rate and redraws the status line
+// every StatsInterval until done is closed
+func trackIOStats(session *Session, done chan bool) {
+	ticker := time.NewTicker(StatsInterval)
+	defer ticker.Stop()
+
+	last := session.Stats.Out.Load()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		out := session.Stats.Out.Load()
+		session.Stats.Rate.Store(out - last)
+		last = out
+		if showIOStats.Load() && !plainMode {
+			drawStatusLine(session, true)
+		}
+	}
+}
+
+// toggleIOStats shows or hides the status line; in plain mode it prints
+// the numbers once instead
+func toggleIOStats(session *Session) {
+	if plainMode {
+		session.Output.Hold(func() {
+			fmt.Printf("\r\n[sshtui] %s\r\n", session.Stats.String())
+		})
+		return
+	}
+	show := !showIOStats.Load()
+	showIOStats.Store(show)
+	drawStatusLine(session, show)
+}
+
+// drawStatusLine writes the stats, dimmed, at the right of the bottom
+// row, or blanks that spot; the cursor is saved and restored around it.
+// Publishing is held off so the line never lands inside session output
+func drawStatusLine(session *Session, show bool) {
+	ws, err := term.Size()
+	if err != nil || ws.Rows == 0 {
+		return
+	}
+
+	text := session.Stats.String() + " "
+	if pad := StatusLineWidth - utf8.RuneCountInString(text); pad > 0 {
+		text = strings.Repeat(" ", pad) + text
+	}
+	width := utf8.RuneCountInString(text)
+	col := max(int(ws.Cols)-width+1, 1)
+
+	session.Output.Hold(func() {
+		if show {
+			fmt.Printf("\0337\033[%d;%dH%s\0338", ws.Rows, col, colorize("2;7", text))
+		} else {
+			fmt.Printf("\0337\033[%d;%dH\033[K\0338", ws.Rows, col)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"slices"
+)
+
+// localAlias names the local machine in multi-host runs, unless a host
+// of that name is configured, which then wins
+const localAlias = "localhost"
+
+// localSource marks the local pseudo-host, run without ssh
+const localSource = "local"
+
+// localHost is the pseudo-host running commands on this machine
+func localHost() SSHHost {
+	return SSHHost{Alias: localAlias, Source: localSource}
+}
+
+// isLocal tells whether host is the local pseudo-host
+func isLocal(host SSHHost) bool {
+	return host.Source == localSource
+}
+
+// withLocalHost offers the local machine after hosts, for a run comparing
+// it with them
+func withLocalHost(hosts []SSHHost) []SSHHost {
+	if _, ok := findHost(hosts, localAlias); ok {
+		return hosts
+	}
+	return append(slices.Clip(hosts), localHost())
+}
+
+// findTarget finds a host of a multi-host run by alias, the local
+// pseudo-host included
+func findTarget(hosts []SSHHost, alias string) (SSHHost, bool) {
+	return findHost(withLocalHost(hosts), alias)
+}
+
+// prepareLocal returns the shell command running the job here: the
+// script from where it is, with its interpreter line when executable
+func (j MultiJob) prepareLocal() string {
+	if j.Script == "" {
+		return j.Command
+	}
+	script := shellQuote(j.Script)
+	if info, err := os.Stat(j.Script); err != nil || info.Mode()&0111 == 0 {
+		script = "sh " + script
+	}
+	return script + " " + j.Command
+}
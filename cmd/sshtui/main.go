@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const version = "0.0.3"
+
+func main() {
+	locale = detectLocale("")
+
+	// Handle CLI flags; a bare argument is a host to connect to at once,
+	// or the hosts (globs allowed) of --run and --test
+	pick, list, test := false, false, false
+	runCommand := ""
+	targets := []string{}
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--version", "-v":
+			fmt.Printf(tr("sshtui v%s\n"), version)
+			os.Exit(ExitOK)
+		case "--help", "-h":
+			fmt.Println(tr("sshtui - SSH session manager"))
+			fmt.Printf(tr("Version: %s\n\n"), version)
+			fmt.Println(tr("Usage: sshtui [options] [host]"))
+			fmt.Println(tr("       sshtui --run COMMAND [--quiet] host|glob..."))
+			fmt.Println(tr("       sshtui --test [--quiet] host|glob..."))
+			fmt.Println(tr("       sshtui version | self-update"))
+			fmt.Println(tr("       sshtui export-state [FILE] | import-state FILE"))
+			fmt.Println(tr("\nOptions:"))
+			fmt.Println(tr("  -v, --version      Show version"))
+			fmt.Println(tr("  -h, --help         Show help"))
+			fmt.Println(tr("  --debug-connect    Capture ssh -vvv logs and diagnose failed connects"))
+			fmt.Println(tr("  --plain            Screen-reader friendly output (no boxes, colors or clears)"))
+			fmt.Println(tr("  --read-only        Only look at sessions, scrollback and health; no connects"))
+			fmt.Println(tr("  --pick             Choose a host, print its alias and exit"))
+			fmt.Println(tr("  --list             Print all host aliases and exit"))
+			fmt.Println(tr("  --run COMMAND      Run COMMAND on the hosts, output prefixed by host"))
+			fmt.Println(tr("  --test             Test logging into the hosts (BatchMode)"))
+			fmt.Println(tr("  -q, --quiet        With --run or --test, print only failures"))
+			fmt.Println(tr("\nWith a host alias, connect to it before showing the menu."))
+			fmt.Println(tr("--run and --test exit with 0 when all hosts succeed, 1 when some fail,\n2 on a config or usage error."))
+			os.Exit(ExitOK)
+		case "--debug-connect":
+			debugConnect = true
+		case "--plain":
+			plainMode = true
+		case "--read-only":
+			readOnly = true
+		case "--pick":
+			pick = true
+		case "--list":
+			list = true
+		case "--test":
+			test = true
+		case "--quiet", "-q":
+			batchQuiet = true
+		case "--run":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, tr("--run needs a command (see --help)"))
+				os.Exit(ExitConfig)
+			}
+			i++
+			runCommand = args[i]
+		default:
+			if strings.HasPrefix(arg, "-") {
+				fmt.Fprintf(os.Stderr, tr("Unknown option: %s (see --help)\n"), arg)
+				os.Exit(ExitConfig)
+			}
+			targets = append(targets, arg)
+		}
+	}
+
+	batch := test || runCommand != ""
+	if !batch && len(targets) > 0 && (targets[0] == "export-state" || targets[0] == "import-state") {
+		// Moving the state to another machine needs no config either
+		os.Exit(stateCommand(targets[0], targets[1:]))
+	}
+	switch {
+	case test && runCommand != "":
+		fmt.Fprintln(os.Stderr, tr("--run and --test cannot be combined"))
+		os.Exit(ExitConfig)
+	case batch && len(targets) == 0:
+		fmt.Fprintln(os.Stderr, tr("No hosts given (see --help)"))
+		os.Exit(ExitConfig)
+	case !batch && len(targets) > 1:
+		fmt.Fprintln(os.Stderr, tr("Only one host can be connected to at once (see --help)"))
+		os.Exit(ExitConfig)
+	case readOnly && (runCommand != "" || (!batch && len(targets) > 0)):
+		fmt.Fprintln(os.Stderr, tr("--read-only cannot connect to a host or run a command"))
+		os.Exit(ExitConfig)
+	}
+
+	// Subcommands, which work even when the config does not parse
+	if !batch && len(targets) == 1 {
+		switch targets[0] {
+		case "version":
+			printVersion()
+			os.Exit(ExitOK)
+		case "self-update":
+			if err := selfUpdate(); err != nil {
+				fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+				os.Exit(ExitFailures)
+			}
+			os.Exit(ExitOK)
+		}
+	}
+
+	// Parse sshtui config
+	var err error
+	appConfig, err = parseAppConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		os.Exit(ExitConfig)
+	}
+	locale = detectLocale(appConfig.Locale)
+	sshClient = probeSSHClient()
+
+	// Load hosts from SSH config and the configured providers
+	hosts, err := loadHosts(appConfig, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		os.Exit(ExitConfig)
+	}
+
+	if list {
+		for _, host := range hosts {
+			fmt.Println(host.Alias)
+		}
+		os.Exit(ExitOK)
+	}
+
+	if batch {
+		selected, err := matchTargets(hosts, targets, !test)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+			os.Exit(ExitConfig)
+		}
+		if test {
+			os.Exit(testHosts(selected))
+		}
+		os.Exit(runBatch(selected, runCommand))
+	}
+
+	initialHost := ""
+	if len(targets) > 0 {
+		initialHost = targets[0]
+	}
+	var initial SSHHost
+	if initialHost != "" {
+		var ok bool
+		if initial, ok = findHost(hosts, initialHost); !ok {
+			fmt.Fprintf(os.Stderr, tr("Unknown host: %s\n"), initialHost)
+			os.Exit(ExitConfig)
+		}
+	}
+
+	if pick {
+		// The picker talks on stderr so stdout carries only the alias,
+		// as in: ssh "$(sshtui --pick)"
+		out := os.Stdout
+		os.Stdout = os.Stderr
+		if initialHost == "" {
+			var ok bool
+			if initial, ok = pickHost(hosts); !ok {
+				os.Exit(ExitFailures)
+			}
+		}
+		fmt.Fprintln(out, initial.Alias)
+		os.Exit(ExitOK)
+	}
+
+	for _, warning := range sshClient.Warnings() {
+		notify("%s", warning)
+	}
+	reportOrphans()
+	stopRecovery := startRecoveryState()
+	if initialHost != "" {
+		createSession(initial)
+	}
+	checkForUpdate()
+	startSessionSweeper()
+	loadHandoff()
+
+	newMenu(hosts).Run()
+	stopRecovery()
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/studiowebux/sshtui/pkg/sshconfig"
+)
+
+// managedSource is the provider name of the hosts sshtui keeps itself,
+// with ManagedHosts yes
+const managedSource = "sshtui"
+
+const (
+	managedHostsFile = "managed-hosts.json"
+	// managedFragment is the ssh config generated from the managed hosts,
+	// relative to ~/.ssh, for ~/.ssh/config to Include
+	managedFragment = "config.d/sshtui.conf"
+)
+
+// ManagedHost is a host of sshtui's own store: its alias and the ssh
+// config lines of its block, as "Keyword value"
+type ManagedHost struct {
+	Alias    string
+	Settings []string
+}
+
+// loadManagedHosts returns the managed hosts, in the order they were added
+func loadManagedHosts() ([]ManagedHost, error) {
+	hosts := []ManagedHost{}
+	err := loadState(managedHostsFile, &hosts)
+	return hosts, err
+}
+
+// saveManagedHosts stores the hosts and writes the fragment ssh reads
+func saveManagedHosts(hosts []ManagedHost) error {
+	if err := saveState(managedHostsFile, hosts); err != nil {
+		return err
+	}
+	return writeManagedFragment(hosts)
+}
+
+// renderManagedHosts returns the ssh config of the managed hosts
+func renderManagedHosts(hosts []ManagedHost) string {
+	var b strings.Builder
+	b.WriteString("# Generated by sshtui from ~/.config/sshtui/" + managedHostsFile + ".\n")
+	b.WriteString("# Add, clone and delete these hosts in sshtui: edits here are overwritten.\n")
+	for _, host := range hosts {
+		b.WriteString("\nHost " + host.Alias + "\n")
+		for _, setting := range host.Settings {
+			b.WriteString("    " + setting + "\n")
+		}
+	}
+	return b.String()
+}
+
+// managedFragmentPath returns where the fragment is written
+func managedFragmentPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ssh", managedFragment), nil
+}
+
+// writeManagedFragment writes the fragment when it differs from the
+// store, replacing it whole so ssh never reads half of it
+func writeManagedFragment(hosts []ManagedHost) error {
+	path, err := managedFragmentPath()
+	if err != nil {
+		return err
+	}
+	text := renderManagedHosts(hosts)
+	if current, err := os.ReadFile(path); err == nil && string(current) == text {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// managedIncluded tells whether ~/.ssh/config includes the fragment ahead
+// of its first Host or Match block, where the Include applies to every
+// host; until it does, ssh, scp and git do not know the managed hosts
+func managedIncluded() (bool, error) {
+	configPath, err := sshconfig.DefaultPath()
+	if err != nil {
+		return false, err
+	}
+	fragment, err := managedFragmentPath()
+	if err != nil {
+		return false, err
+	}
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "host", "match":
+			return false, scanner.Err()
+		case "include":
+			for _, pattern := range fields[1:] {
+				pattern = expandHome(pattern)
+				if !filepath.IsAbs(pattern) {
+					// Relative to ~/.ssh, as ssh reads it
+					pattern = filepath.Join(filepath.Dir(configPath), pattern)
+				}
+				if matched, _ := filepath.Match(pattern, fragment); matched {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, scanner.Err()
+}
+
+// managedWarning says why ssh cannot find the managed hosts, "" when it
+// can; the menu shows it
+var managedWarning string
+
+// managedProvider lists the managed hosts, keeping the fragment in step
+// with the store. Their hosts are reached by alias like those of
+// ~/.ssh/config, through the Include
+type managedProvider struct{}
+
+func (managedProvider) Name() string { return managedSource }
+func (managedProvider) Refresh()     {}
+
+func (managedProvider) Load() ([]SSHHost, error) {
+	managed, err := loadManagedHosts()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeManagedFragment(managed); err != nil {
+		return nil, err
+	}
+	// A client without Include has them spelled out, see fromSSHConfig
+	managedWarning = ""
+	if included, err := managedIncluded(); err == nil && !included && sshClient.Supports(FeatureInclude) {
+		managedWarning = fmt.Sprintf(tr("ssh does not know sshtui's hosts: add \"Include %s\" before the first Host of ~/.ssh/config"), managedFragment)
+	}
+
+	parsed, err := sshconfig.Parse(strings.NewReader(renderManagedHosts(managed)))
+	if err != nil {
+		return nil, err
+	}
+	hosts := []SSHHost{}
+	for _, h := range parsed {
+		hosts = append(hosts, sshConfigHost(h))
+	}
+	return hosts, nil
+}
+
+// fromSSHConfig tells whether ssh finds host's settings by its alias:
+// hosts of ~/.ssh/config, and managed hosts through the fragment when
+// the client can Include it
+func fromSSHConfig(host SSHHost) bool {
+	if host.Source == managedSource {
+		return sshClient.Supports(FeatureInclude)
+	}
+	return host.Source == "" || host.Source == sshConfigSource
+}
+
+// addManagedHost stores a host under a new alias; the settings are ssh
+// config lines, HostName first
+func addManagedHost(alias string, settings []string) error {
+	return addManagedHosts(ManagedHost{Alias: alias, Settings: settings})
+}
+
+// addManagedHosts stores hosts under new aliases, all or none of them
+func addManagedHosts(added ...ManagedHost) error {
+	hosts, err := loadManagedHosts()
+	if err != nil {
+		return err
+	}
+	for _, host := range added {
+		if slices.ContainsFunc(hosts, func(h ManagedHost) bool { return h.Alias == host.Alias }) {
+			return fmt.Errorf(tr("%s already exists"), host.Alias)
+		}
+		hosts = append(hosts, host)
+	}
+	return saveManagedHosts(hosts)
+}
+
+// managedBlock returns the lines of a managed host's block, as
+// hostBlock does for ~/.ssh/config, or nil
+func managedBlock(alias string) []string {
+	hosts, err := loadManagedHosts()
+	if err != nil {
+		return nil
+	}
+	return hostBlock(strings.Split(renderManagedHosts(hosts), "\n"), alias)
+}
+
+// removeManagedHost deletes a host from the store
+func removeManagedHost(alias string) error {
+	hosts, err := loadManagedHosts()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(hosts, func(h ManagedHost) bool { return h.Alias == alias })
+	if i < 0 {
+		return fmt.Errorf(tr("%s is not one of sshtui's hosts"), alias)
+	}
+	return saveManagedHosts(slices.Delete(hosts, i, i+1))
+}
+
+// promptManagedHost asks for a new host's alias and settings and stores
+// it; it returns the host list, reloaded when a host was added
+func promptManagedHost(hosts []SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	if !appConfig.ManagedHosts {
+		fmt.Print(tr("Adding hosts needs \"ManagedHosts yes\" in the sshtui config. Press Enter..."))
+		reader.ReadString('\n')
+		return hosts
+	}
+
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	fmt.Println(tr("\nAdd a host (Enter leaves a setting out)"))
+	alias := ask(tr("Alias: "))
+	if alias == "" || strings.ContainsAny(alias, " \t*?") {
+		fmt.Print(tr("Invalid alias. Press Enter..."))
+		reader.ReadString('\n')
+		return hosts
+	}
+	if _, taken := findHost(hosts, alias); taken {
+		fmt.Printf(tr("%s already exists. Press Enter..."), alias)
+		reader.ReadString('\n')
+		return hosts
+	}
+	hostName := valueOr(ask(tr("HostName [alias]: ")), alias)
+
+	settings := []string{"HostName " + hostName}
+	for _, keyword := range []string{"User", "Port", "ProxyJump", "IdentityFile"} {
+		value := ask(keyword + ": ")
+		if strings.ContainsAny(value, " \t") {
+			fmt.Printf(tr("Invalid %s. Press Enter..."), keyword)
+			reader.ReadString('\n')
+			return hosts
+		}
+		if value != "" {
+			settings = append(settings, keyword+" "+value)
+		}
+	}
+	return saveManagedChange(hosts, alias, addManagedHost(alias, settings), tr("%s added. Press Enter..."))
+}
+
+// deleteManagedHost removes a managed host after asking
+func deleteManagedHost(hosts []SSHHost, host SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	if host.Source != managedSource {
+		fmt.Printf(tr("%s is not one of sshtui's hosts (it comes from %s). Press Enter..."), host.Alias, valueOr(host.Source, sshConfigSource))
+		reader.ReadString('\n')
+		return hosts
+	}
+	fmt.Printf(tr("Delete %s from sshtui's hosts? [y/N]: "), host.Alias)
+	input, _ := reader.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+		return hosts
+	}
+	return saveManagedChange(hosts, host.Alias, removeManagedHost(host.Alias), tr("%s deleted. Press Enter..."))
+}
+
+// saveManagedChange reports a change to the store and reloads the hosts
+// after it; done is the message of a success
+func saveManagedChange(hosts []SSHHost, alias string, err error, done string) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	if err == nil {
+		var newHosts []SSHHost
+		if newHosts, err = loadHosts(appConfig, false); err == nil {
+			hosts = newHosts
+		}
+	}
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+	} else {
+		fmt.Printf(done, alias)
+	}
+	reader.ReadString('\n')
+	return hosts
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+)
+
+// Secret mask states
+const (
+	maskOff    = iota
+	maskTyping // the keys of a secret are being typed
+	maskEnter  // Enter was typed, output up to the next line may echo them
+)
+
+// secretMask keeps what is typed at a password prompt out of a session's
+// captures: scrollback, tee and recording
+type secretMask struct {
+	state atomic.Int32
+	keep  []byte // what the captures keep of the chunk being published
+}
+
+// Write works out what the captures keep of an output chunk; the mask is
+// the pipeline's first subscriber, so it sees each chunk before them
+func (m *secretMask) Write(p []byte) (int, error) {
+	m.keep = p
+	switch m.state.Load() {
+	case maskTyping:
+		m.keep = nil
+	case maskEnter:
+		// A prompt echoing the secret echoes it before the line ends
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			m.keep = nil
+			break
+		}
+		if i > 0 && p[i-1] == '\r' {
+			i--
+		}
+		m.keep = p[i:]
+		m.state.CompareAndSwap(maskEnter, maskOff)
+	}
+	return len(p), nil
+}
+
+// maskedWriter passes on to a capture what the mask keeps of each chunk
+type maskedWriter struct {
+	mask *secretMask
+	w    io.Writer
+}
+
+func (m maskedWriter) Write(p []byte) (int, error) {
+	if len(m.mask.keep) > 0 {
+		m.w.Write(m.mask.keep)
+	}
+	return len(p), nil
+}
+
+// maskSecretInput returns the part of typed keys that may be recorded:
+// at a password prompt, keys up to Enter are not, and the captures skip
+// the output until the line ends. Ctrl+C or Ctrl+D gives up the prompt,
+// as does the prompt going away, and the masking ends with it
+func maskSecretInput(session *Session, keys []byte) []byte {
+	mask := &session.secret
+	if !secretPrompt(session) {
+		mask.state.CompareAndSwap(maskTyping, maskOff)
+		return keys
+	}
+	mask.state.Store(maskTyping)
+	end := bytes.IndexAny(keys, "\r\n\x03\x04")
+	if end < 0 {
+		return nil
+	}
+	if keys[end] == '\x03' || keys[end] == '\x04' {
+		mask.state.Store(maskOff)
+	} else {
+		mask.state.Store(maskEnter)
+	}
+	return keys[end:]
+}
+
+// secretPrompt tells whether the session waits for a secret: ssh reads a
+// password or passphrase from its terminal with echo off but line
+// buffering on (a connected session's terminal is raw, echo off too), or
+// the remote output ends with a prompt such as sudo's
+func secretPrompt(session *Session) bool {
+	// A reconnect replaces the PTY
+	sessionsMu.RLock()
+	ptmx := session.PTY
+	prompt := authPrompt(session.Scrollback)
+	sessionsMu.RUnlock()
+	if ptmx != nil && secretEntry(ptmx.Fd()) {
+		return true
+	}
+	return prompt != "" && prompt != hostKeyPrompt
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMaskSecretInput(t *testing.T) {
+	session := &Session{Scrollback: []byte("$ sudo ls\r\n[sudo] password for me: ")}
+
+	if keys := maskSecretInput(session, []byte("hunt")); keys != nil {
+		t.Errorf("recorded %q of the password", keys)
+	}
+	if keys := maskSecretInput(session, []byte("er2\r")); string(keys) != "\r" {
+		t.Errorf("recorded %q, want Enter only", keys)
+	}
+	if state := session.secret.state.Load(); state != maskEnter {
+		t.Errorf("state %d after Enter, want maskEnter", state)
+	}
+}
+
+func TestMaskSecretInputAborted(t *testing.T) {
+	session := &Session{Scrollback: []byte("[sudo] password for me: ")}
+
+	maskSecretInput(session, []byte("hun"))
+	if keys := maskSecretInput(session, []byte("\x03")); string(keys) != "\x03" {
+		t.Errorf("recorded %q, want Ctrl+C", keys)
+	}
+	if state := session.secret.state.Load(); state != maskOff {
+		t.Errorf("state %d after Ctrl+C, want maskOff", state)
+	}
+
+	// The output after it is captured again
+	session.secret.Write([]byte("^C\r\n$ "))
+	if string(session.secret.keep) != "^C\r\n$ " {
+		t.Errorf("captures keep %q after Ctrl+C", session.secret.keep)
+	}
+}
+
+func TestMaskSecretInputPromptGone(t *testing.T) {
+	session := &Session{Scrollback: []byte("Password: ")}
+	maskSecretInput(session, []byte("hun"))
+
+	// The prompt was answered or gave up without the keys typed here
+	session.Scrollback = []byte("Password: \r\nsudo: timed out reading password\r\n$ ")
+	if keys := maskSecretInput(session, []byte("ls\r")); string(keys) != "ls\r" {
+		t.Errorf("recorded %q, want the command", keys)
+	}
+	if state := session.secret.state.Load(); state != maskOff {
+		t.Errorf("state %d, want maskOff", state)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Menu is the interactive host and session list: the hosts loaded, their
+// search index and hotkeys, and how the list is filtered and scrolled
+type Menu struct {
+	hosts   []SSHHost
+	index   *HostIndex
+	hotkeys map[string]SSHHost
+	view    MenuView
+	shown   []SSHHost // the list as last drawn, which numbers refer to
+
+	snapshot string        // menuSnapshot when last drawn
+	redraws  chan struct{} // the waiting prompt's, nil while a command runs
+}
+
+func newMenu(hosts []SSHHost) *Menu {
+	m := &Menu{}
+	m.setHosts(hosts)
+	return m
+}
+
+// setHosts replaces the hosts, after a reload or a clone
+func (m *Menu) setHosts(hosts []SSHHost) {
+	m.hosts = hosts
+	m.index = newHostIndex(hosts)
+	m.hotkeys = hostHotkeys(hosts)
+	setWatchedHosts(hosts, healthSettingsOf(appConfig))
+}
+
+// visibleHosts applies the filters and order of the host list, probing
+// hosts again when sorting by latency
+func (m *Menu) visibleHosts() []SSHHost {
+	shown := m.index.Search(m.view.Filter)
+	if m.view.FailedOnly {
+		shown = failedHosts(shown)
+	}
+	if m.view.ByLatency {
+		if !readOnly && healthRefreshDue() {
+			refreshHealth(shown)
+		}
+		shown = sortByHealth(shown)
+	}
+	if dashboardDue(m.hosts) {
+		refreshDashboards(m.hosts)
+	}
+	return shown
+}
+
+// draw shows the list and remembers it for the numbers typed next
+func (m *Menu) draw() {
+	m.shown = m.visibleHosts()
+	showMenu(m.shown, &m.view)
+}
+
+// Run shows the menu and handles commands until quit or stdin fails.
+// Everything happens on events: typed commands, session changes, timers
+// and resizes go through one dispatcher, so they never run at once
+func (m *Menu) Run() {
+	d := newDispatcher()
+	d.On(EventInput, func(e Event) {
+		m.redraws = nil
+		if e.Err != nil {
+			fmt.Fprintf(os.Stderr, tr("\nError reading input: %v\n"), e.Err)
+			closeAllSessions()
+			d.Stop()
+			return
+		}
+		if !m.handle(strings.TrimSpace(e.Input)) {
+			d.Stop()
+			return
+		}
+		m.prompt(d)
+	})
+	d.On(EventSession, func(Event) { m.redraw() })
+	d.On(EventResize, func(Event) { m.redraw() })
+	d.On(EventTimer, func(Event) {
+		if menuSnapshot() != m.snapshot || (m.view.ByLatency && !readOnly && healthRefreshDue()) || dashboardDue(m.hosts) {
+			m.redraw()
+		}
+	})
+
+	stop := startEventSources(d)
+	defer stop()
+	stopWatch := startHealthWatch()
+	defer stopWatch()
+	m.prompt(d)
+	d.Run()
+}
+
+// prompt draws the menu and reads the next command, posted as an
+// EventInput
+func (m *Menu) prompt(d *Dispatcher) {
+	m.draw()
+	m.snapshot = menuSnapshot()
+	redraws := make(chan struct{}, 1)
+	m.redraws = redraws
+	go func() {
+		input, err := readMenuInput("> ", m.draw, redraws, func(key string) bool {
+			_, ok := m.hotkeys[key]
+			return ok && !readOnly
+		})
+		d.Post(Event{Kind: EventInput, Input: input, Err: err})
+	}()
+}
+
+// redraw has the prompt repaint the menu around what is being typed
+func (m *Menu) redraw() {
+	if m.redraws == nil {
+		return
+	}
+	m.snapshot = menuSnapshot()
+	select {
+	case m.redraws <- struct{}{}:
+	default:
+	}
+}
+
+// menuCommands are the menu commands typed alone
+var menuCommands = map[string]func(m *Menu){
+	"x": func(m *Menu) { closeActiveSession() },
+	"e": func(m *Menu) { clearEndedSessions() },
+	"v": func(m *Menu) { chooseScrollback() },
+	"E": func(m *Menu) { exportStateMenu() },
+	"m": func(m *Menu) {
+		if selected := selectHosts(withLocalHost(m.shown)); selected != nil {
+			executeMultiHost(selected, "")
+		}
+	},
+	"f": func(m *Menu) { manageForwards(m.hosts) },
+	"b": func(m *Menu) { manageSessions() },
+	"T": func(m *Menu) { showTimeReport() },
+	"w": func(m *Menu) { chooseWorkspace(m.hosts) },
+	"n": func(m *Menu) { showWatchMatches() },
+	"M": func(m *Menu) { showControlMasters() },
+	"N": func(m *Menu) { showNotifications() },
+	"/": func(m *Menu) { searchHistory(m.hosts) },
+	"F": func(m *Menu) {
+		// Toggle the recently-failed filter
+		m.view.FailedOnly = !m.view.FailedOnly
+		m.view.Offset = 0
+	},
+	"L": func(m *Menu) {
+		// Toggle the on-call order: responsive hosts first
+		m.view.ByLatency = !m.view.ByLatency
+		m.view.Offset = 0
+	},
+	">": func(m *Menu) { m.view.Offset += m.view.PageSize },
+	"<": func(m *Menu) { m.view.Offset -= m.view.PageSize },
+	"A": func(m *Menu) { m.setHosts(promptManagedHost(m.hosts)) },
+	"I": func(m *Menu) { m.setHosts(importHosts(m.hosts)) },
+	"P": func(m *Menu) { relayPrompts() },
+	"r": func(m *Menu) {
+		// Reload SSH and sshtui config, showing host changes first
+		m.setHosts(reloadConfig(m.hosts))
+	},
+}
+
+// hostCommands take the number of a host in the list after their letter,
+// as in h3
+var hostCommands = map[string]func(m *Menu, host SSHHost){
+	"o": func(m *Menu, host SSHHost) {
+		// Connect with extra ssh options
+		if host, ok := promptExtras(host); ok {
+			createSession(host)
+		}
+	},
+	"a": func(m *Menu, host SSHHost) { chooseRemoteTmux(host) },
+	"W": func(m *Menu, host SSHHost) { openExternal(host) },
+	"C": func(m *Menu, host SSHHost) { m.setHosts(cloneHost(m.hosts, host)) },
+	"D": func(m *Menu, host SSHHost) { m.setHosts(deleteManagedHost(m.hosts, host)) },
+	"h": func(m *Menu, host SSHHost) { showHostDetail(host) },
+	"t": func(m *Menu, host SSHHost) { testConnection(host) },
+}
+
+// handle runs one menu command, returning false to quit
+func (m *Menu) handle(input string) bool {
+	if input == "q" {
+		closeAllSessions()
+		cleanupControlMasters()
+		return false
+	}
+
+	if readOnly && !readOnlyAllowed(input) {
+		// Anything that could reach a host is off
+		refuseReadOnly(bufio.NewReader(os.Stdin))
+		return true
+	}
+
+	if host, ok := m.hotkeys[input]; ok {
+		// Connect to the host bound to a hotkey
+		connectHost(host)
+		return true
+	}
+
+	if command, ok := menuCommands[input]; ok {
+		command(m)
+		return true
+	}
+
+	if input == "s" || strings.HasPrefix(input, "s ") {
+		// Filter hosts; s alone clears the filter
+		m.view.Filter = strings.TrimSpace(strings.TrimPrefix(input, "s"))
+		m.view.Offset = 0
+		return true
+	}
+
+	if strings.HasPrefix(input, "u") {
+		// Reopen a session imported from another machine
+		var num int
+		if _, err := fmt.Sscanf(input, "u%d", &num); err == nil {
+			reopenHandoff(m.hosts, num)
+		} else {
+			pressEnter(tr("Invalid session number. Press Enter to continue..."))
+		}
+		return true
+	}
+
+	if strings.HasPrefix(input, "i!") || strings.HasPrefix(input, "c!") {
+		// Session process detail, or the critical watchdog
+		var num int
+		if _, err := fmt.Sscanf(input[2:], "%d", &num); err == nil {
+			if input[0] == 'i' {
+				if session := sessionNumber(num); session != nil {
+					showSessionDetail(session)
+					return true
+				}
+			} else if _, ok := cycleWatch(num); ok {
+				return true
+			}
+		}
+		pressEnter(tr("Invalid session number. Press Enter to continue..."))
+		return true
+	}
+
+	if command, ok := hostCommands[input[:min(len(input), 1)]]; ok {
+		var num int
+		if _, err := fmt.Sscanf(input[1:], "%d", &num); err == nil && num > 0 && num <= len(m.shown) {
+			command(m, m.shown[num-1])
+		} else {
+			pressEnter(tr("Invalid host number. Press Enter to continue..."))
+		}
+		return true
+	}
+
+	if strings.HasPrefix(input, "~") {
+		// Scrollback of an archived session
+		var num int
+		if _, err := fmt.Sscanf(input, "~%d", &num); err == nil {
+			if session := archivedNumber(num); session != nil {
+				viewScrollback(session)
+				return true
+			}
+		}
+		pressEnter(tr("Invalid session number. Press Enter to continue..."))
+		return true
+	}
+
+	// Check for session (!number) or host (number)
+	if strings.HasPrefix(input, "!") {
+		// Resume session
+		var num int
+		if _, err := fmt.Sscanf(input, "!%d", &num); err == nil {
+			if session := sessionNumber(num); session != nil {
+				attachToSession(session)
+			} else {
+				sessionsMu.RLock()
+				count := len(sessions)
+				sessionsMu.RUnlock()
+				fmt.Printf(tr("Invalid session number: %d (have %d sessions)\n"), num, count)
+				pressEnter(tr("Press Enter to continue..."))
+			}
+		} else {
+			fmt.Printf(tr("Invalid format: %s (expected !number)\n"), input)
+			pressEnter(tr("Press Enter to continue..."))
+		}
+		return true
+	}
+
+	// Connect to new host
+	var num int
+	if _, err := fmt.Sscanf(input, "%d", &num); err == nil {
+		if num > 0 && num <= len(m.shown) {
+			connectHost(m.shown[num-1])
+		} else {
+			fmt.Println(tr("Invalid host number"))
+		}
+	} else if input != "" {
+		pressEnter(tr("Invalid command. Press Enter to continue..."))
+	}
+	return true
+}
+
+// sessionNumber returns session num as numbered in the menu, or nil
+func sessionNumber(num int) *Session {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	if num > 0 && num <= len(sessions) {
+		return sessions[num-1]
+	}
+	return nil
+}
+
+// archivedNumber returns archived session num, as numbered in the menu,
+// or nil
+func archivedNumber(num int) *Session {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	if num > 0 && num <= len(archivedSessions) {
+		return archivedSessions[num-1]
+	}
+	return nil
+}
+
+// chooseScrollback asks for a live (!number) or archived (~number)
+// session and shows its scrollback
+func chooseScrollback() {
+	sessionsMu.RLock()
+	hasSession := len(sessions) > 0 || len(archivedSessions) > 0
+	sessionsMu.RUnlock()
+	if !hasSession {
+		fmt.Println(tr("No active sessions"))
+		return
+	}
+
+	fmt.Print(tr("Which session? [!number or ~number]: "))
+	numStr, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Printf(tr("Error reading input: %v\n"), err)
+		return
+	}
+	numStr = strings.TrimSpace(numStr)
+	var num int
+	var session *Session
+	switch {
+	case strings.HasPrefix(numStr, "!"):
+		if _, err := fmt.Sscanf(numStr, "!%d", &num); err != nil {
+			return
+		}
+		session = sessionNumber(num)
+	case strings.HasPrefix(numStr, "~"):
+		if _, err := fmt.Sscanf(numStr, "~%d", &num); err != nil {
+			return
+		}
+		session = archivedNumber(num)
+	default:
+		return
+	}
+	if session == nil {
+		fmt.Println(tr("Invalid session number"))
+		return
+	}
+	viewScrollback(session)
+}
+
+// pressEnter prints message, one asking to press Enter, and waits for it
+func pressEnter(message string) {
+	fmt.Println(message)
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/studiowebux/sshtui/pkg/multirun"
+)
+
+// InputPollInterval is how often a multi-host run checks for typed commands
+const InputPollInterval = 100 * time.Millisecond
+
+type HostResult struct {
+	Alias  string
+	Output string
+	Error  error
+}
+
+// executeMultiHost runs a command on several hosts; an empty command is
+// asked for
+func executeMultiHost(hosts []SSHHost, command string) {
+	if len(hosts) == 0 {
+		fmt.Println(tr("No hosts selected. Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	// Tagged hosts must be confirmed one by one; declined ones are dropped
+	confirmed := []SSHHost{}
+	for _, host := range hosts {
+		if confirmHost(host, ConfirmMulti) {
+			confirmed = append(confirmed, host)
+		}
+	}
+	hosts = confirmed
+	if len(hosts) == 0 {
+		return
+	}
+
+	// Say plainly when the command also runs here
+	aliases := []string{}
+	for _, host := range hosts {
+		if isLocal(host) {
+			aliases = append(aliases, colorize("1;33", host.Alias+tr(" (this machine, without ssh)")))
+		} else {
+			aliases = append(aliases, host.Alias)
+		}
+	}
+	fmt.Printf(tr("\nHosts: %s\n"), strings.Join(aliases, ", "))
+
+	reader := bufio.NewReader(os.Stdin)
+	if command == "" {
+		fmt.Print(tr("\nEnter command to execute (or @script.sh args to upload and run a script): "))
+		command, _ = reader.ReadString('\n')
+		command = strings.TrimSpace(command)
+	} else {
+		fmt.Printf(tr("\nCommand: %s\n"), command)
+	}
+
+	if command == "" {
+		return
+	}
+	if hosts = askMultiReason(hosts, command); len(hosts) == 0 {
+		return
+	}
+	recordCommand(command, hosts)
+
+	job := parseMultiJob(command)
+	if job.Script != "" {
+		if _, err := os.Stat(job.Script); err != nil {
+			fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+			reader.ReadString('\n')
+			return
+		}
+	}
+
+	fmt.Print(tr("\nDisplay mode:\n"))
+	fmt.Println(tr("  [1] Live streaming (see output as it arrives)"))
+	fmt.Println(tr("  [2] Collected results (all at once)"))
+	fmt.Print("> ")
+
+	modeInput, _ := reader.ReadString('\n')
+	modeInput = strings.TrimSpace(modeInput)
+
+	timeout := appConfig.MultiTimeout
+	fmt.Printf(tr("\nTimeout per host (e.g. 30s, 5m, 0 for none) [%v]: "), timeout)
+	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+		var err error
+		if timeout, err = parseTimeout(strings.TrimSpace(input)); err != nil {
+			fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+			reader.ReadString('\n')
+			return
+		}
+	}
+
+	if modeInput == "1" {
+		executeMultiHostLive(hosts, job, timeout)
+	} else {
+		executeMultiHostCollected(hosts, job, timeout)
+	}
+}
+
+func executeMultiHostLive(hosts []SSHHost, job MultiJob, timeout time.Duration) {
+	clearScreen()
+	printHeader(tr("Multi-Host Execution (Live)"))
+	fmt.Printf(tr("Command: %s\n"), job)
+	fmt.Print(tr("Type c and Enter to cancel, p<number> to pin a host full-screen, p to unpin\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	live := newLiveOutput(hosts)
+	live.printLegend()
+	stopWatch := watchInput(func(input string) {
+		var num int
+		switch {
+		case input == "c":
+			fmt.Println(tr("Cancelling..."))
+			cancel()
+		case input == "p":
+			live.pin("")
+		case strings.HasPrefix(input, "p"):
+			if _, err := fmt.Sscanf(input, "p%d", &num); err == nil && num > 0 && num <= len(hosts) {
+				live.pin(hosts[num-1].Alias)
+			}
+		}
+	})
+
+	results := make([]HostResult, len(hosts))
+	var wg sync.WaitGroup
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(idx int, h SSHHost) {
+			defer wg.Done()
+			stream := live.stream(h.Alias)
+			results[idx] = runOnHost(ctx, h, job, timeout, stream)
+			stream.flush()
+		}(i, host)
+	}
+
+	wg.Wait()
+	stopWatch()
+	cancel()
+
+	fmt.Println()
+	printSeparator()
+	for _, result := range results {
+		if result.Error != nil {
+			fmt.Printf("  %s %s: %v\n", failMark(), result.Alias, result.Error)
+		} else {
+			fmt.Printf("  %s %s\n", okMark(), result.Alias)
+		}
+	}
+	fmt.Println(tr("\nExecution complete. Press Enter..."))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// hostColors are the SGR colors of host prefixes, distinct on dark and
+// light backgrounds
+var hostColors = []string{"31", "32", "33", "34", "35", "36", "91", "92", "93", "94", "95", "96"}
+
+// assignHostColors gives each alias the color its name hashes to, so a
+// host keeps its color from run to run; a color already taken in this run
+// moves to the next free one
+func assignHostColors(hosts []SSHHost) map[string]string {
+	colors := make(map[string]string)
+	taken := make(map[int]bool)
+	for _, h := range hosts {
+		hash := fnv.New32a()
+		hash.Write([]byte(h.Alias))
+		i := int(hash.Sum32() % uint32(len(hostColors)))
+		// Once every color is taken, repeat them
+		if len(taken) == len(hostColors) {
+			clear(taken)
+		}
+		for taken[i] {
+			i = (i + 1) % len(hostColors)
+		}
+		taken[i] = true
+		colors[h.Alias] = hostColors[i]
+	}
+	return colors
+}
+
+// liveOutput interleaves the output of several hosts line by line behind
+// [alias] prefixes, each host in its own color on a terminal. While a
+// host is pinned only its lines are shown, full-screen and unprefixed
+type liveOutput struct {
+	mu      sync.Mutex
+	pinned  string
+	width   int                      // widest alias, to align prefixes
+	history map[string]*bytes.Buffer // each host's lines, replayed on pin
+	colors  map[string]string        // prefix color per alias, none when piped
+	hosts   []SSHHost
+}
+
+func newLiveOutput(hosts []SSHHost) *liveOutput {
+	l := &liveOutput{history: make(map[string]*bytes.Buffer), hosts: hosts}
+	for _, h := range hosts {
+		l.width = max(l.width, len(h.Alias))
+		l.history[h.Alias] = &bytes.Buffer{}
+	}
+	if stdoutIsTerminal() {
+		l.colors = assignHostColors(hosts)
+	}
+	return l
+}
+
+// paint shows text in the color of the host
+func (l *liveOutput) paint(alias, text string) string {
+	if color, ok := l.colors[alias]; ok {
+		return colorize(color, text)
+	}
+	return text
+}
+
+// printLegend lists the hosts in their colors with the numbers p takes
+func (l *liveOutput) printLegend() {
+	entries := []string{}
+	for i, h := range l.hosts {
+		entries = append(entries, fmt.Sprintf("%d:%s", i+1, l.paint(h.Alias, "["+h.Alias+"]")))
+	}
+	fmt.Printf(tr("Hosts: %s\n\n"), strings.Join(entries, " "))
+}
+
+// stream returns the writer receiving one host's output
+func (l *liveOutput) stream(alias string) *hostStream {
+	return &hostStream{live: l, alias: alias}
+}
+
+// emit shows one complete line; callers hold l.mu
+func (l *liveOutput) emit(alias, line string) {
+	l.history[alias].WriteString(line + "\n")
+	switch l.pinned {
+	case "":
+		fmt.Println(l.paint(alias, fmt.Sprintf("%-*s", l.width+2, "["+alias+"]")) + " " + line)
+	case alias:
+		fmt.Println(line)
+	}
+}
+
+// pin shows one host full-screen, replaying its output so far; "" goes
+// back to the interleaved view
+func (l *liveOutput) pin(alias string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pinned = alias
+	clearScreen()
+	if alias == "" {
+		printHeader(tr("Multi-Host Execution (Live)"))
+		l.printLegend()
+		return
+	}
+	printHeader(tr("Pinned: ")+alias, tr("p to unpin, c to cancel"))
+	os.Stdout.Write(l.history[alias].Bytes())
+}
+
+// hostStream splits a host's output into lines for liveOutput
+type hostStream struct {
+	live    *liveOutput
+	alias   string
+	partial []byte
+}
+
+func (s *hostStream) Write(p []byte) (int, error) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.live.emit(s.alias, strings.TrimRight(string(s.partial[:i]), "\r"))
+		s.partial = s.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush shows a last line that had no newline
+func (s *hostStream) flush() {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	if len(s.partial) > 0 {
+		s.live.emit(s.alias, strings.TrimRight(string(s.partial), "\r"))
+		s.partial = nil
+	}
+}
+
+func executeMultiHostCollected(hosts []SSHHost, job MultiJob, timeout time.Duration) {
+	results := make([]HostResult, len(hosts))
+	pending := make([]int, len(hosts))
+	for i := range hosts {
+		pending[i] = i
+	}
+
+	for {
+		collectResults(hosts, pending, job, timeout, results)
+		if !viewResults(job, results) {
+			return
+		}
+
+		pending = []int{}
+		for i, result := range results {
+			if result.Error != nil {
+				pending = append(pending, i)
+			}
+		}
+	}
+}
+
+// collectResults runs the job on the hosts at the given indexes and
+// stores their results there, leaving the other results as they are
+func collectResults(hosts []SSHHost, indexes []int, job MultiJob, timeout time.Duration, results []HostResult) {
+	clearScreen()
+	printHeader(tr("Multi-Host Execution (Collecting...)"))
+	fmt.Print(tr("Type c and Enter to cancel the hosts still running\n\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopWatch := watchInput(func(input string) {
+		if input == "c" {
+			fmt.Println(tr("Cancelling..."))
+			cancel()
+		}
+	})
+
+	var wg sync.WaitGroup
+	for _, idx := range indexes {
+		wg.Add(1)
+		go func(idx int, h SSHHost) {
+			defer wg.Done()
+			result := runOnHost(ctx, h, job, timeout, nil)
+			results[idx] = result
+			if result.Error != nil {
+				fmt.Printf("  %s %s: %v\n", failMark(), h.Alias, result.Error)
+			} else {
+				fmt.Printf("  %s %s\n", okMark(), h.Alias)
+			}
+		}(idx, hosts[idx])
+	}
+
+	wg.Wait()
+	stopWatch()
+	cancel()
+}
+
+// parseTimeout reads a duration such as 30s or 5m; a bare number is seconds
+func parseTimeout(value string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf(tr("invalid timeout %q"), value)
+	}
+	return d, nil
+}
+
+// runOnHost runs the job on one host, killing ssh when the timeout
+// expires or the run is cancelled; output received so far is kept and,
+// when stream is set, copied to it as it arrives. The local pseudo-host
+// runs it with sh, on a PTY all the same
+func runOnHost(ctx context.Context, h SSHHost, job MultiJob, timeout time.Duration, stream io.Writer) HostResult {
+	result := HostResult{Alias: h.Alias}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var cmd *exec.Cmd
+	if isLocal(h) {
+		cmd = commander.Command(ctx, "sh", "-c", job.prepareLocal())
+	} else if providerCommand(h) != nil {
+		markHostTouched(h.Alias)
+		argv, err := providerRunCommand(h, job)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		cmd = commander.Command(ctx, argv[0], argv[1:]...)
+	} else {
+		markHostTouched(h.Alias)
+		if err := refreshCertificate(h); err != nil {
+			result.Error = err
+			return result
+		}
+		command, err := job.prepare(ctx, h)
+		if err != nil {
+			result.Error = err
+			if ctx.Err() != nil {
+				result.Error = stoppedError(ctx, timeout)
+			}
+			return result
+		}
+		cmd = commander.Command(ctx, "ssh", buildExecArgs(h, command)...)
+	}
+	var watcher io.Writer = &touchWatcher{alias: h.Alias, stream: stream}
+	codec := newEncodingCodec(h)
+	if codec != nil {
+		watcher = decodingWriter{codec, watcher}
+	}
+	output, err := multirun.RunWith(ctx, ptyStarter, cmd, ptySize(), watcher)
+	result.Output = output
+	if codec != nil {
+		result.Output = string(codec.decode([]byte(output)))
+	}
+
+	// A command failing on the host counts as a failed host
+	var exitErr *multirun.ExitError
+	switch {
+	case errors.Is(err, multirun.ErrTimeout):
+		result.Error = fmt.Errorf(tr("timed out after %v"), timeout)
+	case errors.Is(err, multirun.ErrCancelled):
+		result.Error = errors.New(tr("cancelled"))
+	case errors.As(err, &exitErr):
+		result.Error = fmt.Errorf(tr("exit status %d"), exitErr.Code)
+	case err != nil:
+		result.Error = fmt.Errorf(tr("error starting: %v"), err)
+	}
+	return result
+}
+
+// stoppedError tells why a host's run was stopped: its timeout, or the
+// run cancelled
+func stoppedError(ctx context.Context, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf(tr("timed out after %v"), timeout)
+	}
+	return errors.New(tr("cancelled"))
+}
+
+// watchInput passes each line typed during a run to handle; the returned
+// function stops watching and gives the terminal back
+func watchInput(handle func(input string)) func() {
+	done := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		defer close(stopped)
+		buf := make([]byte, 64)
+		var line []byte
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			// Reading only what is there, so stopping never waits for a key
+			if !term.InputWithin(InputPollInterval) {
+				continue
+			}
+			n, err := term.Read(buf)
+			if err != nil {
+				return
+			}
+			line = append(line, buf[:n]...)
+			for {
+				i := bytes.IndexByte(line, '\n')
+				if i < 0 {
+					break
+				}
+				handle(strings.TrimSpace(string(line[:i])))
+				line = line[i+1:]
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
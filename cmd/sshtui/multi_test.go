@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiowebux/sshtui/pkg/ptysession/ptytest"
+)
+
+// fakeSSH plays ssh: it runs the remote command, its last argument,
+// locally
+const fakeSSH = `for arg; do command=$arg; done; exec sh -c "$command"`
+
+// headless swaps the terminal, commands and PTYs for fakes until the test
+// ends
+func headless(t *testing.T, script string) *ptytest.Commander {
+	t.Helper()
+	oldTerm, oldCommander, oldStarter := term, commander, ptyStarter
+	t.Cleanup(func() {
+		term, commander, ptyStarter = oldTerm, oldCommander, oldStarter
+	})
+	fake := &ptytest.Commander{Script: script}
+	term, commander, ptyStarter = ptytest.NewTerminal(80, 24), fake, &ptytest.Starter{}
+	return fake
+}
+
+func TestRunOnHost(t *testing.T) {
+	fake := headless(t, fakeSSH)
+	host := SSHHost{Alias: "web", HostName: "10.0.0.2"}
+
+	result := runOnHost(context.Background(), host, MultiJob{Command: "echo up"}, time.Second, nil)
+	if result.Alias != "web" || result.Error != nil || result.Output != "up\n" {
+		t.Errorf("got %+v, want web with output %q", result, "up\n")
+	}
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0][0] != "ssh" || !strings.Contains(strings.Join(calls[0], " "), "web") {
+		t.Errorf("ran %q, want ssh to web", calls)
+	}
+}
+
+func TestRunOnHostFailures(t *testing.T) {
+	headless(t, fakeSSH)
+	host := SSHHost{Alias: "db"}
+
+	tests := []struct {
+		command string
+		output  string
+		err     string
+	}{
+		{"echo partial; exit 3", "partial\n", "exit status 3"},
+		{"exec sleep 5", "", "timed out after 200ms"},
+	}
+	for _, tt := range tests {
+		result := runOnHost(context.Background(), host, MultiJob{Command: tt.command}, 200*time.Millisecond, nil)
+		if result.Error == nil || result.Error.Error() != tt.err || result.Output != tt.output {
+			t.Errorf("%q: got %q, %v; want %q, %s", tt.command, result.Output, result.Error, tt.output, tt.err)
+		}
+	}
+}
+
+func TestRunOnHostCancelled(t *testing.T) {
+	headless(t, fakeSSH)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := runOnHost(ctx, SSHHost{Alias: "web"}, MultiJob{Command: "echo up"}, 0, nil)
+	if result.Error == nil || result.Error.Error() != "cancelled" {
+		t.Errorf("got %v, want cancelled", result.Error)
+	}
+}
+
+func TestRunOnHostStream(t *testing.T) {
+	headless(t, fakeSSH)
+	var stream strings.Builder
+
+	result := runOnHost(context.Background(), SSHHost{Alias: "web"}, MultiJob{Command: "echo one; echo two"}, time.Second, &stream)
+	if stream.String() != result.Output || result.Output != "one\ntwo\n" {
+		t.Errorf("streamed %q, collected %q", stream.String(), result.Output)
+	}
+}
+
+func TestRunOnHostUploadTimeout(t *testing.T) {
+	// A host that never answers, the script upload included
+	fake := headless(t, "exec sleep 5")
+	script := t.TempDir() + "/job.sh"
+	if err := os.WriteFile(script, []byte("echo hi\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	result := runOnHost(context.Background(), SSHHost{Alias: "web"}, MultiJob{Script: script}, 200*time.Millisecond, nil)
+	if result.Error == nil || result.Error.Error() != "timed out after 200ms" {
+		t.Errorf("got %v, want timed out after 200ms", result.Error)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("the upload was not stopped by the timeout, took %v", elapsed)
+	}
+	if calls := fake.Calls(); len(calls) != 1 {
+		t.Errorf("ran %q, want the upload only", calls)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ControlMaster represents a running ssh multiplexing master
+type ControlMaster struct {
+	Alias      string
+	SocketPath string
+	PID        int       // of the master, 0 when ssh did not say
+	Started    time.Time // when the socket was created
+}
+
+// masterPID finds the pid in ssh -O check's "Master running (pid=123)"
+var masterPID = regexp.MustCompile(`pid=(\d+)`)
+
+var (
+	touchedHosts   = make(map[string]bool)
+	touchedHostsMu sync.Mutex
+)
+
+// markHostTouched records that sshtui opened a connection to the host
+func markHostTouched(alias string) {
+	touchedHostsMu.Lock()
+	touchedHosts[alias] = true
+	touchedHostsMu.Unlock()
+}
+
+// controlPath returns the effective ControlPath for a host, or "" if none
+func controlPath(alias string) string {
+	out, err := commander.Command(context.Background(), "ssh", "-G", alias).Output()
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 || parts[0] != "controlpath" {
+			continue
+		}
+		if parts[1] == "none" {
+			return ""
+		}
+		return strings.Join(parts[1:], " ")
+	}
+	return ""
+}
+
+// findControlMasters checks touched hosts for a live master connection
+func findControlMasters() []ControlMaster {
+	if !sshClient.Supports(FeatureControlMaster) || !sshClient.Supports(FeatureResolve) {
+		return nil
+	}
+	touchedHostsMu.Lock()
+	aliases := make([]string, 0, len(touchedHosts))
+	for alias := range touchedHosts {
+		aliases = append(aliases, alias)
+	}
+	touchedHostsMu.Unlock()
+	sort.Strings(aliases)
+
+	masters := []ControlMaster{}
+	for _, alias := range aliases {
+		path := controlPath(alias)
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		// ssh -O check exits 0 only when a master is running
+		out, err := checkControlMaster(alias)
+		if err != nil {
+			continue
+		}
+		master := ControlMaster{Alias: alias, SocketPath: path, Started: info.ModTime()}
+		if m := masterPID.FindStringSubmatch(out); m != nil {
+			master.PID, _ = strconv.Atoi(m[1])
+		}
+		masters = append(masters, master)
+	}
+	return masters
+}
+
+// checkControlMaster runs ssh -O check and returns what it said
+func checkControlMaster(alias string) (string, error) {
+	out, err := commander.Command(context.Background(), "ssh", "-O", "check", alias).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func exitControlMaster(alias string) error {
+	return commander.Command(context.Background(), "ssh", "-O", "exit", alias).Run()
+}
+
+// sharedSessions counts the running sessions of alias, which share its
+// master connection; callers hold sessionsMu
+func sharedSessions(alias string) int {
+	count := 0
+	for _, s := range sessions {
+		if s.Alias == alias && s.running() {
+			count++
+		}
+	}
+	return count
+}
+
+// showControlMasters lists the master connections of the hosts sshtui
+// connected to, with their sessions and age, to check or stop them
+func showControlMasters() {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		clearScreen()
+		printHeader(tr("SSH connection sharing"))
+		fmt.Println(tr("Checking control masters..."))
+		masters := findControlMasters()
+
+		if len(masters) == 0 {
+			if !sshClient.Supports(FeatureControlMaster) || !sshClient.Supports(FeatureResolve) {
+				fmt.Printf(tr("  unavailable: %s lacks %s or %s\n"), sshClient.Version, FeatureControlMaster, FeatureResolve)
+			} else {
+				fmt.Println(tr("  none: no host connected from here has a running ControlMaster"))
+			}
+			fmt.Print(tr("\nPress Enter..."))
+			reader.ReadString('\n')
+			return
+		}
+		sessionsMu.RLock()
+		for i, m := range masters {
+			pid := "?"
+			if m.PID > 0 {
+				pid = strconv.Itoa(m.PID)
+			}
+			fmt.Printf(tr("  [%d] %s, pid %s, %d sessions, up %s\n      %s\n"), i+1, m.Alias, pid,
+				sharedSessions(m.Alias), formatDuration(int64(time.Since(m.Started).Seconds())), m.SocketPath)
+		}
+		sessionsMu.RUnlock()
+
+		fmt.Print(tr("\nc[number] checks, x[number] stops, Enter goes back: "))
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return
+		}
+		if len(input) < 2 || (input[0] != 'c' && input[0] != 'x') {
+			continue
+		}
+		if input[0] == 'x' && refuseReadOnly(reader) {
+			continue
+		}
+		num, err := strconv.Atoi(input[1:])
+		if err != nil || num < 1 || num > len(masters) {
+			fmt.Print(tr("Invalid number. Press Enter..."))
+			reader.ReadString('\n')
+			continue
+		}
+		m := masters[num-1]
+		if input[0] == 'c' {
+			if out, err := checkControlMaster(m.Alias); err != nil {
+				fmt.Printf("  %s %s: %s\n", failMark(), m.Alias, valueOr(out, err.Error()))
+			} else {
+				fmt.Printf("  %s %s: %s\n", okMark(), m.Alias, out)
+			}
+		} else {
+			// Sessions sharing the master lose their connection with it
+			sessionsMu.RLock()
+			n := sharedSessions(m.Alias)
+			sessionsMu.RUnlock()
+			if n > 0 && !confirmStopMaster(m.Alias, n) {
+				continue
+			}
+			if err := exitControlMaster(m.Alias); err != nil {
+				fmt.Printf("  %s %s: %v\n", failMark(), m.Alias, err)
+			} else {
+				fmt.Printf(tr("  %s %s stopped\n"), okMark(), m.Alias)
+			}
+		}
+		fmt.Print(tr("\nPress Enter..."))
+		reader.ReadString('\n')
+	}
+}
+
+// confirmStopMaster asks before stopping a master that sessions use
+func confirmStopMaster(alias string, sessionCount int) bool {
+	fmt.Printf(tr("%d sessions of %s go through it and will drop. Stop it? [y/N]: "), sessionCount, alias)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+// cleanupControlMasters offers to stop masters left behind by this run
+func cleanupControlMasters() {
+	masters := findControlMasters()
+	if len(masters) == 0 {
+		return
+	}
+
+	fmt.Println(tr("\nLingering SSH control masters:"))
+	for _, m := range masters {
+		fmt.Printf("  %s (%s)\n", m.Alias, m.SocketPath)
+	}
+	fmt.Print(tr("\nClose them? [y/N]: "))
+
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input != "y" && input != "yes" {
+		return
+	}
+
+	for _, m := range masters {
+		if err := exitControlMaster(m.Alias); err != nil {
+			fmt.Printf("  %s %s: %v\n", failMark(), m.Alias, err)
+		} else {
+			fmt.Printf("  %s %s\n", okMark(), m.Alias)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerProvider("netbox", newNetBoxProvider)
+}
+
+// NetBoxTimeout bounds each NetBox API request
+const NetBoxTimeout = 15 * time.Second
+
+// netBoxPage is the part of a NetBox device or virtual machine list we use
+type netBoxPage struct {
+	Next    string
+	Results []struct {
+		Name      string
+		PrimaryIP *struct {
+			Address string
+		} `json:"primary_ip"`
+		Site       *netBoxRef
+		Rack       *netBoxRef
+		Cluster    *netBoxRef
+		Role       *netBoxRef
+		DeviceRole *netBoxRef `json:"device_role"` // NetBox before 3.6
+	}
+}
+
+type netBoxRef struct {
+	Name string
+}
+
+func (r *netBoxRef) name() string {
+	if r == nil {
+		return ""
+	}
+	return r.Name
+}
+
+// netBoxProvider lists NetBox devices and virtual machines that have a
+// primary IP, with their site, rack and role as metadata
+type netBoxProvider struct {
+	url   string
+	token string
+	user  string
+	match string
+	query string // extra API filters, e.g. site=par1&status=active
+	cache []SSHHost
+}
+
+// newNetBoxProvider accepts url= (required), token= (default
+// $NETBOX_TOKEN), user=, match= (alias glob) and query=
+func newNetBoxProvider(options map[string]string) (HostProvider, error) {
+	p := &netBoxProvider{token: os.Getenv("NETBOX_TOKEN")}
+	for key, value := range options {
+		switch key {
+		case "url":
+			p.url = strings.TrimSuffix(value, "/")
+		case "token":
+			p.token = value
+		case "user":
+			p.user = value
+		case "match":
+			p.match = value
+		case "query":
+			p.query = value
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), "netbox", key)
+		}
+	}
+	if p.url == "" {
+		return nil, fmt.Errorf(tr("provider %s: url= is required"), "netbox")
+	}
+	return p, nil
+}
+
+func (p *netBoxProvider) Name() string { return "netbox" }
+
+func (p *netBoxProvider) Refresh() { p.cache = nil }
+
+func (p *netBoxProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+
+	hosts := []SSHHost{}
+	for _, endpoint := range []string{"/api/dcim/devices/", "/api/virtualization/virtual-machines/"} {
+		found, err := p.list(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("netbox: %v", err)
+		}
+		hosts = append(hosts, found...)
+	}
+
+	p.cache = hosts
+	return hosts, nil
+}
+
+// list follows the pages of one NetBox list endpoint
+func (p *netBoxProvider) list(endpoint string) ([]SSHHost, error) {
+	client := &http.Client{Timeout: NetBoxTimeout}
+	next := p.url + endpoint + "?limit=1000&has_primary_ip=true"
+	if p.query != "" {
+		next += "&" + p.query
+	}
+
+	hosts := []SSHHost{}
+	for next != "" {
+		req, err := http.NewRequest(http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if p.token != "" {
+			req.Header.Set("Authorization", "Token "+p.token)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page netBoxPage
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf(tr("%s returned %s"), endpoint, resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Results {
+			if item.PrimaryIP == nil || item.Name == "" {
+				continue
+			}
+			// Addresses come with their prefix length, e.g. 10.0.0.5/24
+			ip, _, _ := strings.Cut(item.PrimaryIP.Address, "/")
+			host, ok := labHost(item.Name, ip, p.user, p.match)
+			if !ok {
+				continue
+			}
+			role := item.Role.name()
+			if role == "" {
+				role = item.DeviceRole.name()
+			}
+			host.Meta = map[string]string{
+				MetaSite:      item.Site.name(),
+				MetaRack:      item.Rack.name(),
+				MetaRole:      role,
+				MetaCluster:   item.Cluster.name(),
+				MetaPrimaryIP: item.PrimaryIP.Address,
+			}
+			hosts = append(hosts, host)
+		}
+
+		// next is absolute and already carries our filters
+		next = page.Next
+	}
+	return hosts, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const notesDir = "notes"
+
+// notesPath returns the markdown notes file of a host, which need not
+// exist
+func notesPath(alias string) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, notesDir, alias+".md"), nil
+}
+
+// hostNotes returns a host's notes, "" when it has none
+func hostNotes(alias string) string {
+	path, err := notesPath(alias)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// editNotes opens a host's notes in $VISUAL or $EDITOR (vi without
+// either), creating the file with a title when it is new
+func editNotes(alias string) error {
+	path, err := notesPath(alias)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, []byte("# "+alias+"\n\n"), 0600); err != nil {
+			return err
+		}
+	}
+
+	editor := valueOr(os.Getenv("VISUAL"), valueOr(os.Getenv("EDITOR"), "vi"))
+	// The editor may come with arguments, as in "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(tr("editor %s: %v"), editor, err)
+	}
+	return nil
+}
+
+var (
+	markdownBold = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownCode = regexp.MustCompile("`([^`]+)`")
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownList = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+`)
+)
+
+// renderMarkdown styles the markdown most notes use for the terminal:
+// headings, lists, quotes, code spans and blocks, bold and links. The
+// rest is shown as written
+func renderMarkdown(text string) []string {
+	lines := []string{}
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			lines = append(lines, "    "+colorize("36", line))
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if strings.HasPrefix(trimmed, "# ") {
+				line = colorize("1;4", heading)
+			} else {
+				line = colorize("1", heading)
+			}
+		case strings.HasPrefix(trimmed, ">"):
+			line = colorize("2", "│ "+renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))))
+		case markdownList.MatchString(line):
+			m := markdownList.FindStringSubmatch(line)
+			bullet := "•"
+			if !strings.ContainsAny(m[2], "-*+") {
+				bullet = m[2]
+			}
+			line = m[1] + "  " + bullet + " " + renderInline(line[len(m[0]):])
+		default:
+			line = renderInline(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderInline styles bold, code spans and links within a line
+func renderInline(line string) string {
+	line = markdownCode.ReplaceAllStringFunc(line, func(s string) string {
+		return colorize("36", markdownCode.FindStringSubmatch(s)[1])
+	})
+	line = markdownBold.ReplaceAllStringFunc(line, func(s string) string {
+		m := markdownBold.FindStringSubmatch(s)
+		return colorize("1", m[1]+m[2])
+	})
+	return markdownLink.ReplaceAllStringFunc(line, func(s string) string {
+		m := markdownLink.FindStringSubmatch(s)
+		return colorize("4", m[1]) + " (" + m[2] + ")"
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MaxNotifications  = 100 // older notifications are dropped
+	NotificationLines = 3   // unseen notifications shown in the menu
+)
+
+// Notification is a background event worth a line in the menu: a session
+// that ended, a forward that gave up, a config reload
+type Notification struct {
+	Time time.Time
+	Text string
+	Seen bool
+}
+
+var (
+	notifications []Notification
+	notifyMu      sync.Mutex
+)
+
+// notify records a background event for the menu's notifications area
+func notify(format string, args ...any) {
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+
+	notifications = append(notifications, Notification{Time: time.Now(), Text: fmt.Sprintf(format, args...)})
+	if len(notifications) > MaxNotifications {
+		notifications = notifications[len(notifications)-MaxNotifications:]
+	}
+	postEvent(EventSession)
+}
+
+// notificationLines describes the latest unseen notifications for the
+// menu, nil when there are none
+func notificationLines() []string {
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+
+	unseen := []Notification{}
+	for _, n := range notifications {
+		if !n.Seen {
+			unseen = append(unseen, n)
+		}
+	}
+	if len(unseen) == 0 {
+		return nil
+	}
+
+	lines := []string{}
+	for _, n := range unseen[max(0, len(unseen)-NotificationLines):] {
+		lines = append(lines, fmt.Sprintf("%s %s", n.Time.Format(time.TimeOnly), n.Text))
+	}
+	if more := len(unseen) - NotificationLines; more > 0 {
+		lines = append(lines, fmt.Sprintf(tr("%d more, N to view all"), more))
+	}
+	return lines
+}
+
+// unseenNotifications counts the notifications not viewed yet
+func unseenNotifications() int {
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+
+	count := 0
+	for _, n := range notifications {
+		if !n.Seen {
+			count++
+		}
+	}
+	return count
+}
+
+// showNotifications lists every notification, marking them seen so they
+// leave the menu; c clears the list
+func showNotifications() {
+	clearScreen()
+	printHeader(tr("Notifications"))
+
+	notifyMu.Lock()
+	for i := range notifications {
+		n := &notifications[i]
+		fmt.Printf("  %s %s\n", n.Time.Format(time.DateTime), n.Text)
+		n.Seen = true
+	}
+	count := len(notifications)
+	notifyMu.Unlock()
+
+	if count == 0 {
+		fmt.Println(tr("  none"))
+		fmt.Print(tr("\nPress Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	fmt.Print(tr("\nc clears the list, Enter goes back: "))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(input) == "c" {
+		notifyMu.Lock()
+		notifications = nil
+		notifyMu.Unlock()
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"time"
+
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+// Output subscriber names
+const (
+	SubscriberMask       = "mask"
+	SubscriberScrollback = "scrollback"
+	SubscriberState      = "state"
+	SubscriberStats      = "stats"
+	SubscriberTee        = "tee"
+	SubscriberTerminal   = "terminal"
+	SubscriberWatch      = "watch"
+	SubscriberBell       = "bell"
+	SubscriberRecord     = "record"
+	SubscriberCommand    = "command"
+	SubscriberTOTP       = "totp"
+	SubscriberRoot       = "root"
+)
+
+// OutputPipeline broadcasts a session's output; subscribers take
+// sessionsMu themselves when they need it, so the pipeline is never used
+// with sessionsMu held
+type OutputPipeline = ptysession.Pipeline
+
+// newSessionOutput returns the pipeline every session starts with
+func newSessionOutput(session *Session) *OutputPipeline {
+	p := &OutputPipeline{}
+	p.Subscribe(SubscriberMask, &session.secret)
+	p.Subscribe(SubscriberScrollback, maskedWriter{&session.secret, scrollbackWriter{session}})
+	if session.Kind == SessionShell && session.Host.TOTPSecret != "" && session.Host.TOTP == TOTPType {
+		p.Subscribe(SubscriberTOTP, &totpTyper{session: session})
+	}
+	if session.Kind == SessionShell {
+		p.Subscribe(SubscriberState, stateTracker{session})
+		p.Subscribe(SubscriberCommand, &commandTracker{session: session})
+		p.Subscribe(SubscriberRoot, rootTracker{session})
+	}
+	p.Subscribe(SubscriberStats, &session.Stats)
+	p.Subscribe(SubscriberWatch, &watchMatcher{session: session})
+	p.Subscribe(SubscriberBell, &bellTracker{session: session})
+	return p
+}
+
+// scrollbackWriter appends output to a session's scrollback
+type scrollbackWriter struct {
+	session *Session
+}
+
+func (w scrollbackWriter) Write(p []byte) (int, error) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	appendScrollback(w.session, p)
+	return len(p), nil
+}
+
+// stateTracker moves a shell session out of Connecting once ssh prints
+// something, and in and out of AuthPrompt and TouchKey as prompts come
+// and go. Nobody is attached to see ssh ask for a touch, so that is also
+// a notification
+type stateTracker struct {
+	session *Session
+}
+
+func (t stateTracker) Write(p []byte) (int, error) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	switch t.session.State {
+	case StateConnecting, StateReady, StateDetached, StateAuthPrompt, StateTouchKey:
+		if state := detachedState(t.session); state != t.session.State {
+			t.session.State = state
+			postEvent(EventSession)
+			if state == StateAuthPrompt {
+				t.session.promptSince = time.Now()
+			}
+			if state == StateTouchKey {
+				notify(tr("%s waits for a touch of your security key"), t.session.Alias)
+			}
+		}
+	}
+	return len(p), nil
+}
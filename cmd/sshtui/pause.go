@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// PauseKey (Ctrl+_, Ctrl+/ on many terminals) pauses and resumes the
+	// session output while attached; pressed twice it reaches the
+	// session, for emacs and readline undo
+	PauseKey = 0x1f
+	// MaxPausedOutput is the output held while paused; older bytes only
+	// remain in the scrollback
+	MaxPausedOutput = 1024 * 1024
+)
+
+// pausableTerminal shows output on our terminal, subscribed while
+// attached, or holds it while paused. The scrollback keeps recording
+// either way
+type pausableTerminal struct {
+	mu      sync.Mutex
+	paused  bool
+	held    []byte
+	dropped int
+}
+
+func (t *pausableTerminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.paused {
+		return os.Stdout.Write(p)
+	}
+	t.held = append(t.held, p...)
+	if over := len(t.held) - MaxPausedOutput; over > 0 {
+		t.held = t.held[over:]
+		t.dropped += over
+	}
+	return len(p), nil
+}
+
+// toggle pauses the output, or resumes it by showing what was held
+func (t *pausableTerminal) toggle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.paused {
+		t.paused = true
+		fmt.Print(tr("\r\n[sshtui] output paused, Ctrl+_ resumes (typing still goes to the session)\r\n"))
+		return
+	}
+	t.paused = false
+	if t.dropped > 0 {
+		fmt.Printf(tr("\r\n[sshtui] %s skipped, see the scrollback (v)\r\n"), formatBytes(int64(t.dropped)))
+	}
+	os.Stdout.Write(t.held)
+	t.held, t.dropped = nil, 0
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// commonPorts are probed with nc when the host has neither ss nor netstat
+var commonPorts = []int{22, 80, 443, 3000, 3306, 5000, 5432, 5601, 5672, 6379, 8000, 8080, 8443, 8888, 9000, 9090, 9200, 15672, 27017}
+
+// portNames describes well-known ports in the scan results
+var portNames = map[int]string{
+	22: "ssh", 80: "http", 443: "https", 3000: "http-dev", 3306: "mysql",
+	5000: "http-dev", 5432: "postgres", 5601: "kibana", 5672: "amqp",
+	6379: "redis", 8000: "http-alt", 8080: "http-alt", 8443: "https-alt",
+	8888: "http-alt", 9000: "http-alt", 9090: "prometheus", 9200: "elasticsearch",
+	15672: "rabbitmq", 27017: "mongodb",
+}
+
+// RemotePort is a TCP port listening on a host
+type RemotePort struct {
+	Address string // as listened on, e.g. 127.0.0.1, 0.0.0.0 or [::]
+	Port    int
+	Process string // from ss when it can tell
+}
+
+// Target is the address:port a local forward should connect to, seen
+// from the host; wildcard listeners are reached on localhost
+func (p RemotePort) Target() string {
+	switch p.Address {
+	case "0.0.0.0", "*", "[::]", "::", "":
+		return fmt.Sprintf("localhost:%d", p.Port)
+	}
+	return fmt.Sprintf("%s:%d", p.Address, p.Port)
+}
+
+// Label describes the port for a pick list
+func (p RemotePort) Label() string {
+	label := fmt.Sprintf("%s:%d", p.Address, p.Port)
+	if name := portNames[p.Port]; name != "" {
+		label += " " + name
+	}
+	if p.Process != "" {
+		label += colorize("2", " ("+p.Process+")")
+	}
+	return label
+}
+
+// remotePortScript lists listening TCP ports with ss, netstat or, as a
+// last resort, nc against commonPorts on localhost
+func remotePortScript() string {
+	ports := make([]string, len(commonPorts))
+	for i, port := range commonPorts {
+		ports[i] = strconv.Itoa(port)
+	}
+	return "ss -ltnpH 2>/dev/null || netstat -ltn 2>/dev/null || " +
+		"for p in " + strings.Join(ports, " ") + "; do nc -z -w1 127.0.0.1 $p 2>/dev/null && echo open 127.0.0.1:$p; done; true"
+}
+
+// listRemotePorts asks the host which TCP ports it listens on
+func listRemotePorts(host SSHHost) ([]RemotePort, error) {
+	if err := refreshCertificate(host); err != nil {
+		return nil, err
+	}
+	args := append([]string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5"}, buildExecArgs(host, remotePortScript())...)
+
+	var stderr bytes.Buffer
+	cmd := commander.Command(context.Background(), "ssh", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return parseListeningPorts(string(out)), nil
+}
+
+// parseListeningPorts reads ss -H, netstat or "open addr:port" lines,
+// keeping one entry per port
+func parseListeningPorts(output string) []RemotePort {
+	byPort := map[int]RemotePort{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		var local string
+		switch {
+		case len(fields) >= 2 && fields[0] == "open":
+			local = fields[1]
+		case len(fields) >= 4 && fields[0] == "LISTEN":
+			// ss: State Recv-Q Send-Q Local Peer [Process]
+			local = fields[3]
+		case len(fields) >= 6 && strings.HasPrefix(fields[0], "tcp") && fields[5] == "LISTEN":
+			// netstat: Proto Recv-Q Send-Q Local Foreign State
+			local = fields[3]
+		default:
+			continue
+		}
+
+		sep := strings.LastIndexAny(local, ":.")
+		if sep < 0 {
+			continue
+		}
+		port, err := strconv.Atoi(local[sep+1:])
+		if err != nil {
+			continue
+		}
+		p := RemotePort{Address: strings.TrimSuffix(local[:sep], "%lo"), Port: port}
+		if fields[0] == "LISTEN" && len(fields) >= 6 {
+			p.Process = ssProcess(fields[5])
+		}
+		// Prefer the IPv4 or named listener over a duplicate IPv6 one
+		if seen, ok := byPort[port]; ok && (seen.Process != "" || !strings.HasPrefix(seen.Address, "[")) {
+			continue
+		}
+		byPort[port] = p
+	}
+
+	ports := make([]RemotePort, 0, len(byPort))
+	for _, p := range byPort {
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+// ssProcess extracts the program name from ss's users:(("nginx",pid=1,fd=6))
+func ssProcess(field string) string {
+	_, rest, ok := strings.Cut(field, `(("`)
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, `"`)
+	return name
+}
+
+// pickRemotePort scans the host and lets the user choose a listening port,
+// returning a local forward to it
+func pickRemotePort(host SSHHost, reader *bufio.Reader) (*PortForward, bool) {
+	fmt.Printf(tr("Scanning listening ports on %s...\n"), host.Alias)
+	ports, err := listRemotePorts(host)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return nil, false
+	}
+	if len(ports) == 0 {
+		fmt.Print(tr("No listening TCP ports found. Press Enter..."))
+		reader.ReadString('\n')
+		return nil, false
+	}
+
+	fmt.Println(tr("\nListening ports:"))
+	for i, p := range ports {
+		fmt.Printf("  [%d] %s\n", i+1, p.Label())
+	}
+	fmt.Print(tr("\nPort number: "))
+	numStr, _ := reader.ReadString('\n')
+	var num int
+	if _, err := fmt.Sscanf(strings.TrimSpace(numStr), "%d", &num); err != nil || num < 1 || num > len(ports) {
+		fmt.Println(tr("Invalid number. Press Enter..."))
+		reader.ReadString('\n')
+		return nil, false
+	}
+	chosen := ports[num-1]
+
+	fmt.Printf(tr("Local port [%d]: "), chosen.Port)
+	localPort, _ := reader.ReadString('\n')
+	localPort = strings.TrimSpace(localPort)
+	if localPort == "" {
+		localPort = strconv.Itoa(chosen.Port)
+	}
+	if _, err := strconv.Atoi(localPort); err != nil {
+		fmt.Println(tr("Invalid forward. Press Enter..."))
+		reader.ReadString('\n')
+		return nil, false
+	}
+	return &PortForward{Type: "L", LocalPort: localPort, RemoteAddr: chosen.Target()}, true
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ProcInfo is one row of the process table
+type ProcInfo struct {
+	PID     int
+	PPID    int
+	RSSKB   int
+	CPU     string
+	Elapsed string
+	Command string
+}
+
+// listProcesses reads the process table via ps (works on Linux and macOS)
+func listProcesses() ([]ProcInfo, error) {
+	out, err := exec.Command("ps", "-ax", "-o", "pid=,ppid=,rss=,pcpu=,etime=,command=").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	procs := []ProcInfo{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		rss, _ := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		procs = append(procs, ProcInfo{
+			PID:     pid,
+			PPID:    ppid,
+			RSSKB:   rss,
+			CPU:     fields[3],
+			Elapsed: fields[4],
+			Command: strings.Join(fields[5:], " "),
+		})
+	}
+	return procs, scanner.Err()
+}
+
+// printProcessTree prints pid and its descendants, indented by depth
+func printProcessTree(procs []ProcInfo, pid int, depth int) {
+	for _, p := range procs {
+		if p.PID != pid {
+			continue
+		}
+		fmt.Printf("  %s%-7d %6.1fMB %5s%% %10s  %s\n",
+			strings.Repeat("  ", depth), p.PID, float64(p.RSSKB)/1024, p.CPU, p.Elapsed, p.Command)
+	}
+	for _, p := range procs {
+		if p.PPID == pid {
+			printProcessTree(procs, p.PID, depth+1)
+		}
+	}
+}
+
+// showSessionDetail displays a session's ssh process tree and lets the user
+// send it a specific signal
+func showSessionDetail(session *Session) {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		sessionsMu.RLock()
+		cmd := session.Cmd
+		status := coloredStatus(session)
+		tee := session.Tee
+		sessionsMu.RUnlock()
+
+		clearScreen()
+		printHeader(tr("Session: ") + session.Alias)
+
+		fmt.Printf(tr("Status:  %s\n"), status)
+		fmt.Printf(tr("Command: %s\n"), strings.Join(cmd.Args, " "))
+		if tee != nil {
+			fmt.Printf(tr("Tee:     %s\n"), tee)
+		}
+		fmt.Printf(tr("I/O:     %s out, %s in\n"), formatBytes(session.Stats.Out.Load()), formatBytes(session.Stats.In.Load()))
+		if cmd.Process == nil {
+			fmt.Println(tr("No process"))
+		} else {
+			fmt.Printf(tr("PID:     %d\n"), cmd.Process.Pid)
+			if cmd.ProcessState != nil {
+				fmt.Printf(tr("Exit:    %s\n"), cmd.ProcessState)
+			} else if procs, err := listProcesses(); err != nil {
+				fmt.Printf(tr("Error: %v\n"), err)
+			} else {
+				fmt.Println(tr("\nProcess tree (PID, RSS, CPU, elapsed, command):"))
+				printProcessTree(procs, cmd.Process.Pid, 0)
+			}
+		}
+
+		fmt.Println(tr("\nCommands:"))
+		fmt.Println(tr("  i         - Send SIGINT"))
+		fmt.Println(tr("  t         - Send SIGTERM"))
+		fmt.Println(tr("  k         - Send SIGKILL"))
+		fmt.Println(tr("  p         - Pipe output to a local command"))
+		fmt.Println(tr("  r         - Refresh"))
+		fmt.Println(tr("  q         - Back to main menu"))
+		fmt.Print("\n> ")
+
+		input, _ := reader.ReadString('\n')
+		var sig syscall.Signal
+		switch strings.TrimSpace(input) {
+		case "q":
+			return
+		case "p":
+			promptTee(session, reader)
+			continue
+		case "i":
+			sig = syscall.SIGINT
+		case "t":
+			sig = syscall.SIGTERM
+		case "k":
+			sig = syscall.SIGKILL
+		default:
+			continue
+		}
+		if refuseReadOnly(reader) {
+			continue
+		}
+
+		if cmd.Process != nil && cmd.ProcessState == nil {
+			if err := cmd.Process.Signal(sig); err != nil {
+				fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+				reader.ReadString('\n')
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// PromptContextLines is how much of a session's output the prompt
+	// screen shows above its prompt
+	PromptContextLines = 4
+	// PromptAnswerWait bounds the wait for a host to take an answer: to ask
+	// again, print something else or end
+	PromptAnswerWait = 5 * time.Second
+	// PromptSettleWait bounds the wait, after opening a workspace, for
+	// its sessions to reach their first prompt and go quiet
+	PromptSettleWait = ConnectionTimeout
+	// PromptQuietTime is how long hosts that printed something stay quiet
+	// before the prompts show: a prompt may follow a banner, or a message
+	// such as the one adding a host key
+	PromptQuietTime    = 500 * time.Millisecond
+	promptPollInterval = 100 * time.Millisecond
+)
+
+// waitingPrompts returns the detached sessions waiting at an auth prompt,
+// longest waiting first
+func waitingPrompts() []*Session {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	waiting := []*Session{}
+	for _, s := range sessions {
+		if s.State == StateAuthPrompt {
+			waiting = append(waiting, s)
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool { return waiting[i].promptSince.Before(waiting[j].promptSince) })
+	return waiting
+}
+
+// promptSummary tells in the menu how many sessions wait at a prompt, ""
+// when none does
+func promptSummary() string {
+	if n := len(waitingPrompts()); n > 0 {
+		return fmt.Sprintf(tr("Sessions waiting at a prompt: %d, P answers them one by one"), n)
+	}
+	return ""
+}
+
+// promptContext returns the last lines of a session's output as shown on
+// screen, its prompt last
+func promptContext(session *Session) []string {
+	sessionsMu.RLock()
+	tail := session.Scrollback[max(0, len(session.Scrollback)-PromptContextLines*AuthPromptMaxLen):]
+	sessionsMu.RUnlock()
+
+	lines := []string{}
+	for _, line := range bytes.Split(tail, []byte("\n")) {
+		if text := strings.TrimRight(screenText(string(line)), " \t"); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return lines[max(0, len(lines)-PromptContextLines):]
+}
+
+// relayPrompts shows the auth prompts of detached sessions one at a time,
+// in the order they came, and types the answers into them: a host asking
+// again, for a second factor or after a wrong password, comes next.
+// Answers to secret prompts are not echoed; an empty answer skips a host,
+// Esc leaves
+func relayPrompts() {
+	skipped := make(map[*Session]bool)
+	var current *Session
+	answered := false
+	for {
+		queue := []*Session{}
+		for _, s := range waitingPrompts() {
+			if !skipped[s] {
+				queue = append(queue, s)
+			}
+		}
+		if len(queue) == 0 {
+			if answered {
+				fmt.Print(tr("\nNo more prompts. Press Enter..."))
+			} else {
+				fmt.Print(tr("\nNo session waits at a prompt. Press Enter..."))
+			}
+			bufio.NewReader(os.Stdin).ReadString('\n')
+			return
+		}
+		// The host answered last goes on while it asks for more
+		session := queue[0]
+		for _, s := range queue {
+			if s == current {
+				session = s
+			}
+		}
+		current = session
+
+		clearScreen()
+		printHeader(tr("Auth prompts"), tr("Enter on an empty answer skips a host, Esc leaves"))
+		for _, s := range queue {
+			marker := "  "
+			if s == session {
+				marker = "> "
+			}
+			fmt.Printf("%s[!%d] %s\n", marker, sessionIndex(s), s.Alias)
+		}
+		fmt.Println()
+		for _, line := range promptContext(session) {
+			fmt.Println(colorize("2", "  | ") + line)
+		}
+		fmt.Println()
+
+		sessionsMu.RLock()
+		secret := authPrompt(session.Scrollback) != hostKeyPrompt
+		mark := len(session.Scrollback)
+		sessionsMu.RUnlock()
+
+		restoreTerm, err := term.MakeRaw()
+		if err != nil {
+			fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+			bufio.NewReader(os.Stdin).ReadString('\n')
+			return
+		}
+		answer, ok := readRaw(fmt.Sprintf(tr("%s answer: "), session.Alias), !secret)
+		restoreTerm()
+		if !ok {
+			return
+		}
+		if answer == "" {
+			skipped[session] = true
+			continue
+		}
+		if secret {
+			// Like keys typed attached, an echo of the secret stays out
+			// of the captures
+			session.secret.state.Store(maskEnter)
+		}
+		if _, err := session.writeInput([]byte(answer + "\r")); err != nil {
+			skipped[session] = true
+			continue
+		}
+		answered = true
+		waitForAnswer(session, mark)
+	}
+}
+
+// waitForAnswer waits for a session to take an answer typed at its prompt
+// with mark bytes of scrollback: until it asks again, ends, or prints
+// something and goes quiet, at most PromptAnswerWait
+func waitForAnswer(session *Session, mark int) {
+	fmt.Printf(tr("Waiting for %s...\n"), session.Alias)
+	size, changed := mark, time.Now()
+	for deadline := time.Now().Add(PromptAnswerWait); time.Now().Before(deadline); {
+		time.Sleep(promptPollInterval)
+		sessionsMu.RLock()
+		running, prompting := session.running(), session.State == StateAuthPrompt
+		if len(session.Scrollback) != size {
+			size, changed = len(session.Scrollback), time.Now()
+		}
+		sessionsMu.RUnlock()
+		if !running || (size > mark && (prompting || time.Since(changed) > PromptQuietTime)) {
+			return
+		}
+	}
+}
+
+// offerPromptRelay waits for the sessions just opened to connect and go
+// quiet and, when some of them wait at a prompt, offers to answer them; it
+// tells whether the prompt screen was shown
+func offerPromptRelay(opened []*Session) bool {
+	size, changed := 0, time.Now()
+	for deadline := time.Now().Add(PromptSettleWait); time.Now().Before(deadline); {
+		connecting, total := false, 0
+		sessionsMu.RLock()
+		for _, s := range opened {
+			connecting = connecting || s.State == StateConnecting
+			total += len(s.Scrollback)
+		}
+		sessionsMu.RUnlock()
+		if total != size {
+			size, changed = total, time.Now()
+		}
+		if !connecting && time.Since(changed) > PromptQuietTime {
+			break
+		}
+		time.Sleep(promptPollInterval)
+	}
+
+	n := len(waitingPrompts())
+	if n == 0 {
+		return false
+	}
+	fmt.Printf(tr("\nSessions waiting at a prompt: %d. Answer them now? [Y/n]: "), n)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "" && answer != "y" && answer != "yes" {
+		return false
+	}
+	relayPrompts()
+	return true
+}
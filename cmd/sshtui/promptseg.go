@@ -0,0 +1,88 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultPromptPattern recognizes the usual user@host prompts, such as
+// "user@web1:~/app$ " and "[root@db1 ~]# ", when no PromptPattern is set
+const DefaultPromptPattern = `^\[?[\w.-]+@[\w.-]+[: ][^$#]*[$#] ?`
+
+var defaultPrompt = regexp.MustCompile(DefaultPromptPattern)
+
+// semanticPrompt finds OSC 133 prompt marks: A starts the prompt, B the
+// command typed after it
+var semanticPrompt = regexp.MustCompile(`\x1b\]133;([AB])[^\x07\x1b]*(\x07|\x1b\\)`)
+
+// CommandBlock is a command typed at a shell prompt and the output that
+// follows it, in lines of scrollback
+type CommandBlock struct {
+	Prompt  int    // line of the prompt
+	Command string // as typed, "" for an empty prompt
+	End     int    // first line after the output: the next prompt or the end
+}
+
+// Output is the number of output lines of the block
+func (b CommandBlock) Output() int {
+	return b.End - b.Prompt - 1
+}
+
+// segmentScrollback splits scrollback lines into command blocks at each
+// prompt: a line with OSC 133 marks, or else one matching pattern. Lines
+// before the first prompt belong to no block
+func segmentScrollback(lines []string, pattern *regexp.Regexp) []CommandBlock {
+	if pattern == nil {
+		pattern = defaultPrompt
+	}
+	blocks := []CommandBlock{}
+	for i, line := range lines {
+		command, ok := promptCommand(line, pattern)
+		if !ok {
+			continue
+		}
+		if n := len(blocks); n > 0 {
+			blocks[n-1].End = i
+		}
+		blocks = append(blocks, CommandBlock{Prompt: i, Command: command, End: len(lines)})
+	}
+	return blocks
+}
+
+// promptCommand tells whether line is a prompt and returns the command
+// typed after it
+func promptCommand(line string, pattern *regexp.Regexp) (string, bool) {
+	if marks := semanticPrompt.FindAllStringSubmatchIndex(line, -1); marks != nil {
+		last := marks[len(marks)-1]
+		if line[last[2]:last[3]] != "B" {
+			// The prompt is drawn but nothing was typed yet
+			return "", true
+		}
+		return screenText(line[last[1]:]), true
+	}
+	text := screenText(line)
+	loc := pattern.FindStringIndex(text)
+	if loc == nil {
+		return "", false
+	}
+	return strings.TrimSpace(text[loc[1]:]), true
+}
+
+// screenText is what a line of output shows: escape sequences removed,
+// and only what follows the last carriage return, which redraws the line
+func screenText(line string) string {
+	line = strings.TrimRight(escapePattern.ReplaceAllString(line, ""), "\r")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return line
+}
+
+// blockOutput is the text of a block's output, as shown on screen
+func blockOutput(lines []string, block CommandBlock) string {
+	output := []string{}
+	for _, line := range lines[block.Prompt+1 : block.End] {
+		output = append(output, screenText(line))
+	}
+	return strings.Join(output, "\n")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Where an effective ssh setting comes from, closest to the host first
+const (
+	SettingSSHTUI  = "sshtui"     // options, forwards and target sshtui adds
+	SettingBlock   = "host block" // the host's own block in ~/.ssh/config
+	SettingShared  = "shared"     // wildcard Host or Match blocks, Include, /etc/ssh/ssh_config
+	SettingDefault = "default"    // built into ssh
+)
+
+// settingColors tells the sources apart in the listing
+var settingColors = map[string]string{
+	SettingSSHTUI: "33",
+	SettingBlock:  "32",
+	SettingShared: "36",
+}
+
+// settingLabel names a source in the listing
+func settingLabel(source string) string {
+	switch source {
+	case SettingBlock:
+		return tr("host block")
+	case SettingShared:
+		return tr("shared")
+	}
+	return source
+}
+
+// SSHSetting is one line of ssh -G: a keyword with its values, several
+// for keywords given more than once, and where it comes from
+type SSHSetting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// resolveSettings runs ssh -G with args and returns its keywords in order
+// with their values
+func resolveSettings(args ...string) ([]string, map[string]string, error) {
+	out, err := commander.Command(context.Background(), "ssh", append([]string{"-G"}, args...)...).Output()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := []string{}
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		if _, seen := values[key]; seen {
+			values[key] += ", " + value
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = value
+	}
+	return keys, values, scanner.Err()
+}
+
+// settingSources resolves the host as sshtui connects to it, then as
+// plain ssh would and with no config at all: a setting differing from
+// plain ssh is sshtui's doing, one differing from no config comes from
+// the host's block when it sets the keyword, else from shared blocks
+func settingSources(host SSHHost) ([]SSHSetting, error) {
+	if !sshClient.Supports(FeatureResolve) {
+		return nil, fmt.Errorf(tr("%s lacks %s"), sshClient.Version, FeatureResolve)
+	}
+	keys, effective, err := resolveSettings(buildSSHArgs(host)...)
+	if err != nil {
+		return nil, err
+	}
+	_, plain, err := resolveSettings(host.Alias)
+	if err != nil {
+		return nil, err
+	}
+	_, defaults, err := resolveSettings("-F", "/dev/null", host.Alias)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := []SSHSetting{}
+	for _, key := range keys {
+		setting := SSHSetting{Key: key, Value: effective[key]}
+		switch {
+		case effective[key] != plain[key]:
+			setting.Source = SettingSSHTUI
+		case slices.Contains(host.Keywords, key):
+			setting.Source = SettingBlock
+		case effective[key] != defaults[key]:
+			setting.Source = SettingShared
+		default:
+			setting.Source = SettingDefault
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// printSettingSources lists the settings ssh uses for host that are not
+// its defaults, colored by where each comes from
+func printSettingSources(host SSHHost) {
+	settings, err := settingSources(host)
+	if err != nil {
+		fmt.Printf(tr("\nssh -G failed: %v\n"), err)
+		return
+	}
+
+	fmt.Printf(tr("\nSSH settings (%s, %s, %s; defaults left out):\n"),
+		colorize(settingColors[SettingBlock], settingLabel(SettingBlock)),
+		colorize(settingColors[SettingShared], tr("shared: patterns, Match, Include")),
+		colorize(settingColors[SettingSSHTUI], tr("added by sshtui")))
+	for _, s := range settings {
+		if s.Source == SettingDefault {
+			continue
+		}
+		line := fmt.Sprintf("  %-14s %-24s %s", settingLabel(s.Source), s.Key, s.Value)
+		fmt.Println(colorize(settingColors[s.Source], line))
+	}
+}
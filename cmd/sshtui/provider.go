@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// sshConfigSource is the provider name of hosts from ~/.ssh/config
+const sshConfigSource = "ssh"
+
+// Inventory metadata keys with a place in host details; providers may
+// add others
+const (
+	MetaSite      = "site"
+	MetaRack      = "rack"
+	MetaRole      = "role"
+	MetaCluster   = "cluster"
+	MetaPrimaryIP = "primary_ip"
+)
+
+// metaOrder is the display order of known metadata keys
+var metaOrder = []string{MetaSite, MetaRack, MetaRole, MetaCluster, MetaPrimaryIP}
+
+// metaTagKeys become tags, as site:par1 or role:db, so the host filter
+// and Tag blocks can use them
+var metaTagKeys = []string{MetaSite, MetaRole}
+
+// metaKeys returns the keys of host's non-empty metadata, known keys first
+func metaKeys(host SSHHost) []string {
+	keys := []string{}
+	for _, key := range metaOrder {
+		if host.Meta[key] != "" {
+			keys = append(keys, key)
+		}
+	}
+	others := []string{}
+	for key, value := range host.Meta {
+		if value != "" && !slices.Contains(metaOrder, key) {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	return append(keys, others...)
+}
+
+// metaTags returns the tags derived from host's metadata
+func metaTags(host SSHHost) []string {
+	tags := []string{}
+	for _, key := range metaTagKeys {
+		if value := host.Meta[key]; value != "" {
+			tags = append(tags, key+":"+strings.ReplaceAll(value, " ", "-"))
+		}
+	}
+	return tags
+}
+
+// HostProvider is an inventory source contributing connectable hosts
+type HostProvider interface {
+	Name() string
+	// Load returns the provider's hosts, possibly from a cache
+	Load() ([]SSHHost, error)
+	// Refresh drops cached data so the next Load fetches it again
+	Refresh()
+}
+
+// ProviderFactory builds a built-in provider from the key=value options
+// of its Provider line
+type ProviderFactory func(options map[string]string) (HostProvider, error)
+
+// builtinProviders are the providers a Provider line can enable by name
+var builtinProviders = make(map[string]ProviderFactory)
+
+// registerProvider makes a built-in provider available to the config
+func registerProvider(name string, factory ProviderFactory) {
+	builtinProviders[name] = factory
+}
+
+// newProvider builds the provider for a Provider line: a built-in one by
+// name, or a script provider running the arguments
+func newProvider(settings ProviderSettings) (HostProvider, error) {
+	factory, ok := builtinProviders[settings.Name]
+	if !ok {
+		if len(settings.Args) == 0 {
+			return nil, fmt.Errorf(tr("unknown provider %s (add a command for a script provider)"), settings.Name)
+		}
+		return &scriptProvider{name: settings.Name, command: settings.Args}, nil
+	}
+
+	options := make(map[string]string)
+	for _, arg := range settings.Args {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			return nil, fmt.Errorf(tr("provider %s: expected key=value, got %q"), settings.Name, arg)
+		}
+		options[strings.ToLower(key)] = value
+	}
+	return factory(options)
+}
+
+// sshConfigProvider reads ~/.ssh/config; it is always enabled
+type sshConfigProvider struct{}
+
+func (sshConfigProvider) Name() string             { return sshConfigSource }
+func (sshConfigProvider) Load() ([]SSHHost, error) { return parseSSHConfig() }
+func (sshConfigProvider) Refresh()                 {}
+
+// scriptProvider runs a command printing one host per line:
+// alias [user@]hostname[:port] [key=value metadata...]
+type scriptProvider struct {
+	name    string
+	command []string
+	cache   []SSHHost
+}
+
+func (p *scriptProvider) Name() string { return p.name }
+
+func (p *scriptProvider) Refresh() { p.cache = nil }
+
+func (p *scriptProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(expandHome(p.command[0]), p.command[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", p.name, msg)
+		}
+		return nil, fmt.Errorf("%s: %v", p.name, err)
+	}
+
+	hosts := []SSHHost{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		host := SSHHost{Alias: fields[0], HostName: fields[0]}
+		if len(fields) > 1 && !strings.Contains(fields[1], "=") {
+			host.User, host.HostName, host.Port = parseTarget(fields[1])
+			fields = fields[1:]
+		}
+		for _, field := range fields[1:] {
+			if key, value, found := strings.Cut(field, "="); found {
+				if host.Meta == nil {
+					host.Meta = make(map[string]string)
+				}
+				host.Meta[strings.ToLower(key)] = value
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	p.cache = hosts
+	return hosts, scanner.Err()
+}
+
+// parseTarget splits [user@]hostname[:port]
+func parseTarget(target string) (user, hostname, port string) {
+	if i := strings.Index(target, "@"); i >= 0 {
+		user, target = target[:i], target[i+1:]
+	}
+	// Leave bare IPv6 addresses alone
+	if i := strings.LastIndex(target, ":"); i >= 0 && strings.Count(target, ":") == 1 {
+		target, port = target[:i], target[i+1:]
+	}
+	return user, target, port
+}
+
+// builtProviders are the providers built for Provider lines, by line, so
+// their caches and state outlive a reload that leaves the line as it is
+var builtProviders = make(map[string]HostProvider)
+
+// providerKey identifies a Provider line
+func providerKey(settings ProviderSettings) string {
+	return strings.Join(append([]string{settings.Name}, settings.Args...), "\x00")
+}
+
+// activeProviders returns ssh config and the managed hosts followed by the
+// providers enabled in cfg, reusing those built for the same lines before.
+// It also returns the providers that could not be built
+func activeProviders(cfg AppConfig) ([]HostProvider, []string) {
+	providers := []HostProvider{sshConfigProvider{}}
+	if cfg.ManagedHosts {
+		providers = append(providers, managedProvider{})
+	} else {
+		managedWarning = ""
+	}
+
+	built := make(map[string]HostProvider)
+	failures := []string{}
+	for _, settings := range cfg.Providers {
+		key := providerKey(settings)
+		p, ok := builtProviders[key]
+		if !ok {
+			var err error
+			if p, err = newProvider(settings); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+		}
+		built[key] = p
+		providers = append(providers, p)
+	}
+	// Providers of removed or changed lines go
+	builtProviders = built
+	return providers, failures
+}
+
+// providerErrors holds the failures of the last load, shown in the menu
+var providerErrors []string
+
+// loadHosts merges the hosts of all active providers; an alias already
+// provided by an earlier provider wins, taking the metadata it lacks from
+// the later ones. Only an ssh config error is fatal, other providers that
+// fail are skipped
+func loadHosts(cfg AppConfig, refresh bool) ([]SSHHost, error) {
+	hosts := []SSHHost{}
+	seen := make(map[string]int) // alias -> position in hosts
+	providers, failures := activeProviders(cfg)
+	providerErrors = failures
+
+	for _, p := range providers {
+		if refresh {
+			p.Refresh()
+		}
+		provided, err := p.Load()
+		if err != nil && p.Name() == sshConfigSource {
+			return nil, err
+		}
+		if err != nil {
+			providerErrors = append(providerErrors, err.Error())
+			continue
+		}
+		for _, host := range provided {
+			if i, ok := seen[host.Alias]; ok {
+				hosts[i].Meta = mergeMeta(hosts[i].Meta, host.Meta)
+				continue
+			}
+			seen[host.Alias] = len(hosts)
+			host.Source = p.Name()
+			hosts = append(hosts, host)
+		}
+	}
+	return applyHostSettings(hosts, cfg), nil
+}
+
+// mergeMeta adds the keys of extra that meta lacks
+func mergeMeta(meta, extra map[string]string) map[string]string {
+	for key, value := range extra {
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		if meta[key] == "" {
+			meta[key] = value
+		}
+	}
+	return meta
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestActiveProvidersKeepsBuiltOnes(t *testing.T) {
+	t.Cleanup(func() { builtProviders = make(map[string]HostProvider) })
+	inventory := ProviderSettings{Name: "inv", Args: []string{"echo", "web1"}}
+
+	first, failures := activeProviders(AppConfig{Providers: []ProviderSettings{inventory}})
+	if len(first) != 2 || len(failures) != 0 {
+		t.Fatalf("got %d providers and %q, want ssh config and inv", len(first), failures)
+	}
+	again, _ := activeProviders(AppConfig{Providers: []ProviderSettings{inventory}})
+	if again[1] != first[1] {
+		t.Error("an unchanged Provider line built a new provider")
+	}
+
+	inventory.Args = []string{"echo", "web2"}
+	changed, _ := activeProviders(AppConfig{Providers: []ProviderSettings{inventory}})
+	if changed[1] == first[1] {
+		t.Error("a changed Provider line kept its old provider")
+	}
+	if len(builtProviders) != 1 {
+		t.Errorf("%d providers kept, want the one in use", len(builtProviders))
+	}
+}
+
+func TestActiveProvidersReportsFailures(t *testing.T) {
+	t.Cleanup(func() { builtProviders = make(map[string]HostProvider) })
+
+	providers, failures := activeProviders(AppConfig{Providers: []ProviderSettings{{Name: "nosuch"}}})
+	if len(providers) != 1 || len(failures) != 1 {
+		t.Errorf("got %d providers and %q, want ssh config and one failure", len(providers), failures)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerProvider("list", newListProvider)
+}
+
+// MaxRangeExpansion bounds the names one range pattern expands to
+const MaxRangeExpansion = 1000
+
+// numericRange finds [01-12] in a name pattern
+var numericRange = regexp.MustCompile(`\[(\d+)-(\d+)\]`)
+
+// expandRanges expands the numeric ranges of pattern, as in
+// web[01-12].example.com, into every name it stands for, in order. A
+// start with leading zeros keeps its width. Several ranges combine
+func expandRanges(pattern string) ([]string, error) {
+	loc := numericRange.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+	from, to := pattern[loc[2]:loc[3]], pattern[loc[4]:loc[5]]
+	start, err1 := strconv.Atoi(from)
+	end, err2 := strconv.Atoi(to)
+	if err1 != nil || err2 != nil || start > end {
+		return nil, fmt.Errorf(tr("invalid range [%s-%s] in %s"), from, to, pattern)
+	}
+	width := 0
+	if len(from) > 1 && from[0] == '0' {
+		width = len(from)
+	}
+
+	// Expand the rest first, then put each number in front of it
+	rest, err := expandRanges(pattern[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+	if (end-start+1)*len(rest) > MaxRangeExpansion {
+		return nil, fmt.Errorf(tr("%s expands to more than %d names"), pattern, MaxRangeExpansion)
+	}
+	names := []string{}
+	for n := start; n <= end; n++ {
+		for _, suffix := range rest {
+			names = append(names, fmt.Sprintf("%s%0*d%s", pattern[:loc[0]], width, n, suffix))
+		}
+	}
+	return names, nil
+}
+
+// listProvider offers hosts written in the sshtui config, with numeric
+// ranges for numbered fleets
+type listProvider struct {
+	names []string
+	user  string
+	port  string
+	short bool // alias is the first label of the name
+}
+
+// newListProvider accepts hosts= (comma-separated names with ranges,
+// required), user=, port= and short=yes
+func newListProvider(options map[string]string) (HostProvider, error) {
+	p := &listProvider{}
+	for key, value := range options {
+		switch key {
+		case "hosts":
+			for _, pattern := range strings.Split(value, ",") {
+				names, err := expandRanges(strings.TrimSpace(pattern))
+				if err != nil {
+					return nil, fmt.Errorf(tr("provider %s: %v"), "list", err)
+				}
+				p.names = append(p.names, names...)
+			}
+		case "user":
+			p.user = value
+		case "port":
+			p.port = value
+		case "short":
+			p.short = value == "yes"
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), "list", key)
+		}
+	}
+	if len(p.names) == 0 {
+		return nil, fmt.Errorf(tr("provider %s: hosts= is required"), "list")
+	}
+	return p, nil
+}
+
+func (p *listProvider) Name() string { return "list" }
+
+func (p *listProvider) Refresh() {}
+
+func (p *listProvider) Load() ([]SSHHost, error) {
+	hosts := []SSHHost{}
+	for _, name := range p.names {
+		alias := name
+		if p.short {
+			alias, _, _ = strings.Cut(name, ".")
+		}
+		hosts = append(hosts, SSHHost{Alias: alias, HostName: name, User: p.user, Port: p.port})
+	}
+	return hosts, nil
+}
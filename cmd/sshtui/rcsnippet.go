@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// rcPrelude starts the temporary rc file: it removes itself, then reads
+// the login files as the login shell it stands in for would
+const rcPrelude = `rm -rf "$SSHTUI_RC"; unset SSHTUI_RC
+[ -f /etc/profile ] && . /etc/profile
+for f in ~/.bash_profile ~/.bash_login ~/.profile; do
+  [ -f "$f" ] && { . "$f"; break; }
+done
+unset f
+`
+
+// rcCommand is the remote command of an interactive session running
+// snippet, and the shell integration when asked: an interactive bash
+// reads them from a temporary rc file, so the remote dotfiles stay as
+// they are. Without bash, or a writable temporary directory, the session
+// gets the usual login shell
+func rcCommand(snippet string, integration bool) string {
+	if integration {
+		// After the snippet, which may set PS1
+		snippet += "\n" + shellIntegrationRc
+	}
+	rc := base64.StdEncoding.EncodeToString([]byte(rcPrelude + snippet + "\n"))
+	script := fmt.Sprintf(`d=$(mktemp -d 2>/dev/null) && command -v bash >/dev/null 2>&1 &&
+printf %%s %s | { base64 -d 2>/dev/null || base64 -D; } >"$d/rc" &&
+SSHTUI_RC=$d exec bash --rcfile "$d/rc" -i
+[ -n "$d" ] && rm -rf "$d"
+exec "${SHELL:-sh}" -l`, rc)
+	// The login shell may not be sh-compatible (fish, csh)
+	return "exec sh -c " + shellQuote(script)
+}
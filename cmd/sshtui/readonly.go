@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// readOnly is set by --read-only: sessions, scrollback, history and
+// host health can be looked at, but nothing connects, attaches or sends
+// anything to a host. The latency order sorts by the probes made before,
+// and the health watch and dashboards do not poll
+var readOnly bool
+
+// readOnlyAllowed tells whether a main menu command only looks at state
+func readOnlyAllowed(input string) bool {
+	switch input {
+	case "", "v", "n", "N", "T", "F", "L", "M", "E", "f", "r", "s", ">", "<":
+		return true
+	}
+	var num int
+	for _, format := range []string{"h%d", "i!%d", "~%d"} {
+		if _, err := fmt.Sscanf(input, format, &num); err == nil {
+			return true
+		}
+	}
+	return strings.HasPrefix(input, "s ")
+}
+
+// refuseReadOnly tells the user a command is disabled in read-only mode
+// and returns true, or returns false outside of it
+func refuseReadOnly(reader *bufio.Reader) bool {
+	if !readOnly {
+		return false
+	}
+	fmt.Print(tr("Disabled in read-only mode. Press Enter..."))
+	reader.ReadString('\n')
+	return true
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestReadOnlyAllowed(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"v", true},
+		{"~2", true},
+		{"h3", true},
+		{"i!1", true},
+		{"s web", true},
+		{"L", true},
+		{"t3", false},
+		{"!1", false},
+		{"3", false},
+		{"m", false},
+		{"x", false},
+	}
+	for _, tt := range tests {
+		if got := readOnlyAllowed(tt.input); got != tt.want {
+			t.Errorf("readOnlyAllowed(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
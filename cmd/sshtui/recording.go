@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRegulatedTags record sessions and ask for a reason unless a Tag
+// block says otherwise
+var defaultRegulatedTags = []string{"regulated"}
+
+// tagPolicy reads a Record or Reason setting ("yes", "no" or unset) of tag
+func tagPolicy(tag, setting string) bool {
+	if setting != "" {
+		return setting == "yes"
+	}
+	for _, t := range defaultRegulatedTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTag returns the first tag of host whose sessions are recorded, or ""
+func recordTag(host SSHHost) string {
+	for _, tag := range host.Tags {
+		if tagPolicy(tag, appConfig.Tags[tag].Record) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// reasonTag returns the first tag of host requiring a ticket or reason
+// before connecting, or ""
+func reasonTag(host SSHHost) string {
+	for _, tag := range host.Tags {
+		if tagPolicy(tag, appConfig.Tags[tag].Reason) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// askReason asks for the ticket or reason of connecting to hosts; an
+// empty answer cancels
+func askReason(aliases []string, tag string) (string, bool) {
+	fmt.Printf(tr("\n%s tagged %q: ticket or reason (empty cancels): "), strings.Join(aliases, ", "), tag)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	reason := strings.TrimSpace(input)
+	return reason, reason != ""
+}
+
+// auditConnect logs a connection to a host with a recorded or reasoned
+// tag, along with its recording
+func auditConnect(host SSHHost, reason string, session *Session, err error) {
+	if recordTag(host) == "" && reasonTag(host) == "" {
+		return
+	}
+	entry := AuditEntry{Time: time.Now(), Action: "connect", Host: host.Alias, User: effectiveUser(host), Reason: reason}
+	if session != nil && session.Recorder != nil {
+		entry.Recording = session.Recorder.Path
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := writeAudit(entry); err != nil {
+		fmt.Printf(tr("Warning: audit log: %v\n"), err)
+	}
+}
+
+// askMultiReason asks once for the reason of running command on the
+// hosts that require one, dropping them if none is given, and logs the
+// run on recorded or reasoned hosts
+func askMultiReason(hosts []SSHHost, command string) []SSHHost {
+	needing := []string{}
+	tag := ""
+	for _, host := range hosts {
+		if t := reasonTag(host); t != "" {
+			needing = append(needing, host.Alias)
+			tag = valueOr(tag, t)
+		}
+	}
+
+	reason := ""
+	if len(needing) > 0 {
+		var ok bool
+		if reason, ok = askReason(needing, tag); !ok {
+			kept := []SSHHost{}
+			for _, host := range hosts {
+				if reasonTag(host) == "" {
+					kept = append(kept, host)
+				}
+			}
+			fmt.Printf(tr("Skipping %s\n"), strings.Join(needing, ", "))
+			hosts = kept
+		}
+	}
+
+	entries := []AuditEntry{}
+	for _, host := range hosts {
+		if recordTag(host) != "" || reasonTag(host) != "" {
+			entries = append(entries, AuditEntry{Time: time.Now(), Action: "multi", Host: host.Alias, User: effectiveUser(host), Reason: reason, Command: command})
+		}
+	}
+	if len(entries) > 0 {
+		if err := writeAudit(entries...); err != nil {
+			fmt.Printf(tr("Warning: audit log: %v\n"), err)
+		}
+	}
+	return hosts
+}
+
+// AuditEntry is one line of audit.log
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"` // "connect" or "multi"
+	Host      string    `json:"host"`
+	User      string    `json:"user,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Recording string    `json:"recording,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// writeAudit appends entries to ~/.config/sshtui/audit.log, one JSON
+// object per line; the log is only ever appended to
+func writeAudit(entries ...AuditEntry) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SessionRecorder writes a session's output and typed input as an
+// asciicast v2 file, playable with asciinema play
+type SessionRecorder struct {
+	Path  string
+	mu    sync.Mutex
+	file  *os.File
+	start time.Time
+}
+
+// startRecording creates ~/.config/sshtui/recordings/<alias>-<time>.cast
+func startRecording(alias string) (*SessionRecorder, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, "recordings")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.cast", alias, start.Format("20060102-150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := ptySize()
+	header, _ := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     ws.Cols,
+		"height":    ws.Rows,
+		"timestamp": start.Unix(),
+		"title":     alias,
+	})
+	if _, err := file.Write(append(header, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &SessionRecorder{Path: path, file: file, start: start}, nil
+}
+
+// event appends an "o" (output) or "i" (input) event
+func (r *SessionRecorder) event(kind string, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	line, _ := json.Marshal([]any{time.Since(r.start).Seconds(), kind, string(p)})
+	r.file.Write(append(line, '\n'))
+}
+
+// Write records session output; the recorder subscribes to the pipeline
+func (r *SessionRecorder) Write(p []byte) (int, error) {
+	r.event("o", p)
+	return len(p), nil
+}
+
+// Input records what was typed into the session
+func (r *SessionRecorder) Input(p []byte) {
+	r.event("i", p)
+}
+
+// Close ends the recording; later events are dropped
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
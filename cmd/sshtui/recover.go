@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// RecoveryInterval is how often the running sessions are written down
+	// for the next launch to find, should sshtui crash
+	RecoveryInterval = 10 * time.Second
+	recoveryDir      = "recovery"
+)
+
+// RecoveryState is what a running sshtui leaves behind in case it dies:
+// itself and its sessions, each process named by PID and start time as
+// PIDs get reused
+type RecoveryState struct {
+	PID      int
+	Started  string
+	Saved    time.Time
+	Sessions []RecoverySession
+}
+
+// RecoverySession is a session as written down for crash recovery; the
+// captures point to where its output went besides the scrollback
+type RecoverySession struct {
+	ID        int
+	Alias     string
+	Kind      string
+	Forwards  string `json:",omitempty"`
+	PID       int
+	Started   string
+	Args      []string
+	Recording string `json:",omitempty"`
+	DebugLog  string `json:",omitempty"`
+}
+
+// processStart returns when pid started as ps prints it, "" when it is
+// not running
+func processStart(pid int) string {
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// stillRunning tells whether pid is the process that started at started
+func stillRunning(pid int, started string) bool {
+	return pid > 0 && started != "" && processStart(pid) == started
+}
+
+// recoveryPath returns this sshtui's recovery file; one per process, so
+// several can run side by side
+func recoveryPath(pid int) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, recoveryDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%d.json", pid)), nil
+}
+
+// recoverySnapshot describes the running sessions
+func recoverySnapshot(self RecoveryState) RecoveryState {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	for _, s := range sessions {
+		if s.Cmd == nil || s.Cmd.Process == nil || !s.running() {
+			continue
+		}
+		entry := RecoverySession{
+			ID:       s.ID,
+			Alias:    s.Alias,
+			Kind:     s.Kind,
+			Forwards: displayForwards(s.Forwards),
+			PID:      s.Cmd.Process.Pid,
+			Args:     s.Args,
+			DebugLog: s.DebugLog,
+		}
+		if s.Recorder != nil {
+			entry.Recording = s.Recorder.Path
+		}
+		self.Sessions = append(self.Sessions, entry)
+	}
+	return self
+}
+
+// startRecoveryState writes the sessions down every RecoveryInterval, and
+// looks up their start times once, until the returned function is
+// called on a clean exit, which removes the file
+func startRecoveryState() func() {
+	self := RecoveryState{PID: os.Getpid()}
+	self.Started = processStart(self.PID)
+	path, err := recoveryPath(self.PID)
+	if err != nil || self.Started == "" {
+		return func() {}
+	}
+
+	started := map[int]string{}
+	var last []byte
+	save := func() {
+		state := recoverySnapshot(self)
+		for i, s := range state.Sessions {
+			if _, ok := started[s.PID]; !ok {
+				started[s.PID] = processStart(s.PID)
+			}
+			state.Sessions[i].Started = started[s.PID]
+		}
+		data, err := json.MarshalIndent(state.Sessions, "", "  ")
+		if err != nil || bytes.Equal(data, last) {
+			return
+		}
+		last = data
+		state.Saved = time.Now()
+		if data, err = json.MarshalIndent(state, "", "  "); err == nil {
+			os.WriteFile(path, data, 0600)
+		}
+	}
+
+	save()
+	done := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(RecoveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				save()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		os.Remove(path)
+	}
+}
+
+// orphanedSessions reads what sshtuis that did not exit cleanly left
+// behind and returns their sessions whose ssh still runs. The files of
+// the dead sshtuis are removed: each orphan is reported once
+func orphanedSessions() []RecoverySession {
+	dir, err := stateDir()
+	if err != nil {
+		return nil
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, recoveryDir, "*.json"))
+	orphans := []RecoverySession{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var state RecoveryState
+		if err := json.Unmarshal(data, &state); err != nil {
+			os.Remove(file)
+			continue
+		}
+		if state.PID == os.Getpid() || stillRunning(state.PID, state.Started) {
+			// Another sshtui still looks after these
+			continue
+		}
+		for _, s := range state.Sessions {
+			if stillRunning(s.PID, s.Started) {
+				orphans = append(orphans, s)
+			}
+		}
+		os.Remove(file)
+	}
+	return orphans
+}
+
+// reportOrphans lists the ssh processes a crashed sshtui left running and
+// offers to end them; they cannot be attached to again, their terminal
+// died with it
+func reportOrphans() {
+	orphans := orphanedSessions()
+	if len(orphans) == 0 {
+		return
+	}
+
+	fmt.Println(tr("A previous sshtui did not exit cleanly; its ssh processes still run:"))
+	for i, s := range orphans {
+		fmt.Printf(tr("  [%d] %s, pid %d, started %s%s\n"), i+1, s.Alias, s.PID, s.Started, s.Forwards)
+		if s.Recording != "" {
+			fmt.Printf(tr("      recording: %s\n"), s.Recording)
+		}
+		if s.DebugLog != "" {
+			fmt.Printf(tr("      debug log: %s\n"), s.DebugLog)
+		}
+	}
+	notify(tr("%d ssh processes left by a crashed sshtui"), len(orphans))
+	if readOnly {
+		fmt.Print(tr("Press Enter to continue..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	fmt.Print(tr("End them? [k]ill, Enter leaves them running: "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != "k" {
+		return
+	}
+	for _, s := range orphans {
+		// Checked again: the PID may have been reused since
+		if !stillRunning(s.PID, s.Started) {
+			continue
+		}
+		if err := syscall.Kill(s.PID, syscall.SIGTERM); err != nil {
+			fmt.Printf(tr("  %s (pid %d): %v\n"), s.Alias, s.PID, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// diffHosts describes added, removed and modified hosts between two parses
+func diffHosts(oldHosts, newHosts []SSHHost) []string {
+	changes := []string{}
+
+	oldByAlias := make(map[string]SSHHost)
+	for _, h := range oldHosts {
+		oldByAlias[h.Alias] = h
+	}
+	newByAlias := make(map[string]bool)
+
+	for _, h := range newHosts {
+		newByAlias[h.Alias] = true
+		old, ok := oldByAlias[h.Alias]
+		if !ok {
+			changes = append(changes, colorize("32", "+ "+h.Alias+hostSummary(h)))
+			continue
+		}
+
+		fields := []struct{ name, before, after string }{
+			{"HostName", old.HostName, h.HostName},
+			{"User", effectiveUser(old), effectiveUser(h)},
+			{"Port", old.Port, h.Port},
+			{"Forwards", strings.TrimSpace(displayForwards(old.Forwards)), strings.TrimSpace(displayForwards(h.Forwards))},
+			{"Tags", strings.Join(old.Tags, " "), strings.Join(h.Tags, " ")},
+			{"Source", old.Source, h.Source},
+			{"Status", old.Status, h.Status},
+			{"RemoteCommand", old.RemoteCommand, h.RemoteCommand},
+			{"Command", old.Command, h.Command},
+		}
+		for _, f := range fields {
+			if f.before != f.after {
+				changes = append(changes, colorize("33", fmt.Sprintf("~ %s: %s %q → %q", h.Alias, f.name, f.before, f.after)))
+			}
+		}
+	}
+
+	for _, h := range oldHosts {
+		if !newByAlias[h.Alias] {
+			changes = append(changes, colorize("31", "- "+h.Alias+hostSummary(h)))
+		}
+	}
+	return changes
+}
+
+// hostSummary is a short " (user@hostname:port)" description for diffs
+func hostSummary(h SSHHost) string {
+	target := h.HostName
+	if user := effectiveUser(h); user != "" {
+		target = user + "@" + target
+	}
+	if h.Port != "" {
+		target += ":" + h.Port
+	}
+	if target == "" {
+		return ""
+	}
+	return " (" + target + ")"
+}
+
+// reloadConfig re-reads both configs and applies them after showing what changed
+func reloadConfig(hosts []SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+
+	newConfig, err := parseAppConfig()
+	var newHosts []SSHHost
+	if err == nil {
+		newHosts, err = loadHosts(newConfig, true)
+	}
+	if err != nil {
+		fmt.Printf(tr("Error reloading config: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return hosts
+	}
+
+	changes := diffHosts(hosts, newHosts)
+	if len(changes) > 0 {
+		fmt.Println(tr("\nHost changes:"))
+		for _, change := range changes {
+			fmt.Println("  " + change)
+		}
+		fmt.Print(tr("\nApply? [Y/n]: "))
+
+		input, _ := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input == "n" || input == "no" {
+			return hosts
+		}
+	}
+
+	appConfig = newConfig
+	locale = detectLocale(appConfig.Locale)
+	notify(tr("config reloaded (%d hosts)"), len(newHosts))
+
+	fmt.Printf(tr("SSH config reloaded (%d hosts)\nPress Enter..."), len(newHosts))
+	reader.ReadString('\n')
+	return newHosts
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// resultSection is a host's part of the results pager
+type resultSection struct {
+	result    HostResult
+	lines     []string // output lines, carriage returns trimmed
+	collapsed bool
+}
+
+// resultMatch is a search match in the results pager
+type resultMatch struct {
+	section, line int
+}
+
+// newResultSections splits each host's output into lines
+func newResultSections(results []HostResult) []resultSection {
+	sections := make([]resultSection, len(results))
+	for i, result := range results {
+		lines := []string{}
+		if output := strings.TrimRight(result.Output, "\r\n"); output != "" {
+			for _, line := range strings.Split(output, "\n") {
+				lines = append(lines, strings.TrimRight(line, "\r"))
+			}
+		}
+		sections[i] = resultSection{result: result, lines: lines}
+	}
+	return sections
+}
+
+// sectionHeading is the row heading a host's section: whether it is
+// collapsed, its number, its outcome and how much output it has
+func sectionHeading(i int, section resultSection) string {
+	marker := "▾"
+	if section.collapsed {
+		marker = "▸"
+	}
+	if plainMode {
+		marker = "-"
+		if section.collapsed {
+			marker = "+"
+		}
+	}
+	outcome := colorize("32", strings.TrimSuffix(okMark(), ":"))
+	if section.result.Error != nil {
+		outcome = colorize("31", failMark()) + " " + section.result.Error.Error()
+	}
+	return colorize("1", fmt.Sprintf("%s [%d] %s", marker, i+1, section.result.Alias)) + " " + outcome +
+		colorize("2", fmt.Sprintf(tr(" (%d lines)"), len(section.lines)))
+}
+
+// resultRows lays the sections out: a heading per host, followed by its
+// output unless collapsed. rowSections and rowLines give the section and
+// the output line of each row, -1 for headings
+func resultRows(sections []resultSection) (rows []string, rowSections, rowLines []int) {
+	for i, section := range sections {
+		rows = append(rows, sectionHeading(i, section))
+		rowSections, rowLines = append(rowSections, i), append(rowLines, -1)
+		if section.collapsed {
+			continue
+		}
+		for j, line := range section.lines {
+			rows = append(rows, "  "+line)
+			rowSections, rowLines = append(rowSections, i), append(rowLines, j)
+		}
+	}
+	return rows, rowSections, rowLines
+}
+
+// viewResults pages through the results of a collected run, a section per
+// host. It tells whether the failed hosts should be run again
+func viewResults(job MultiJob, results []HostResult) bool {
+	sections := newResultSections(results)
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+
+	rows, rowSections, rowLines := resultRows(sections)
+	currentLine := 0
+	current := 0
+	pageSize := DefaultPageSize
+	searchTerm := ""
+	searchResults := []resultMatch{}
+	searchIndex := -1
+	reader := bufio.NewReader(os.Stdin)
+	message := ""
+
+	layout := func() {
+		rows, rowSections, rowLines = resultRows(sections)
+	}
+	// headingRow is the row of a section's heading
+	headingRow := func(section int) int {
+		for row, s := range rowSections {
+			if s == section {
+				return row
+			}
+		}
+		return 0
+	}
+	// showSection puts a section's heading at the top of the page
+	showSection := func(section int) {
+		current = section
+		currentLine = clampTop(headingRow(section), len(rows), pageSize)
+	}
+	// scrolled makes the host at the top of the page the current one
+	scrolled := func() {
+		if len(rowSections) > 0 {
+			current = rowSections[currentLine]
+		}
+	}
+	// showMatch expands the section of a match and shows its line
+	showMatch := func(match resultMatch) {
+		sections[match.section].collapsed = false
+		layout()
+		current = match.section
+		row := headingRow(match.section) + 1 + match.line
+		currentLine = showLine(row, len(rows), pageSize)
+	}
+
+	// The page is redrawn from the resize handler while input is awaited
+	var mu sync.Mutex
+	render := func() {
+		clearScreen()
+		header := []string{tr("Multi-Host Results"), fmt.Sprintf(tr("Command: %s"), job),
+			tr("j/k hosts, c folds, / searches, e exports, q returns")}
+		if failed > 0 {
+			header = append(header, fmt.Sprintf(tr("%d of %d hosts failed, r runs them again"), failed, len(results)))
+		}
+		if searchTerm != "" {
+			header = append(header, tr("Search: ")+searchTerm, fmt.Sprintf(tr("Matches: %d"), len(searchResults)))
+		}
+		printHeader(header...)
+
+		pageSize = viewerPageSize(len(header))
+		currentLine = clampTop(currentLine, len(rows), pageSize)
+
+		endLine := min(currentLine+pageSize, len(rows))
+		for i := currentLine; i < endLine; i++ {
+			line := rows[i]
+			if searchTerm != "" && rowLines[i] >= 0 {
+				line = highlightMatches(line, searchTerm)
+			}
+			fmt.Println(line)
+		}
+
+		percent := 100
+		if len(rows) > 0 {
+			percent = endLine * 100 / len(rows)
+		}
+		if message != "" {
+			fmt.Println("\n" + message)
+			message = ""
+		}
+		fmt.Printf(tr("\n[Line %d/%d %d%%] "), currentLine+1, len(rows), percent)
+		if searchTerm != "" && len(searchResults) > 0 {
+			fmt.Printf(tr("[Match %d/%d] "), searchIndex+1, len(searchResults))
+		}
+		fmt.Printf(tr("[Host %d/%d: %s] "), current+1, len(sections), sections[current].result.Alias)
+		fmt.Print(tr("Command: "))
+	}
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	done := make(chan bool)
+	defer func() {
+		signal.Stop(winch)
+		close(done)
+	}()
+	go func() {
+		for {
+			select {
+			case <-winch:
+				mu.Lock()
+				render()
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		mu.Lock()
+		render()
+		mu.Unlock()
+
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err != nil || input == "q" {
+			return false
+		}
+
+		mu.Lock()
+		switch {
+		case input == "":
+			// Page down
+			currentLine = clampTop(currentLine+pageSize, len(rows), pageSize)
+			scrolled()
+
+		case input == "j":
+			// Next host
+			if current < len(sections)-1 {
+				showSection(current + 1)
+			}
+
+		case input == "k":
+			// Previous host, or the top of the current one
+			if headingRow(current) >= currentLine && current > 0 {
+				showSection(current - 1)
+			} else {
+				showSection(current)
+			}
+
+		case input == "d":
+			// Half page down
+			currentLine = clampTop(currentLine+pageSize/2, len(rows), pageSize)
+			scrolled()
+
+		case input == "u":
+			// Half page up
+			currentLine = clampTop(currentLine-pageSize/2, len(rows), pageSize)
+			scrolled()
+
+		case input == "J":
+			// One line down
+			currentLine = clampTop(currentLine+1, len(rows), pageSize)
+			scrolled()
+
+		case input == "K":
+			// One line up
+			currentLine = clampTop(currentLine-1, len(rows), pageSize)
+			scrolled()
+
+		case input == "g":
+			// Go to top
+			showSection(0)
+
+		case input == "G":
+			// Go to bottom: the last full page
+			currentLine = clampTop(len(rows), len(rows), pageSize)
+			scrolled()
+
+		case strings.HasPrefix(input, "c"):
+			// Collapse or expand the current host, or the numbered one
+			section := current
+			if input != "c" {
+				var num int
+				if _, err := fmt.Sscanf(input, "c%d", &num); err != nil || num < 1 || num > len(sections) {
+					message = fmt.Sprintf(tr("No host #%s"), strings.TrimPrefix(input, "c"))
+					break
+				}
+				section = num - 1
+			}
+			sections[section].collapsed = !sections[section].collapsed
+			layout()
+			showSection(section)
+
+		case input == "C":
+			// Collapse every host, or expand them all when all are collapsed
+			collapse := false
+			for _, section := range sections {
+				collapse = collapse || !section.collapsed
+			}
+			for i := range sections {
+				sections[i].collapsed = collapse
+			}
+			layout()
+			showSection(current)
+
+		case input == "F":
+			// Collapse the hosts that succeeded, expand the failed ones
+			for i := range sections {
+				sections[i].collapsed = sections[i].result.Error == nil
+			}
+			layout()
+			for i, section := range sections {
+				if !section.collapsed {
+					showSection(i)
+					break
+				}
+			}
+
+		case strings.HasPrefix(input, "/"):
+			// Search every host, collapsed ones too
+			searchTerm = strings.TrimPrefix(input, "/")
+			searchResults = []resultMatch{}
+			searchIndex = -1
+			for i, section := range sections {
+				for j, line := range section.lines {
+					if containsFold(line, searchTerm) {
+						searchResults = append(searchResults, resultMatch{i, j})
+					}
+				}
+			}
+			if len(searchResults) > 0 {
+				searchIndex = 0
+				showMatch(searchResults[0])
+			}
+
+		case input == "n":
+			// Next search result
+			if len(searchResults) > 0 {
+				searchIndex = (searchIndex + 1) % len(searchResults)
+				showMatch(searchResults[searchIndex])
+			}
+
+		case input == "N":
+			// Previous search result
+			if len(searchResults) > 0 {
+				searchIndex = (searchIndex - 1 + len(searchResults)) % len(searchResults)
+				showMatch(searchResults[searchIndex])
+			}
+
+		case input == "e" || strings.HasPrefix(input, "e "):
+			// Export every host's results, collapsed or not
+			path, err := exportResults(job, sections, strings.TrimSpace(strings.TrimPrefix(input, "e")))
+			if err != nil {
+				message = fmt.Sprintf(tr("Export failed: %v"), err)
+			} else {
+				message = fmt.Sprintf(tr("Exported to %s"), path)
+			}
+
+		case input == "r":
+			// Run the failed hosts again
+			if failed > 0 {
+				mu.Unlock()
+				return true
+			}
+			message = tr("No host failed")
+		}
+		mu.Unlock()
+	}
+}
+
+// exportResults writes a collected run's results to path, as JSON when it
+// ends in .json and as text otherwise, escape sequences removed. With no
+// path, a timestamped text file goes to the state directory
+func exportResults(job MultiJob, sections []resultSection, path string) (string, error) {
+	if path == "" {
+		dir, err := stateDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "multi-results-"+time.Now().Format("20060102-150405")+".txt")
+	}
+	path = expandHome(path)
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		type exported struct {
+			Host   string `json:"host"`
+			Error  string `json:"error,omitempty"`
+			Output string `json:"output"`
+		}
+		hosts := []exported{}
+		for _, section := range sections {
+			host := exported{Host: section.result.Alias, Output: sectionText(section)}
+			if section.result.Error != nil {
+				host.Error = section.result.Error.Error()
+			}
+			hosts = append(hosts, host)
+		}
+		var err error
+		if data, err = json.MarshalIndent(map[string]any{"command": job.String(), "hosts": hosts}, "", "  "); err != nil {
+			return "", err
+		}
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, tr("Command: %s\n\n"), job)
+		for _, section := range sections {
+			fmt.Fprintf(&b, tr("Host: %s\n"), section.result.Alias)
+			if section.result.Error != nil {
+				fmt.Fprintf(&b, tr("Error: %v\n"), section.result.Error)
+			}
+			fmt.Fprintf(&b, "\n%s\n\n", sectionText(section))
+		}
+		data = []byte(b.String())
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// sectionText is a host's output as shown on screen
+func sectionText(section resultSection) string {
+	lines := make([]string, len(section.lines))
+	for i, line := range section.lines {
+		lines[i] = screenText(line)
+	}
+	return strings.Join(lines, "\n")
+}
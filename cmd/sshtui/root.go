@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// rootTracker watches a shell session's prompts for a root shell: one
+// after sudo -i or su ends in #, where user prompts end in $ or %.
+// With shell integration, the euid bash reports at each prompt says so
+// first, see commandTracker. It follows the scrollback subscriber
+type rootTracker struct {
+	session *Session
+}
+
+func (t rootTracker) Write(p []byte) (int, error) {
+	sessionsMu.RLock()
+	scrollback := t.session.Scrollback
+	line := string(scrollback[bytes.LastIndexByte(scrollback, '\n')+1:])
+	sessionsMu.RUnlock()
+
+	if root, ok := rootPrompt(line); ok {
+		setRoot(t.session, root)
+	}
+	return len(p), nil
+}
+
+// rootPrompt tells whether line, the last of the output, is a shell
+// prompt waiting for a command and if so whether it is root's
+func rootPrompt(line string) (root, prompt bool) {
+	if len(line) > AuthPromptMaxLen {
+		return false, false
+	}
+	text := screenText(line)
+	trimmed := strings.TrimRight(text, " ")
+	if trimmed == text || trimmed == "" {
+		// Prompts end in a space; anything typed after one does not
+		return false, false
+	}
+	switch trimmed[len(trimmed)-1] {
+	case '#':
+		return true, true
+	case '$', '%':
+		// Not >, which is also the continuation prompt
+		return false, true
+	}
+	return false, false
+}
+
+// setRoot records whether the session's shell runs as root, redrawing
+// the status bar of an attached view. Callers are output subscribers,
+// so the bar is drawn in order with the output
+func setRoot(s *Session, root bool) {
+	sessionsMu.Lock()
+	changed := s.Root != root
+	s.Root = root
+	attached := s.State == StateAttached && s.ioStop != nil
+	sessionsMu.Unlock()
+	if !changed {
+		return
+	}
+	postEvent(EventSession)
+	if attached && s.Host.StatusBar && !plainMode {
+		drawStatusBar(s)
+	}
+}
+
+// rootLabel returns the badge of a session running a root shell
+func rootLabel(s *Session) string {
+	if !s.Root {
+		return ""
+	}
+	return " " + colorize("1;37;41", tr("ROOT"))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MultiJob is what a multi-host run executes on each host
+type MultiJob struct {
+	Command string // shell command, or the arguments when Script is set
+	Script  string // local script uploaded, run and removed on each host
+}
+
+// parseMultiJob reads "@path/to/script.sh args" as a script job, anything
+// else as a plain command
+func parseMultiJob(input string) MultiJob {
+	if !strings.HasPrefix(input, "@") {
+		return MultiJob{Command: input}
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(input, "@"), " ", 2)
+	job := MultiJob{Script: expandHome(fields[0])}
+	if len(fields) > 1 {
+		job.Command = strings.TrimSpace(fields[1])
+	}
+	return job
+}
+
+func (j MultiJob) String() string {
+	if j.Script == "" {
+		return j.Command
+	}
+	return strings.TrimSpace(fmt.Sprintf("@%s %s", filepath.Base(j.Script), j.Command))
+}
+
+// prepare returns the remote command for a host, uploading the script
+// first; the upload is stopped when ctx ends
+func (j MultiJob) prepare(ctx context.Context, host SSHHost) (string, error) {
+	if j.Script == "" {
+		return j.Command, nil
+	}
+
+	path, err := uploadScript(ctx, host, j.Script)
+	if err != nil {
+		return "", err
+	}
+	// Run, keep the script's exit code, always clean up
+	return fmt.Sprintf("%s %s; rc=$?; rm -f %s; exit $rc", shellQuote(path), j.Command, shellQuote(path)), nil
+}
+
+// uploadScript copies a local script to a temp file on the host and returns its path
+func uploadScript(ctx context.Context, host SSHHost, script string) (string, error) {
+	file, err := os.Open(script)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	remote := `t=$(mktemp /tmp/sshtui.XXXXXX) && cat > "$t" && chmod 700 "$t" && echo "$t"`
+	args := append([]string{"-o", "ClearAllForwardings=yes"}, overrideRemoteCommand(host)...)
+	args = append(args, host.Extras...)
+	args = append(args, sshTarget(host)...)
+	args = append(args, remote)
+
+	var stdout, stderr bytes.Buffer
+	cmd := commander.Command(ctx, "ssh", args...)
+	cmd.Stdin = file
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf(tr("upload failed: %s"), msg)
+		}
+		return "", fmt.Errorf(tr("upload failed: %v"), err)
+	}
+
+	path := strings.TrimSpace(stdout.String())
+	if path == "" {
+		return "", errors.New(tr("upload failed: no temp path returned"))
+	}
+	return path, nil
+}
+
+// shellQuote quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+const (
+	// ScrollbackChunkSize is how much of the oldest output is compressed at
+	// a time once the scrollback is full
+	ScrollbackChunkSize = 256 * 1024
+	// DefaultScrollbackHistory is how much output, uncompressed, is kept
+	// compressed beyond MaxScrollbackSize unless ScrollbackHistory says
+	// otherwise; shell output shrinks five to ten times
+	DefaultScrollbackHistory = 32 * 1024 * 1024
+)
+
+// scrollbackChunk is older output of a session, compressed
+type scrollbackChunk struct {
+	data []byte // flate
+	size int    // uncompressed
+}
+
+// compactScrollback moves the oldest output of a session's full
+// scrollback into compressed chunks, dropping the oldest chunks beyond
+// ScrollbackHistory; without it, the oldest output is dropped. Callers
+// hold sessionsMu
+func compactScrollback(s *Session) {
+	for len(s.Scrollback) > MaxScrollbackSize {
+		if appConfig.ScrollbackHistory == 0 {
+			s.Scrollback = ptysession.TailAtBoundary(s.Scrollback, MaxScrollbackSize)
+			return
+		}
+		// Chunks end where a replay may start, so none cuts a line
+		// or an escape sequence in two
+		rest := ptysession.TailAtBoundary(s.Scrollback, len(s.Scrollback)-ScrollbackChunkSize)
+		chunk := s.Scrollback[:len(s.Scrollback)-len(rest)]
+		s.history = append(s.history, compressChunk(chunk))
+		s.Scrollback = append([]byte{}, rest...)
+	}
+
+	size := 0
+	for _, chunk := range s.history {
+		size += chunk.size
+	}
+	for len(s.history) > 0 && size > appConfig.ScrollbackHistory {
+		size -= s.history[0].size
+		s.history = s.history[1:]
+	}
+}
+
+// compressChunk compresses output for the history; speed matters more
+// than size, as it happens while the output streams
+func compressChunk(output []byte) scrollbackChunk {
+	var b bytes.Buffer
+	w, _ := flate.NewWriter(&b, flate.BestSpeed)
+	w.Write(output)
+	w.Close()
+	return scrollbackChunk{data: b.Bytes(), size: len(output)}
+}
+
+// fullScrollback returns a session's whole output, the compressed history
+// first; callers hold sessionsMu
+func fullScrollback(s *Session) []byte {
+	if len(s.history) == 0 {
+		return s.Scrollback
+	}
+	var b bytes.Buffer
+	for _, chunk := range s.history {
+		b.Grow(chunk.size)
+		io.Copy(&b, flate.NewReader(bytes.NewReader(chunk.data)))
+	}
+	b.Write(s.Scrollback)
+	return b.Bytes()
+}
+
+// historySummary tells how much output a session keeps compressed and in
+// how much memory, "" when it has no history
+func historySummary(s *Session) string {
+	size, stored := 0, 0
+	for _, chunk := range s.history {
+		size += chunk.size
+		stored += len(chunk.data)
+	}
+	if size == 0 {
+		return ""
+	}
+	return fmt.Sprintf(tr("%s older output in %s"), formatBytes(int64(size)), formatBytes(int64(stored)))
+}
+
+// parseSize reads a byte count: a number with an optional K, M or G
+// suffix (KB, MB, GB and KiB... too), binary units
+func parseSize(value string) (int, error) {
+	upper := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "I")
+	scale := 1
+	if upper != "" {
+		switch upper[len(upper)-1] {
+		case 'K':
+			scale = 1 << 10
+		case 'M':
+			scale = 1 << 20
+		case 'G':
+			scale = 1 << 30
+		}
+	}
+	if scale > 1 {
+		upper = upper[:len(upper)-1]
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(upper))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf(tr("invalid size %q, use e.g. 512K or 64M"), value)
+	}
+	return n * scale, nil
+}
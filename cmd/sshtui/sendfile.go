@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SendFileKey     = 0x1d // Ctrl+], twice to send it, see sessionKeys
+	MaxSendFileSize = 10 * 1024 * 1024
+	sendFileMarker  = "SSHTUI_EOF"
+	base64LineWidth = 76
+)
+
+// readLineRaw reads a line from stdin while the terminal is in raw mode,
+// echoing input itself; Esc or Ctrl+C cancels
+func readLineRaw(prompt string) (string, bool) {
+	return readRaw(prompt, true)
+}
+
+// readRaw is readLineRaw, echoing input only with echo, for secrets
+func readRaw(prompt string, echo bool) (string, bool) {
+	os.Stdout.WriteString(prompt)
+
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		if _, err := term.Read(buf); err != nil {
+			return "", false
+		}
+		switch b := buf[0]; b {
+		case '\r', '\n':
+			os.Stdout.WriteString("\r\n")
+			return string(line), true
+		case 0x1b, 0x03: // Esc, Ctrl+C
+			os.Stdout.WriteString("\r\n")
+			return "", false
+		case 0x7f, 0x08: // Backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				if echo {
+					os.Stdout.WriteString("\b \b")
+				}
+			}
+		default:
+			if b >= 0x20 {
+				line = append(line, b)
+				if echo {
+					os.Stdout.Write(buf)
+				}
+			}
+		}
+	}
+}
+
+// isText reports whether data can go through a heredoc unchanged
+func isText(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 && b != '\n' && b != '\t' {
+			return false
+		}
+	}
+	return !bytes.Contains(data, []byte("\n"+sendFileMarker+"\n"))
+}
+
+// promptSendFile asks for a local file and types it into the remote shell,
+// either base64-encoded or, for plain text, through cat
+func promptSendFile(session *Session) {
+	path, ok := readLineRaw(tr("\r\n[sshtui] Send local file (Esc cancels): "))
+	if !ok || strings.TrimSpace(path) == "" {
+		return
+	}
+	path = expandHome(strings.TrimSpace(path))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf(tr("[sshtui] %v\r\n"), err)
+		return
+	}
+	if len(data) > MaxSendFileSize {
+		fmt.Printf(tr("[sshtui] file too large (%d bytes, max %d)\r\n"), len(data), MaxSendFileSize)
+		return
+	}
+
+	name := filepath.Base(path)
+	if remote, ok := readLineRaw(fmt.Sprintf(tr("[sshtui] Remote name [%s]: "), name)); !ok {
+		return
+	} else if strings.TrimSpace(remote) != "" {
+		name = strings.TrimSpace(remote)
+	}
+
+	mode := "b"
+	if isText(data) {
+		answer, ok := readLineRaw(tr("[sshtui] Transfer with [b]ase64 or [c]at? [b]: "))
+		if !ok {
+			return
+		}
+		if strings.TrimSpace(answer) == "c" {
+			mode = "c"
+		}
+	}
+
+	var script strings.Builder
+	if mode == "c" {
+		fmt.Fprintf(&script, "cat > %s <<'%s'\n", shellQuote(name), sendFileMarker)
+		script.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			script.WriteString("\n")
+		}
+	} else {
+		fmt.Fprintf(&script, "base64 -d > %s <<'%s'\n", shellQuote(name), sendFileMarker)
+		encoded := base64.StdEncoding.EncodeToString(data)
+		for len(encoded) > base64LineWidth {
+			script.WriteString(encoded[:base64LineWidth] + "\n")
+			encoded = encoded[base64LineWidth:]
+		}
+		script.WriteString(encoded + "\n")
+	}
+	script.WriteString(sendFileMarker + "\n")
+
+	if _, err := session.PTY.Write([]byte(script.String())); err != nil {
+		fmt.Printf(tr("[sshtui] %v\r\n"), err)
+	}
+}
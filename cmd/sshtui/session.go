@@ -0,0 +1,648 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+const (
+	ScrollbackReplaySize = 4096
+	MaxScrollbackSize    = 1024 * 1024
+	StdinBufSize         = 1024
+	PtyBufSize           = 4096
+	ConnectionTimeout    = 10 * time.Second
+	FallbackPTYColumns   = 120 // PTY size when there is no terminal to mirror
+	FallbackPTYRows      = 40
+	TakeoverPollInterval = 50 * time.Millisecond
+)
+
+// Session kinds
+const (
+	SessionShell   = "shell"
+	SessionForward = "forward"
+)
+
+// Session represents a running SSH session with PTY
+type Session struct {
+	ID         int
+	Alias      string
+	Host       SSHHost
+	Args       []string        // command line, ssh first, reused on reconnect
+	Kind       string          // SessionShell or SessionForward
+	Forwards   []PortForward   // forwards requested by this session
+	Relays     []*ForwardRelay // count the use of a forward session's local forwards
+	Cmd        *exec.Cmd
+	PTY        *os.File
+	State      string // one of the State* constants
+	Scrollback []byte
+	DebugLog   string           // ssh -vvv capture when --debug-connect is on
+	Restarts   int              // automatic restarts of a forward session
+	Watch      string           // watchdog mode: WatchOff, WatchAlert or WatchReconnect
+	Bell       bool             // rang the bell since last attached
+	Root       bool             // the shell's last prompt was root's, see rootTracker
+	Tee        *OutputTee       // local command receiving a copy of the output
+	Recorder   *SessionRecorder // set for recorded tags, from the start
+	Output     *OutputPipeline
+	Stats      IOStats
+	Ended      time.Time  // when the session last went Dead
+	closed     bool       // set when the user closes the session
+	ioStop     chan bool  // set while attached, ends the attached view
+	secret     secretMask // hides secrets typed at a prompt from the captures
+
+	LastCommand     string    // last command run at a prompt, from OSC 133 marks
+	LastExit        string    // its exit status, "" while running or unknown
+	LastCommandTime time.Time // when it started
+
+	attachedOnce bool       // Detached rather than Ready after the first attach
+	attachMu     sync.Mutex // held by the view attached to the PTY
+
+	codec       *encodingCodec    // converts the PTY streams of hosts with an Encoding
+	promptSince time.Time         // when it last reached an auth prompt, see relayPrompts
+	history     []scrollbackChunk // output older than Scrollback, compressed
+}
+
+var (
+	sessions   []*Session
+	nextID     = 1
+	sessionsMu sync.RWMutex
+)
+
+func createSession(host SSHHost) {
+	if !confirmHost(host, ConfirmConnect) {
+		return
+	}
+	reason := ""
+	if tag := reasonTag(host); tag != "" {
+		var ok bool
+		if reason, ok = askReason([]string{host.Alias}, tag); !ok {
+			return
+		}
+	}
+	if err := refreshCertificate(host); err != nil {
+		fmt.Printf(tr("Warning: %v\n"), err)
+	}
+	if !confirmCertificates(host) {
+		return
+	}
+
+	fmt.Printf(tr("\nConnecting to %s...\n"), host.Alias)
+	releaseKeys := holdTypeAhead()
+
+	debugLog := ""
+	// A Connect command does not take ssh's -vvv
+	if debugConnect && host.Connect == "" {
+		var err error
+		if debugLog, err = newDebugLog(); err != nil {
+			fmt.Printf(tr("Warning: debug capture disabled: %v\n"), err)
+		} else {
+			// -E sends the -vvv output to the log instead of the terminal
+			host.Extras = append(append([]string{}, host.Extras...), "-vvv", "-E", debugLog)
+		}
+	}
+
+	argv, err := sessionCommand(host)
+	var session *Session
+	if err == nil {
+		session, err = startSession(host, argv)
+	}
+	auditConnect(host, reason, session, err)
+	if err != nil {
+		releaseKeys(true)
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+	session.DebugLog = debugLog
+
+	// Attach immediately; keys typed meanwhile go to the session
+	attachToSession(session)
+	releaseKeys(false)
+
+	if debugLog != "" {
+		showConnectDebug(session)
+	}
+}
+
+// connectHost connects to host, unless it already has a live shell
+// session: then it asks whether to attach to it, open another or cancel.
+// Hosts set to ExternalWindow open in a new terminal window
+func connectHost(host SSHHost) {
+	if host.ExternalWindow {
+		openExternal(host)
+		return
+	}
+	sessionsMu.RLock()
+	var existing *Session
+	for i, s := range sessions {
+		if s.Alias == host.Alias && s.Kind == SessionShell && s.running() {
+			if existing == nil {
+				fmt.Printf(tr("\n%s already has an open session:\n"), host.Alias)
+			}
+			fmt.Printf("  [!%d] %s (%s)\n", i+1, s.Alias, coloredStatus(s))
+			existing = s
+		}
+	}
+	sessionsMu.RUnlock()
+	if existing == nil {
+		createSession(host)
+		return
+	}
+
+	fmt.Print(tr("a attaches to the last one, n opens another, Enter cancels: "))
+	input, _ := bufio.NewReader(term).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "a":
+		attachToSession(existing)
+	case "n":
+		createSession(host)
+	}
+}
+
+// startSession spawns the command line argv, usually ssh, on a PTY and
+// registers it in the session list
+func startSession(host SSHHost, argv []string) (*Session, error) {
+	cmd, ptmx, err := spawnPTY(argv)
+	if err != nil {
+		recordConnect(host.Alias, err)
+		return nil, err
+	}
+
+	var tee *OutputTee
+	var scrollback []byte
+	if host.Tee != "" {
+		if tee, err = startTee(host.Tee); err != nil {
+			scrollback = fmt.Appendf(nil, tr("[sshtui] tee failed: %v\r\n"), err)
+		}
+	}
+	var recorder *SessionRecorder
+	if tag := recordTag(host); tag != "" {
+		if recorder, err = startRecording(host.Alias); err != nil {
+			scrollback = fmt.Appendf(scrollback, tr("[sshtui] recording failed: %v\r\n"), err)
+		} else {
+			scrollback = fmt.Appendf(scrollback, tr("[sshtui] tagged %s, recording to %s\r\n"), tag, recorder.Path)
+		}
+	}
+
+	order := loadSessionOrder()
+	sessionsMu.Lock()
+	session := &Session{
+		ID:         nextID,
+		Alias:      host.Alias,
+		Host:       host,
+		Args:       argv,
+		Kind:       SessionShell,
+		Forwards:   host.Forwards,
+		Cmd:        cmd,
+		PTY:        ptmx,
+		State:      StateConnecting,
+		Scrollback: scrollback,
+		Tee:        tee,
+		Recorder:   recorder,
+		codec:      newEncodingCodec(host),
+	}
+	session.Output = newSessionOutput(session)
+	if tee != nil {
+		session.Output.Subscribe(SubscriberTee, maskedWriter{&session.secret, tee})
+	}
+	if recorder != nil {
+		session.Output.Subscribe(SubscriberRecord, maskedWriter{&session.secret, recorder})
+	}
+	nextID++
+	insertSession(session, order)
+	sessionsMu.Unlock()
+	postEvent(EventSession)
+	markHostTouched(host.Alias)
+
+	go readPTY(session, ptmx)
+	go monitorSession(session)
+
+	return session, nil
+}
+
+// ptySize is the size given to a new PTY before anything attaches to it:
+// PTYSize from the config, else the terminal's, else a fallback. Without
+// one, remote commands see a 0x0 terminal
+func ptySize() *pty.Winsize {
+	if appConfig.PTYColumns > 0 {
+		return &pty.Winsize{Cols: uint16(appConfig.PTYColumns), Rows: uint16(appConfig.PTYRows)}
+	}
+	if ws, err := term.Size(); err == nil && ws.Cols > 0 && ws.Rows > 0 {
+		return ws
+	}
+	return &pty.Winsize{Cols: FallbackPTYColumns, Rows: FallbackPTYRows}
+}
+
+// spawnPTY starts the command line argv on a new PTY
+func spawnPTY(argv []string) (*exec.Cmd, *os.File, error) {
+	cmd := commander.Command(context.Background(), argv[0], argv[1:]...)
+	ptmx, err := ptysession.StartWith(ptyStarter, cmd, ptySize(), ConnectionTimeout)
+	if errors.Is(err, ptysession.ErrStartTimeout) {
+		return nil, nil, fmt.Errorf(tr("connection timeout after %v"), ConnectionTimeout)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, ptmx, nil
+}
+
+// monitorSession waits for ssh to exit and reconnects critical sessions
+// that have auto-reconnect enabled
+func monitorSession(session *Session) {
+	for {
+		sessionsMu.RLock()
+		cmd := session.Cmd
+		sessionsMu.RUnlock()
+
+		cmd.Wait()
+
+		sessionsMu.Lock()
+		reconnect := session.Watch == WatchReconnect && !session.closed
+		attached := session.State == StateAttached
+		session.State = StateDead
+		session.Ended = time.Now()
+		if reconnect {
+			session.State = StateReconnecting
+		}
+		closed := session.closed
+		reason := lastOutputLine(session.Scrollback)
+		sessionsMu.Unlock()
+		postEvent(EventSession)
+
+		if !closed {
+			recordExit(session.Alias, cmd.ProcessState, reason)
+			if !reconnect && !attached {
+				notify(tr("session %s ended: %s"), session.Alias, valueOr(escapePattern.ReplaceAllString(reason, ""), cmd.ProcessState.String()))
+			}
+		}
+
+		if !reconnect || !reconnectSession(session) {
+			return
+		}
+	}
+}
+
+// sessionIndex returns the 1-based menu number of a session, or 0 if gone
+func sessionIndex(session *Session) int {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	for i, s := range sessions {
+		if s == session {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func attachToSession(session *Session) {
+	// Panic recovery to ensure terminal is restored
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf(tr("\n\nPanic recovered: %v\n"), r)
+			fmt.Println(tr("Terminal state restored. Press Enter..."))
+			bufio.NewReader(os.Stdin).ReadString('\n')
+		}
+	}()
+
+	// Forward sessions have no terminal to attach to; show their log instead
+	if session.PTY == nil {
+		viewScrollback(session)
+		return
+	}
+
+	sessionsMu.RLock()
+	dead := !session.running()
+	sessionsMu.RUnlock()
+	if dead {
+		fmt.Println(tr("Session has ended. Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	// One view at a time owns the PTY; taking over detaches the other one,
+	// like tmux attach -d
+	if !session.attachMu.TryLock() {
+		if !confirmTakeover(session) {
+			return
+		}
+		takeOverSession(session)
+	}
+	defer session.attachMu.Unlock()
+
+	// The TOTP code is for logging in: on the first attach, or back at
+	// a prompt
+	sessionsMu.RLock()
+	loggingIn := !session.attachedOnce || session.State == StateAuthPrompt
+	sessionsMu.RUnlock()
+	totp := ""
+	if loggingIn {
+		totp = totpBanner(session.Host)
+	}
+
+	// Hosts with StatusBar keep a bar on the top row instead of a banner
+	statusBar := session.Host.StatusBar && !plainMode
+	clearScreen()
+	if statusBar {
+		startStatusBar(session)
+		defer stopStatusBar()
+		if totp != "" {
+			fmt.Println(totp)
+		}
+	} else {
+		lines := []string{tr("Connected: ") + session.Alias, tr("Ctrl+Space to detach (twice sends it)"), tr("Ctrl+] to send a file"), tr("Ctrl+^ to toggle I/O stats"), tr("Ctrl+_ to pause output")}
+		if totp != "" {
+			lines = append(lines, totp)
+		}
+		printHeader(lines...)
+		sessionsMu.RLock()
+		root := session.Root
+		sessionsMu.RUnlock()
+		if root {
+			fmt.Println(colorize("1;37;41", tr(" ROOT: this shell runs as root ")))
+		}
+	}
+
+	// I/O proxy
+	ioStop := make(chan bool, 2) // Buffered to avoid blocking goroutines
+	terminal := &pausableTerminal{}
+
+	// Replay scrollback buffer when reattaching, then subscribe the
+	// terminal to live output; the pipeline keeps the two in order
+	session.Output.SubscribeAfter(SubscriberTerminal, terminal, func() {
+		sessionsMu.Lock()
+		defer sessionsMu.Unlock()
+
+		if len(session.Scrollback) > 0 {
+			// Limit to last 4KB to avoid flooding terminal
+			scrollbackToShow := ptysession.TailAtBoundary(session.Scrollback, ScrollbackReplaySize)
+
+			// Write scrollback to stdout
+			os.Stdout.Write(scrollbackToShow)
+			fmt.Println(tr("\n--- [Scrollback end, live session resumed] ---"))
+		}
+		session.State = StateAttached
+		session.Bell = false
+		session.attachedOnce = true
+		session.ioStop = ioStop
+	})
+	if statusBar {
+		session.Output.Hold(func() { drawStatusBar(session) })
+	}
+
+	defer func() {
+		session.Output.Unsubscribe(SubscriberTerminal)
+		sessionsMu.Lock()
+		session.ioStop = nil
+		if session.State == StateAttached {
+			session.State = detachedState(session)
+		}
+		sessionsMu.Unlock()
+	}()
+
+	// Set PTY size, less the status bar
+	resize := func() {
+		ws, err := term.Size()
+		if statusBar {
+			ws, err = statusBarWinsize()
+		}
+		if err != nil {
+			return
+		}
+		pty.Setsize(session.PTY, ws)
+		if statusBar {
+			redrawStatusBar(session, int(ws.Rows)+StatusBarRows)
+		}
+	}
+	resize()
+
+	// Handle window resize with proper cleanup
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	done := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-winch:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		signal.Stop(winch)
+		close(done)
+	}()
+
+	go trackIOStats(session, done)
+
+	// Set raw mode
+	restoreTerm, err := term.MakeRaw()
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	defer restoreTerm()
+
+	// Track attached time per host for the time report
+	defer recordAttachTime(session.Alias, time.Now())
+
+	// Stdin -> PTY
+	stop := func() {
+		select {
+		case ioStop <- true:
+		default:
+		}
+	}
+	go func() {
+		buf := make([]byte, StdinBufSize)
+		for {
+			n, err := term.Read(buf)
+			if err != nil {
+				stop()
+				return
+			}
+
+			// sshtui's keys act when pressed alone; pressed twice, they
+			// reach the session
+			input := buf[:n]
+			for len(input) > 0 {
+				data, key, found, rest := nextSessionKey(input, moreStdin)
+				input = rest
+				if len(data) > 0 {
+					session.Stats.In.Add(int64(len(data)))
+					// Keys typed at a password prompt are never recorded
+					if keys := maskSecretInput(session, data); session.Recorder != nil && len(keys) > 0 {
+						session.Recorder.Input(keys)
+					}
+
+					if _, err := session.writeInput(data); err != nil {
+						stop()
+						return
+					}
+				}
+				if !found {
+					break
+				}
+				switch key {
+				case DetachKey:
+					stop()
+					return
+				case SendFileKey:
+					// Keys typed after it went to the prompt
+					promptSendFile(session)
+					input = nil
+				case StatsKey:
+					toggleIOStats(session)
+				case PauseKey:
+					terminal.toggle()
+				}
+			}
+		}
+	}()
+
+	// PTY -> Stdout goes through the session's output pipeline
+
+	// Wait for detach or end
+	<-ioStop
+
+	// Don't close the channel - goroutines might still try to send to it
+	// Just let them finish naturally or remain blocked on Read()
+
+	// Note: Goroutines may still be blocked on Read() calls.
+	// Terminal restoration via defer will make stdin line-buffered again.
+	// The Drain call below will consume any pending input.
+
+	// Drain any pending stdin input that may have accumulated
+	// This prevents the need for double Enter after detach
+	term.Drain()
+
+	fmt.Print(tr("\n\n[Detached]\n"))
+}
+
+// confirmTakeover asks whether to detach the view holding the session
+func confirmTakeover(session *Session) bool {
+	fmt.Printf(tr("Session %s is attached elsewhere. Detach it and take over? [y/N]: "), session.Alias)
+	input, _ := bufio.NewReader(term).ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(input)) == "y"
+}
+
+// takeOverSession detaches the attached view and takes the attach lock;
+// the view may still be starting, so it is asked again until it lets go
+func takeOverSession(session *Session) {
+	for !session.attachMu.TryLock() {
+		detachSession(session)
+		time.Sleep(TakeoverPollInterval)
+	}
+}
+
+// detachSession ends the view attached to session, if any
+func detachSession(session *Session) {
+	sessionsMu.RLock()
+	stop := session.ioStop
+	sessionsMu.RUnlock()
+	if stop != nil {
+		select {
+		case stop <- true:
+		default:
+		}
+	}
+}
+
+// makeRaw and restore are in terminal_darwin.go and terminal_linux.go
+
+// holdTypeAhead keeps keys typed while a session connects from being
+// echoed or line-edited, so they wait in the terminal's input queue and
+// reach the session once attached. The returned function puts the
+// terminal back; with discard, it drops the waiting keys and says so
+func holdTypeAhead() func(discard bool) {
+	restoreTerm, err := term.MakeCbreak()
+	if err != nil {
+		return func(bool) {}
+	}
+	return func(discard bool) {
+		if discard {
+			if n := term.Drain(); n > 0 {
+				fmt.Printf(tr("(%d typed characters discarded)\n"), n)
+			}
+		}
+		restoreTerm()
+	}
+}
+
+func closeAllSessions() {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for _, s := range sessions {
+		killSession(s)
+	}
+}
+
+// closeActiveSession closes the most recent live shell session;
+// forward sessions are left alone and closed from the forward screen
+func closeActiveSession() {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if sessions[i].running() && sessions[i].Kind != SessionForward {
+			killSession(sessions[i])
+			sessions = append(sessions[:i], sessions[i+1:]...)
+			break
+		}
+	}
+}
+
+// closeSession closes the session with the given 1-based menu number
+func closeSession(num int) bool {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	if num < 1 || num > len(sessions) {
+		return false
+	}
+	killSession(sessions[num-1])
+	sessions = append(sessions[:num-1], sessions[num:]...)
+	return true
+}
+
+// killSession terminates the ssh process; callers hold sessionsMu
+func killSession(s *Session) {
+	s.closed = true
+	if s.Tee != nil {
+		s.Tee.Close()
+		s.Tee = nil
+	}
+	if s.Recorder != nil {
+		s.Recorder.Close()
+	}
+	closeForwardRelays(s.Relays)
+	if s.DebugLog != "" {
+		os.Remove(s.DebugLog)
+	}
+	if s.PTY != nil {
+		s.PTY.Close()
+	}
+	if s.Cmd.Process != nil {
+		s.Cmd.Process.Kill()
+		// Forward sessions are reaped by their supervisor
+		if s.Kind != SessionForward {
+			s.Cmd.Wait()
+		}
+	}
+}
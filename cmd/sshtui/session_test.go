@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiowebux/sshtui/pkg/ptysession/ptytest"
+)
+
+// waitFor waits for cond to hold, at most a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+// fakeShell plays an interactive ssh session: it echoes lines until one
+// says quit
+const fakeShell = `while read -r line; do [ "$line" = quit ] && exit 3; echo "got $line"; done`
+
+func TestAttachDetach(t *testing.T) {
+	headless(t, fakeShell)
+	t.Setenv("HOME", t.TempDir())
+	keys := term.(*ptytest.Terminal)
+	starter := ptyStarter.(*ptytest.Starter)
+	// Ends the input loop attaching left reading
+	t.Cleanup(keys.Close)
+	t.Cleanup(func() {
+		sessionsMu.Lock()
+		sessions = nil
+		sessionsMu.Unlock()
+	})
+
+	session, err := startSession(SSHHost{Alias: "web"}, []string{"ssh", "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes := starter.Sizes(); len(sizes) != 1 || sizes[0].Cols != 80 || sizes[0].Rows != 24 {
+		t.Errorf("PTY started with sizes %v, want the terminal's 80x24", sizes)
+	}
+
+	attached := make(chan struct{})
+	go func() {
+		attachToSession(session)
+		close(attached)
+	}()
+	waitFor(t, "raw mode", func() bool { return keys.Mode() == "raw" })
+	waitState(t, session, StateAttached, time.Second)
+
+	// Typed keys reach the session, its output the scrollback
+	keys.Type("hello\n")
+	waitFor(t, "the session's reply", func() bool {
+		sessionsMu.RLock()
+		defer sessionsMu.RUnlock()
+		return strings.Contains(string(session.Scrollback), "got hello")
+	})
+
+	// Ctrl+Space alone detaches and puts the terminal back
+	keys.Type("\x00")
+	select {
+	case <-attached:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Ctrl+Space did not detach")
+	}
+	if mode := keys.Mode(); mode != "" {
+		t.Errorf("terminal left in %s mode", mode)
+	}
+	waitState(t, session, StateDetached, time.Second)
+
+	// The monitor notices ssh exiting while detached
+	if _, err := session.writeInput([]byte("quit\n")); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, session, StateDead, 3*time.Second)
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	if session.Ended.IsZero() {
+		t.Error("an ended session has no end time")
+	}
+}
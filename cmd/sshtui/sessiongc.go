@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSessionRetention is how long an ended session stays in the
+	// list unless SessionRetention says otherwise
+	DefaultSessionRetention = time.Hour
+	SessionSweepInterval    = time.Minute
+	MaxArchivedSessions     = 20 // older archived scrollbacks are dropped
+	ArchivedMenuLines       = 3  // latest archived sessions shown in the menu
+)
+
+// archivedSessions are ended sessions taken out of the list, newest
+// first, kept for their scrollback; guarded by sessionsMu
+var archivedSessions []*Session
+
+// archiveSession moves an ended session from the list to the archive;
+// callers hold sessionsMu
+func archiveSession(s *Session) {
+	for i, other := range sessions {
+		if other == s {
+			sessions = append(sessions[:i], sessions[i+1:]...)
+			break
+		}
+	}
+	killSession(s)
+	archivedSessions = append([]*Session{s}, archivedSessions...)
+	if len(archivedSessions) > MaxArchivedSessions {
+		archivedSessions = archivedSessions[:MaxArchivedSessions]
+	}
+}
+
+// clearEndedSessions archives every ended session now and returns how
+// many there were
+func clearEndedSessions() int {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	ended := selectSessions(isEnded)
+	for _, s := range ended {
+		archiveSession(s)
+	}
+	return len(ended)
+}
+
+// sweepEndedSessions archives sessions ended for longer than the
+// retention. Critical sessions stay until closed, with their alert
+func sweepEndedSessions() {
+	if appConfig.SessionRetention == 0 {
+		return
+	}
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	expired := selectSessions(func(s *Session) bool {
+		return isEnded(s) && s.Watch == WatchOff && time.Since(s.Ended) > appConfig.SessionRetention
+	})
+	for _, s := range expired {
+		archiveSession(s)
+	}
+	if len(expired) > 0 {
+		notify(tr("%d ended sessions archived"), len(expired))
+	}
+}
+
+// startSessionSweeper archives expired sessions in the background; the
+// menu redraws when the list changes
+func startSessionSweeper() {
+	go func() {
+		for range time.Tick(SessionSweepInterval) {
+			sweepEndedSessions()
+		}
+	}()
+}
+
+// archivedSummary lists the latest archived sessions for the menu;
+// callers hold sessionsMu
+func archivedSummary() string {
+	if len(archivedSessions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintln(&b, tr("Archived (scrollback only, v to view):"))
+	for i, s := range archivedSessions[:min(len(archivedSessions), ArchivedMenuLines)] {
+		fmt.Fprintf(&b, tr("  [~%d] %s, ended %s\n"), i+1, s.Alias, s.Ended.Format(time.TimeOnly))
+	}
+	if more := len(archivedSessions) - ArchivedMenuLines; more > 0 {
+		fmt.Fprintf(&b, tr("  and %d more\n"), more)
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const sessionOrderFile = "session-order.json"
+
+// loadSessionOrder returns host aliases in the order their sessions are
+// listed, as last arranged by the user
+func loadSessionOrder() []string {
+	order := []string{}
+	loadState(sessionOrderFile, &order)
+	return order
+}
+
+// listedOrder returns the aliases of the sessions in the order they are
+// listed; callers hold sessionsMu
+func listedOrder() []string {
+	order := []string{}
+	for _, s := range sessions {
+		if !slices.Contains(order, s.Alias) {
+			order = append(order, s.Alias)
+		}
+	}
+	return order
+}
+
+// saveSessionOrder remembers order, from listedOrder, ahead of aliases
+// remembered before that have no session now. It reads and writes the
+// state file, so callers do not hold sessionsMu
+func saveSessionOrder(order []string) error {
+	for _, alias := range loadSessionOrder() {
+		if !slices.Contains(order, alias) {
+			order = append(order, alias)
+		}
+	}
+	return saveState(sessionOrderFile, order)
+}
+
+// insertSession adds a new session to the list, before the sessions of
+// hosts arranged after its own in order, from loadSessionOrder; hosts
+// never arranged go last. Callers hold sessionsMu
+func insertSession(session *Session, order []string) {
+	rank := func(alias string) int {
+		if i := slices.Index(order, alias); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+
+	at := len(sessions)
+	if own := rank(session.Alias); own < len(order) {
+		for i, s := range sessions {
+			if rank(s.Alias) > own {
+				at = i
+				break
+			}
+		}
+	}
+	sessions = slices.Insert(sessions, at, session)
+}
+
+// moveSession moves session !from to !to, renumbering those in between,
+// and remembers the new order
+func moveSession(from, to int) error {
+	sessionsMu.Lock()
+	if from < 1 || from > len(sessions) {
+		sessionsMu.Unlock()
+		return fmt.Errorf(tr("no session !%d"), from)
+	}
+	to = min(max(to, 1), len(sessions))
+	session := sessions[from-1]
+	sessions = slices.Delete(sessions, from-1, from)
+	sessions = slices.Insert(sessions, to-1, session)
+	order := listedOrder()
+	sessionsMu.Unlock()
+	return saveSessionOrder(order)
+}
+
+// reorderCommand runs a session order command of the bulk screen: k and
+// j move a session up or down, m puts it at a number. moving is the
+// session moved last, which k and j move again without a number; the
+// one moved is returned
+func reorderCommand(input string, moving int) (int, error) {
+	fields := strings.Fields(input)
+	numbers := []int{}
+	for _, f := range fields[1:] {
+		n, err := strconv.Atoi(strings.TrimPrefix(f, "!"))
+		if err != nil {
+			return moving, fmt.Errorf(tr("not a session number: %s"), f)
+		}
+		numbers = append(numbers, n)
+	}
+
+	from, to := moving, 0
+	switch {
+	case fields[0] == "m" && len(numbers) == 2:
+		from, to = numbers[0], numbers[1]
+	case fields[0] == "m":
+		return moving, errors.New(tr("usage: m FROM TO"))
+	case len(numbers) == 1:
+		from = numbers[0]
+		fallthrough
+	case len(numbers) == 0 && from > 0:
+		to = from - 1
+		if fields[0] == "j" {
+			to = from + 1
+		}
+	default:
+		return moving, fmt.Errorf(tr("usage: %s [number]"), fields[0])
+	}
+
+	if err := moveSession(from, to); err != nil {
+		return 0, err
+	}
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	return min(max(to, 1), len(sessions)), nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInsertSession(t *testing.T) {
+	t.Cleanup(func() { sessions = nil })
+	sessions = nil
+	order := []string{"db", "web"}
+
+	sessionsMu.Lock()
+	for _, alias := range []string{"web", "other", "db", "web"} {
+		insertSession(&Session{Alias: alias}, order)
+	}
+	listed := listedOrder()
+	aliases := []string{}
+	for _, s := range sessions {
+		aliases = append(aliases, s.Alias)
+	}
+	sessionsMu.Unlock()
+
+	if want := []string{"db", "web", "web", "other"}; !slices.Equal(aliases, want) {
+		t.Errorf("sessions %q, want %q", aliases, want)
+	}
+	if want := []string{"db", "web", "other"}; !slices.Equal(listed, want) {
+		t.Errorf("listed order %q, want %q", listed, want)
+	}
+}
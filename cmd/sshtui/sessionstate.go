@@ -0,0 +1,98 @@
+package main
+
+import "os"
+
+// Session states. A shell session is Connecting until ssh prints
+// something, then Ready; attaching and detaching move it between Attached
+// and Detached. When ssh exits it is Dead, or Reconnecting when the
+// watchdog restarts it
+const (
+	StateConnecting   = "connecting"
+	StateAuthPrompt   = "auth prompt"
+	StateTouchKey     = "touch key"
+	StateReady        = "ready"
+	StateDetached     = "detached"
+	StateAttached     = "attached"
+	StateDead         = "dead"
+	StateReconnecting = "reconnecting"
+)
+
+// stateColors are the SGR colors of each state in session lists
+var stateColors = map[string]string{
+	StateConnecting:   "33",
+	StateAuthPrompt:   "1;35",
+	StateTouchKey:     "1;33",
+	StateReady:        "32",
+	StateDetached:     "36",
+	StateAttached:     "1;32",
+	StateDead:         "31",
+	StateReconnecting: "33",
+}
+
+// running reports whether the session's ssh process is up; callers hold
+// sessionsMu
+func (s *Session) running() bool {
+	return s.State != StateDead && s.State != StateReconnecting
+}
+
+// coloredStatus is the translated, colored state for session lists;
+// callers hold sessionsMu
+func coloredStatus(s *Session) string {
+	return colorize(stateColors[s.State], tr(s.State))
+}
+
+// detachedState is the state of a running session nobody is attached to:
+// waiting at an auth prompt or for a hardware key touch, or otherwise
+// Detached once it has been attached and Ready before; callers hold
+// sessionsMu
+func detachedState(s *Session) string {
+	if waitingForAuth(s.Scrollback) || waitingForPinentry(s.Scrollback) {
+		return StateAuthPrompt
+	}
+	if waitingForTouch(s.Scrollback) {
+		return StateTouchKey
+	}
+	if s.attachedOnce {
+		return StateDetached
+	}
+	return StateReady
+}
+
+// appendScrollback adds output to the session history, keeping at most
+// the last MaxScrollbackSize bytes as they are, older ones compressed (see
+// compactScrollback); callers hold sessionsMu
+func appendScrollback(s *Session, p []byte) {
+	s.Scrollback = append(s.Scrollback, p...)
+	if len(s.Scrollback) > MaxScrollbackSize {
+		compactScrollback(s)
+	}
+}
+
+// readPTY publishes a shell session's output for its whole life, attached
+// or not
+func readPTY(session *Session, ptmx *os.File) {
+	buf := make([]byte, PtyBufSize)
+	for {
+		n, err := ptmx.Read(buf)
+		if n > 0 {
+			session.Output.Write(session.decodeOutput(buf[:n]))
+		}
+		if err != nil {
+			// Wake up the attached view, if any
+			sessionsMu.RLock()
+			stop := session.ioStop
+			if session.PTY != ptmx {
+				// Replaced by a reconnect
+				stop = nil
+			}
+			sessionsMu.RUnlock()
+			if stop != nil {
+				select {
+				case stop <- true:
+				default:
+				}
+			}
+			return
+		}
+	}
+}
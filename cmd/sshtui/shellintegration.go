@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// shellIntegrationRc makes bash print OSC 133 semantic prompt marks: D
+// with the exit status and A before the prompt, with the effective uid,
+// B after it, and C when a command starts (bash 4.4 and later)
+const shellIntegrationRc = `__sshtui_osc133() {
+  local status=$?
+  printf '\033]133;D;%s\007\033]133;A;euid=%s\007' "$status" "$EUID"
+  return $status
+}
+PROMPT_COMMAND="__sshtui_osc133${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+PS1="$PS1"'\[\033]133;B\007\]'
+PS0=$'\033]133;C\007'
+`
+
+const (
+	maxOSCLength   = 64   // longer OSC sequences are not semantic marks
+	maxTypedLength = 4096 // of a command line followed between B and C
+)
+
+// commandTracker follows the OSC 133 marks of a shell session's output to
+// record the last command run and its exit status. Marks reach the
+// terminal untouched, for terminals that navigate by prompt
+type commandTracker struct {
+	session *Session
+	escape  bool   // previous byte was ESC
+	inOSC   bool   // within an OSC sequence
+	osc     []byte // its text so far
+	typing  bool   // between B and C: the command line being typed
+	typed   []byte
+	ran     bool // a command was recorded since the last D
+}
+
+func (t *commandTracker) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch {
+		case t.inOSC && t.escape:
+			// ESC \ ends the sequence
+			t.escape = false
+			t.endOSC()
+		case t.inOSC && b == 0x1b:
+			t.escape = true
+		case t.inOSC && b == 0x07:
+			t.endOSC()
+		case t.inOSC:
+			if len(t.osc) < maxOSCLength {
+				t.osc = append(t.osc, b)
+			}
+		case t.escape:
+			t.escape = false
+			if b == ']' {
+				t.inOSC, t.osc = true, t.osc[:0]
+			} else {
+				t.keep(0x1b, b)
+			}
+		case b == 0x1b:
+			t.escape = true
+		default:
+			t.keep(b)
+		}
+	}
+	return len(p), nil
+}
+
+// keep collects the echo of a command line being typed
+func (t *commandTracker) keep(b ...byte) {
+	if t.typing && len(t.typed) < maxTypedLength {
+		t.typed = append(t.typed, b...)
+	}
+}
+
+func (t *commandTracker) endOSC() {
+	t.inOSC = false
+	mark, found := strings.CutPrefix(string(t.osc), "133;")
+	if !found || mark == "" {
+		return
+	}
+	switch mark[0] {
+	case 'B':
+		t.typing, t.typed = true, t.typed[:0]
+	case 'C':
+		t.finishCommand()
+	case 'A', 'D':
+		// Shells that mark no C: the command ran by the next prompt
+		t.finishCommand()
+		if euid, ok := strings.CutPrefix(mark, "A;euid="); ok {
+			setRoot(t.session, euid == "0")
+		}
+		if exit, ok := strings.CutPrefix(mark, "D;"); ok && t.ran {
+			sessionsMu.Lock()
+			t.session.LastExit = exit
+			sessionsMu.Unlock()
+			t.ran = false
+		}
+	}
+}
+
+// finishCommand records the command line typed since B, if any
+func (t *commandTracker) finishCommand() {
+	if !t.typing {
+		return
+	}
+	t.typing = false
+	command := typedCommand(string(t.typed))
+	if command == "" {
+		return
+	}
+	sessionsMu.Lock()
+	t.session.LastCommand, t.session.LastExit = command, ""
+	t.session.LastCommandTime = time.Now()
+	sessionsMu.Unlock()
+	t.ran = true
+}
+
+// typedCommand is the command line shown by the echo of typing: escape
+// sequences removed and backspaces applied
+func typedCommand(echo string) string {
+	text := []rune{}
+	for _, r := range escapePattern.ReplaceAllString(echo, "") {
+		switch {
+		case r == '\b' || r == 0x7f:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case r == '\n' || r == '\t':
+			text = append(text, ' ')
+		case r < 0x20:
+		default:
+			text = append(text, r)
+		}
+	}
+	return strings.TrimSpace(string(text))
+}
+
+// commandLabel returns the menu note of a session's last command, marked
+// when it failed; callers hold sessionsMu
+func commandLabel(s *Session) string {
+	if s.LastCommand == "" {
+		return ""
+	}
+	label := colorize("2", " $ "+truncateText(s.LastCommand, 40))
+	if s.LastExit != "" && s.LastExit != "0" {
+		label += colorize("31", " [exit "+s.LastExit+"]")
+	}
+	return label
+}
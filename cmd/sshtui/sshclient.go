@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ssh client features sshtui relies on
+const (
+	FeatureResolve       = "ssh -G"
+	FeatureJump          = "ProxyJump (-J)"
+	FeatureInclude       = "Include"
+	FeatureControlMaster = "ControlMaster (-O)"
+)
+
+// sshFeatureSince is the OpenSSH release adding each feature
+var sshFeatureSince = map[string][2]int{
+	FeatureResolve:       {6, 8},
+	FeatureJump:          {7, 3},
+	FeatureInclude:       {7, 3},
+	FeatureControlMaster: {4, 4},
+}
+
+// sshFeatures lists them in the order warnings name them
+var sshFeatures = []string{FeatureResolve, FeatureJump, FeatureInclude, FeatureControlMaster}
+
+var openSSHVersion = regexp.MustCompile(`OpenSSH_(\d+)\.(\d+)`)
+
+// SSHClient is the ssh found on PATH, as ssh -V describes it
+type SSHClient struct {
+	Version string // the first line of ssh -V, "" when it did not run
+	Err     error  // why ssh -V did not run
+	OpenSSH bool
+	Major   int
+	Minor   int
+}
+
+// sshClient is probed once at startup
+var sshClient SSHClient
+
+// probeSSHClient runs ssh -V, which prints the version on stderr
+func probeSSHClient() SSHClient {
+	out, err := commander.Command(context.Background(), "ssh", "-V").CombinedOutput()
+	client := SSHClient{Version: strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])}
+	if err != nil && client.Version == "" {
+		client.Err = err
+		return client
+	}
+	if m := openSSHVersion.FindStringSubmatch(client.Version); m != nil {
+		client.OpenSSH = true
+		client.Major, _ = strconv.Atoi(m[1])
+		client.Minor, _ = strconv.Atoi(m[2])
+	}
+	return client
+}
+
+// Supports tells whether the client has feature. Another client than
+// OpenSSH is not assumed to; an ssh that could not be probed is, so
+// sshtui goes on as before and ssh reports what fails
+func (c SSHClient) Supports(feature string) bool {
+	if c.Version == "" {
+		return true
+	}
+	if !c.OpenSSH {
+		return false
+	}
+	since := sshFeatureSince[feature]
+	return c.Major > since[0] || c.Major == since[0] && c.Minor >= since[1]
+}
+
+// Warnings describes what sshtui cannot do with this client, and how it
+// copes, for the notifications at startup
+func (c SSHClient) Warnings() []string {
+	if c.Err != nil {
+		return []string{fmt.Sprintf(tr("ssh -V failed (%v): connections will fail until ssh is on PATH"), c.Err)}
+	}
+	missing := []string{}
+	for _, feature := range sshFeatures {
+		if !c.Supports(feature) {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	warnings := []string{fmt.Sprintf(tr("%s lacks %s"), c.Version, strings.Join(missing, ", "))}
+	if !c.Supports(FeatureResolve) {
+		warnings = append(warnings, tr("host details show sshtui's own reading of ~/.ssh/config, not what ssh resolves"))
+	}
+	if !c.Supports(FeatureJump) {
+		warnings = append(warnings, tr("a single jump host goes through ProxyCommand ssh -W; chains of jump hosts fail"))
+	}
+	if !c.Supports(FeatureInclude) && appConfig.ManagedHosts {
+		warnings = append(warnings, tr("sshtui's hosts are reached by hostname, user and port only"))
+	}
+	if !c.Supports(FeatureControlMaster) {
+		warnings = append(warnings, tr("connection sharing (M) is off"))
+	}
+	return warnings
+}
+
+// jumpArgs returns the arguments reaching a host through jump, with -J
+// or, for clients without it, a ProxyCommand doing the same for one hop
+func jumpArgs(jump string) []string {
+	if sshClient.Supports(FeatureJump) || strings.Contains(jump, ",") {
+		return []string{"-J", jump}
+	}
+	user, hostName, port := parseTarget(jump)
+	command := "ssh -W %h:%p"
+	if user != "" {
+		command += " -l " + user
+	}
+	if port != "" {
+		command += " -p " + port
+	}
+	return []string{"-o", "ProxyCommand=" + command + " " + hostName}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerProvider(ssmSource, newSSMProvider)
+}
+
+// ssmSource is the provider name of the instances of AWS Systems Manager,
+// reached with aws ssm start-session instead of ssh
+const ssmSource = "ssm"
+
+// Metadata of SSM instances; the session command reads the instance id,
+// region and profile back from the host
+const (
+	MetaInstanceID = "id"
+	MetaPlatform   = "platform"
+	MetaRegion     = "region"
+	MetaProfile    = "profile"
+)
+
+// ssmInstances is the part of `aws ssm describe-instance-information` we use
+type ssmInstances struct {
+	InstanceInformationList []struct {
+		InstanceID   string `json:"InstanceId"`
+		PingStatus   string
+		PlatformName string
+		ComputerName string
+		IPAddress    string
+		Name         string // of hybrid nodes, from their activation
+	}
+}
+
+// ssmTags is the part of `aws ec2 describe-tags` we use
+type ssmTags struct {
+	Tags []struct {
+		ResourceID string `json:"ResourceId"`
+		Value      string
+	}
+}
+
+// ssmProvider lists the instances Systems Manager can open a session on,
+// named by a tag
+type ssmProvider struct {
+	region      string
+	profile     string
+	tag         string
+	match       string
+	hideOffline bool
+	cache       []SSHHost
+}
+
+// newSSMProvider accepts region=, profile= (default those of the aws
+// CLI), tag= (naming the hosts, default Name), match= (alias glob) and
+// offline=hide
+func newSSMProvider(options map[string]string) (HostProvider, error) {
+	p := &ssmProvider{tag: "Name"}
+	for key, value := range options {
+		switch key {
+		case "region":
+			p.region = value
+		case "profile":
+			p.profile = value
+		case "tag":
+			p.tag = value
+		case "match":
+			p.match = value
+		case "offline":
+			if value != "hide" && value != "show" {
+				return nil, fmt.Errorf(tr("provider %s: offline must be hide or show"), ssmSource)
+			}
+			p.hideOffline = value == "hide"
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), ssmSource, key)
+		}
+	}
+	return p, nil
+}
+
+func (p *ssmProvider) Name() string { return ssmSource }
+
+func (p *ssmProvider) Refresh() { p.cache = nil }
+
+func (p *ssmProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+
+	var instances ssmInstances
+	if err := p.aws(&instances, "ssm", "describe-instance-information"); err != nil {
+		return nil, err
+	}
+	// Without ec2:DescribeTags the instances keep their computer names
+	var tags ssmTags
+	p.aws(&tags, "ec2", "describe-tags", "--filters", "Name=key,Values="+p.tag, "Name=resource-type,Values=instance")
+	names := make(map[string]string)
+	for _, tag := range tags.Tags {
+		names[tag.ResourceID] = tag.Value
+	}
+
+	hosts := []SSHHost{}
+	seen := make(map[string]bool)
+	for _, instance := range instances.InstanceInformationList {
+		online := instance.PingStatus == "Online"
+		if p.hideOffline && !online {
+			continue
+		}
+		name := valueOr(names[instance.InstanceID], valueOr(instance.Name, strings.SplitN(instance.ComputerName, ".", 2)[0]))
+		alias := strings.Join(strings.Fields(name), "-")
+		if alias == "" || seen[alias] {
+			// Instances sharing a name are told apart by id
+			alias = instance.InstanceID
+		}
+
+		host, ok := labHost(alias, instance.IPAddress, "", p.match)
+		if !ok {
+			continue
+		}
+		seen[alias] = true
+		host.Meta = map[string]string{
+			MetaInstanceID: instance.InstanceID,
+			MetaPlatform:   instance.PlatformName,
+			MetaRegion:     p.region,
+			MetaProfile:    p.profile,
+		}
+		if !online {
+			host.Status = "offline"
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Alias < hosts[j].Alias })
+
+	p.cache = hosts
+	return hosts, nil
+}
+
+// aws runs an aws CLI command for the provider's region and profile and
+// decodes its JSON output into v
+func (p *ssmProvider) aws(v any, args ...string) error {
+	args = append(args, "--output", "json")
+	if p.region != "" {
+		args = append(args, "--region", p.region)
+	}
+	if p.profile != "" {
+		args = append(args, "--profile", p.profile)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("aws", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", ssmSource, msg)
+		}
+		return fmt.Errorf("%s: %v", ssmSource, err)
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("%s: %v", ssmSource, err)
+	}
+	return nil
+}
+
+// isSSM tells whether host is an SSM instance, opened without ssh
+func isSSM(host SSHHost) bool {
+	return host.Source == ssmSource
+}
+
+// ssmCommand returns the command line of a session on an SSM instance;
+// extra are start-session arguments, such as a document to run
+func ssmCommand(host SSHHost, extra ...string) []string {
+	argv := []string{"aws", "ssm", "start-session", "--target", host.Meta[MetaInstanceID]}
+	if region := host.Meta[MetaRegion]; region != "" {
+		argv = append(argv, "--region", region)
+	}
+	if profile := host.Meta[MetaProfile]; profile != "" {
+		argv = append(argv, "--profile", profile)
+	}
+	return append(argv, extra...)
+}
+
+// ssmRunCommand returns the command line running command on an SSM
+// instance, in a session of the AWS-StartInteractiveCommand document.
+// Session Manager does not report the command's exit code
+func ssmRunCommand(host SSHHost, command string) ([]string, error) {
+	parameters, err := json.Marshal(map[string][]string{"command": {command}})
+	if err != nil {
+		return nil, err
+	}
+	return ssmCommand(host, "--document-name", "AWS-StartInteractiveCommand", "--parameters", string(parameters)), nil
+}
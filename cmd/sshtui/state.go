@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// stateDir returns sshtui's directory for remembered settings
+func stateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(home, ".config", "sshtui")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// loadState decodes a JSON state file into v; a missing file is not an error
+func loadState(name string, v interface{}) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// saveState writes v as JSON to a state file
+func saveState(name string, v interface{}) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0600)
+}
+
+// loadExtras returns the last-used extra ssh options per host alias
+func loadExtras() map[string][]string {
+	extras := make(map[string][]string)
+	loadState("extras.json", &extras)
+	return extras
+}
+
+func saveExtras(alias string, args []string) error {
+	extras := loadExtras()
+	if len(args) == 0 {
+		delete(extras, alias)
+	} else {
+		extras[alias] = args
+	}
+	return saveState("extras.json", extras)
+}
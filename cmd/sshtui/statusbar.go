@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/creack/pty"
+)
+
+// StatusBarRows is how many top rows the status bar keeps for itself
+const StatusBarRows = 1
+
+// startStatusBar reserves the top of the terminal for the status bar:
+// the rows below become the scroll region (DECSTBM) and, with origin
+// mode, the remote shell's row 1
+func startStatusBar(session *Session) {
+	ws, err := term.Size()
+	if err != nil || ws.Rows <= StatusBarRows {
+		return
+	}
+	fmt.Print("\033[2J")
+	setScrollRegion(int(ws.Rows))
+	drawStatusBar(session)
+}
+
+// stopStatusBar gives the whole terminal back
+func stopStatusBar() {
+	fmt.Print("\033[?6l\033[r")
+}
+
+// setScrollRegion keeps session output below the bar; it homes the cursor
+// to the region's first row
+func setScrollRegion(rows int) {
+	fmt.Printf("\033[%d;%dr\033[?6h", StatusBarRows+1, rows)
+}
+
+// drawStatusBar writes the session name, state and keys on the top row,
+// saving and restoring the cursor (and origin mode) around it
+func drawStatusBar(session *Session) {
+	ws, err := term.Size()
+	if err != nil || ws.Cols == 0 {
+		return
+	}
+
+	sessionsMu.RLock()
+	text := fmt.Sprintf(" %s [%s]  %s  %s  %s", session.Alias, tr(session.State),
+		tr("Ctrl+Space detach"), tr("Ctrl+] send file"), tr("Ctrl+^ I/O stats"))
+	badge := ""
+	if session.Root {
+		// Red, ahead of the rest, so a root shell is hard to miss
+		badge = " " + tr("ROOT") + " "
+	}
+	sessionsMu.RUnlock()
+
+	cols := max(int(ws.Cols)-len([]rune(badge)), 0)
+	runes := []rune(text)
+	if len(runes) > cols {
+		runes = runes[:cols]
+	}
+	text = string(runes) + strings.Repeat(" ", cols-len(runes))
+	if badge != "" {
+		text = colorize("1;37;41", badge) + colorize("7", text)
+	} else {
+		text = colorize("7", text)
+	}
+	fmt.Printf("\0337\033[?6l\033[1;1H%s\0338", text)
+}
+
+// redrawStatusBar follows a terminal resize without moving the cursor
+func redrawStatusBar(session *Session, rows int) {
+	session.Output.Hold(func() {
+		fmt.Print("\0337")
+		setScrollRegion(rows)
+		fmt.Print("\0338")
+		drawStatusBar(session)
+	})
+}
+
+// statusBarWinsize is the terminal size minus the bar, as the remote
+// shell should see it
+func statusBarWinsize() (*pty.Winsize, error) {
+	ws, err := term.Size()
+	if err != nil {
+		return nil, err
+	}
+	if ws.Rows > StatusBarRows {
+		ws.Rows -= StatusBarRows
+	}
+	return ws, nil
+}
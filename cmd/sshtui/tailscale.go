@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerProvider("tailscale", newTailscaleProvider)
+}
+
+// tailscaleStatus is the part of `tailscale status --json` we use
+type tailscaleStatus struct {
+	Peer map[string]struct {
+		HostName     string
+		DNSName      string
+		TailscaleIPs []string
+		Online       bool
+	}
+}
+
+// tailscaleProvider lists tailnet peers by their MagicDNS names
+type tailscaleProvider struct {
+	user        string
+	match       string
+	hideOffline bool
+	cache       []SSHHost
+}
+
+// newTailscaleProvider accepts user=, match= (alias glob) and offline=hide
+func newTailscaleProvider(options map[string]string) (HostProvider, error) {
+	p := &tailscaleProvider{}
+	for key, value := range options {
+		switch key {
+		case "user":
+			p.user = value
+		case "match":
+			p.match = value
+		case "offline":
+			if value != "hide" && value != "show" {
+				return nil, fmt.Errorf(tr("provider %s: offline must be hide or show"), "tailscale")
+			}
+			p.hideOffline = value == "hide"
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), "tailscale", key)
+		}
+	}
+	return p, nil
+}
+
+func (p *tailscaleProvider) Name() string { return "tailscale" }
+
+func (p *tailscaleProvider) Refresh() { p.cache = nil }
+
+func (p *tailscaleProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+
+	out, err := exec.Command("tailscale", "status", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("tailscale: %v", err)
+	}
+	var status tailscaleStatus
+	if err := json.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("tailscale: %v", err)
+	}
+
+	hosts := []SSHHost{}
+	for _, peer := range status.Peer {
+		if p.hideOffline && !peer.Online {
+			continue
+		}
+		// MagicDNS name without the tailnet suffix, e.g. "laptop"
+		dnsName := strings.TrimSuffix(peer.DNSName, ".")
+		alias := strings.SplitN(dnsName, ".", 2)[0]
+		if alias == "" {
+			alias = strings.ToLower(peer.HostName)
+		}
+		hostname := dnsName
+		if hostname == "" && len(peer.TailscaleIPs) > 0 {
+			hostname = peer.TailscaleIPs[0]
+		}
+
+		host, ok := labHost(alias, hostname, p.user, p.match)
+		if !ok {
+			continue
+		}
+		if !peer.Online {
+			host.Status = "offline"
+		}
+		hosts = append(hosts, host)
+	}
+	// Peers come from a map; keep the menu order stable
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Alias < hosts[j].Alias })
+
+	p.cache = hosts
+	return hosts, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// TeeQueueSize is how many output chunks may wait for a slow tee command
+// before new output is dropped instead of stalling the session
+const TeeQueueSize = 256
+
+// OutputTee pipes a session's output into a local shell command
+type OutputTee struct {
+	Command string
+	Dropped int // chunks dropped because the command fell behind
+	cmd     *exec.Cmd
+	queue   chan []byte
+	mu      sync.Mutex
+	closed  bool
+}
+
+// startTee runs command through sh with the session output on its stdin
+func startTee(command string) (*OutputTee, error) {
+	cmd := exec.Command("sh", "-c", command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	t := &OutputTee{Command: command, cmd: cmd, queue: make(chan []byte, TeeQueueSize)}
+	go func() {
+		for chunk := range t.queue {
+			if _, err := stdin.Write(chunk); err != nil {
+				break
+			}
+		}
+		stdin.Close()
+		// Keep draining so senders never block on a dead command
+		for range t.queue {
+		}
+	}()
+	go cmd.Wait()
+	return t, nil
+}
+
+// Write queues output for the command without ever blocking the session;
+// output after Close is discarded
+func (t *OutputTee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return len(p), nil
+	}
+	chunk := append([]byte(nil), p...)
+	select {
+	case t.queue <- chunk:
+	default:
+		t.Dropped++
+	}
+	return len(p), nil
+}
+
+// Close ends the command's input; the command exits on EOF
+func (t *OutputTee) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+}
+
+// String describes the tee for session details
+func (t *OutputTee) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cmd.ProcessState != nil {
+		return fmt.Sprintf(tr("%s (exited: %s)"), t.Command, t.cmd.ProcessState)
+	}
+	if t.Dropped > 0 {
+		return fmt.Sprintf(tr("%s (%d chunks dropped)"), t.Command, t.Dropped)
+	}
+	return t.Command
+}
+
+// setSessionTee replaces the session's tee; an empty command removes it
+func setSessionTee(session *Session, command string) error {
+	var tee *OutputTee
+	if command != "" {
+		var err error
+		if tee, err = startTee(command); err != nil {
+			return err
+		}
+	}
+
+	sessionsMu.Lock()
+	old := session.Tee
+	session.Tee = tee
+	sessionsMu.Unlock()
+
+	if tee != nil {
+		session.Output.Subscribe(SubscriberTee, maskedWriter{&session.secret, tee})
+	} else {
+		session.Output.Unsubscribe(SubscriberTee)
+	}
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// promptTee asks for the command receiving the session output
+func promptTee(session *Session, reader *bufio.Reader) {
+	fmt.Println(tr("\nLocal command fed with the session output, e.g. grep --line-buffered ERROR >> alerts.log"))
+	fmt.Print(tr("Enter keeps the current one, '-' removes it: "))
+
+	input, _ := reader.ReadString('\n')
+	switch input = strings.TrimSpace(input); input {
+	case "":
+		return
+	case "-":
+		input = ""
+	}
+
+	if err := setSessionTee(session, input); err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+	}
+}
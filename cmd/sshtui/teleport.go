@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerProvider(teleportSource, newTeleportProvider)
+}
+
+// teleportSource is the provider name of Teleport nodes, reached with
+// tsh ssh instead of ssh
+const teleportSource = "teleport"
+
+// MetaProxy is the Teleport proxy of a node, when the provider names one
+const MetaProxy = "proxy"
+
+// TeleportExpiryWarn is how long before the Teleport certificate expires
+// the menu starts saying so
+const TeleportExpiryWarn = 15 * time.Minute
+
+// teleportNode is the part of a node of `tsh ls --format=json` we use
+type teleportNode struct {
+	Metadata struct {
+		Labels map[string]string
+	}
+	Spec struct {
+		Hostname string
+	}
+}
+
+// teleportCluster is the part of a cluster of `tsh clusters --format=json`
+// we use
+type teleportCluster struct {
+	Name string `json:"cluster_name"`
+}
+
+// teleportStatus is the part of `tsh status --format=json` we use
+type teleportStatus struct {
+	Active struct {
+		Username   string
+		Cluster    string
+		ValidUntil time.Time `json:"valid_until"`
+	}
+}
+
+// teleportExpiry is when the certificate of the Teleport login expires,
+// as last seen; zero when there is no login
+var teleportExpiry time.Time
+
+// teleportProvider lists the nodes of the Teleport clusters tsh is
+// logged in to
+type teleportProvider struct {
+	proxy    string
+	user     string
+	match    string
+	clusters []string // "" for tsh's current cluster
+	all      bool     // every cluster tsh knows, leaf ones included
+	cache    []SSHHost
+	listed   []SSHHost // the last nodes listed, kept while logged out
+}
+
+// newTeleportProvider accepts proxy=, user= (the login), match= (alias
+// glob) and clusters= (comma-separated, or all)
+func newTeleportProvider(options map[string]string) (HostProvider, error) {
+	p := &teleportProvider{clusters: []string{""}}
+	for key, value := range options {
+		switch key {
+		case "proxy":
+			p.proxy = value
+		case "user":
+			p.user = value
+		case "match":
+			p.match = value
+		case "clusters":
+			p.all = value == "all"
+			p.clusters = strings.Split(value, ",")
+		default:
+			return nil, fmt.Errorf(tr("provider %s: unknown option %s"), teleportSource, key)
+		}
+	}
+	return p, nil
+}
+
+func (p *teleportProvider) Name() string { return teleportSource }
+
+func (p *teleportProvider) Refresh() { p.cache = nil }
+
+func (p *teleportProvider) Load() ([]SSHHost, error) {
+	if p.cache != nil {
+		return p.cache, nil
+	}
+	if _, err := exec.LookPath("tsh"); err != nil {
+		return nil, fmt.Errorf(tr("%s: tsh is not on PATH"), teleportSource)
+	}
+
+	// Listing needs a valid login: while there is none, the nodes listed
+	// before stay, and connecting to one offers to log in again
+	status, err := loadTeleportStatus(p.proxy)
+	if err != nil || time.Now().After(status.Active.ValidUntil) {
+		if p.listed != nil {
+			return p.listed, nil
+		}
+		// Without nodes to connect to, the error says it all
+		teleportExpiry = time.Time{}
+		return nil, fmt.Errorf(tr("%s: not logged in, run tsh login then r"), teleportSource)
+	}
+
+	clusters := p.clusters
+	if p.all {
+		var list []teleportCluster
+		if err := p.tsh(&list, "clusters"); err != nil {
+			return nil, err
+		}
+		clusters = nil
+		for _, cluster := range list {
+			clusters = append(clusters, cluster.Name)
+		}
+	}
+
+	hosts := []SSHHost{}
+	seen := make(map[string]bool)
+	for _, cluster := range clusters {
+		var nodes []teleportNode
+		args := []string{"ls"}
+		if cluster != "" {
+			args = append(args, "--cluster="+cluster)
+		}
+		if err := p.tsh(&nodes, args...); err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			alias := node.Spec.Hostname
+			if seen[alias] {
+				// Clusters may have nodes of the same name
+				alias += "." + valueOr(cluster, status.Active.Cluster)
+			}
+			host, ok := labHost(alias, node.Spec.Hostname, p.user, p.match)
+			if !ok {
+				continue
+			}
+			seen[alias] = true
+			host.Meta = map[string]string{MetaCluster: valueOr(cluster, status.Active.Cluster), MetaProxy: p.proxy}
+			for label, value := range node.Metadata.Labels {
+				host.Meta[strings.ToLower(label)] = value
+			}
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Alias < hosts[j].Alias })
+
+	p.cache, p.listed = hosts, hosts
+	return hosts, nil
+}
+
+// tsh runs a tsh listing command against the provider's proxy and decodes
+// its JSON output into v
+func (p *teleportProvider) tsh(v any, args ...string) error {
+	args = append(args, "--format=json")
+	if p.proxy != "" {
+		args = append(args, "--proxy="+p.proxy)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("tsh", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", teleportSource, msg)
+		}
+		return fmt.Errorf("%s: %v", teleportSource, err)
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("%s: %v", teleportSource, err)
+	}
+	return nil
+}
+
+// loadTeleportStatus reads the active Teleport login and records when its
+// certificate expires
+func loadTeleportStatus(proxy string) (teleportStatus, error) {
+	var status teleportStatus
+	args := []string{"status", "--format=json"}
+	if proxy != "" {
+		args = append(args, "--proxy="+proxy)
+	}
+	out, err := exec.Command("tsh", args...).Output()
+	if err == nil {
+		err = json.Unmarshal(out, &status)
+	}
+	if err == nil && status.Active.ValidUntil.IsZero() {
+		err = errors.New(tr("not logged in"))
+	}
+	teleportExpiry = status.Active.ValidUntil
+	return status, err
+}
+
+// isTeleport tells whether host is a Teleport node, opened with tsh
+func isTeleport(host SSHHost) bool {
+	return host.Source == teleportSource
+}
+
+// teleportCommand returns the command line of a session on a Teleport
+// node; extra is a command to run instead of a shell
+func teleportCommand(host SSHHost, extra ...string) []string {
+	argv := []string{"tsh", "ssh"}
+	if proxy := host.Meta[MetaProxy]; proxy != "" {
+		argv = append(argv, "--proxy="+proxy)
+	}
+	if cluster := host.Meta[MetaCluster]; cluster != "" {
+		argv = append(argv, "--cluster="+cluster)
+	}
+	target := host.HostName
+	if user := effectiveUser(host); user != "" {
+		target = user + "@" + target
+	}
+	return append(append(argv, target), extra...)
+}
+
+// teleportLogin describes the Teleport login for host details
+func teleportLogin(host SSHHost) string {
+	status, err := loadTeleportStatus(host.Meta[MetaProxy])
+	if err != nil {
+		return tr("not logged in")
+	}
+	until := status.Active.ValidUntil.Local().Format(time.DateTime)
+	if time.Now().After(status.Active.ValidUntil) {
+		return fmt.Sprintf(tr("%s on %s, certificate expired on %s"), status.Active.Username, status.Active.Cluster, until)
+	}
+	return fmt.Sprintf(tr("%s on %s, certificate valid until %s (%s left)"), status.Active.Username, status.Active.Cluster,
+		until, time.Until(status.Active.ValidUntil).Round(time.Minute))
+}
+
+// teleportWarning tells in the menu that the Teleport certificate expired
+// or soon will, "" otherwise
+func teleportWarning() string {
+	switch {
+	case teleportExpiry.IsZero():
+		return ""
+	case time.Now().After(teleportExpiry):
+		return fmt.Sprintf(tr("Teleport certificate expired on %s: connecting to a node logs in again"), teleportExpiry.Local().Format(time.DateTime))
+	case time.Until(teleportExpiry) < TeleportExpiryWarn:
+		return fmt.Sprintf(tr("Teleport certificate expires at %s"), teleportExpiry.Local().Format(time.TimeOnly))
+	}
+	return ""
+}
+
+// confirmTeleportLogin makes sure there is a valid Teleport login before
+// connecting to a node, offering to run tsh login when there is none
+func confirmTeleportLogin(host SSHHost) bool {
+	proxy := host.Meta[MetaProxy]
+	status, err := loadTeleportStatus(proxy)
+	if err == nil && time.Until(status.Active.ValidUntil) > time.Minute {
+		return true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	switch {
+	case err != nil:
+		fmt.Println(colorize("1;33", tr("\nNot logged in to Teleport.")))
+	case time.Now().After(status.Active.ValidUntil):
+		fmt.Println(colorize("1;33", fmt.Sprintf(tr("\nTeleport certificate expired on %s."), status.Active.ValidUntil.Local().Format(time.DateTime))))
+	default:
+		fmt.Println(colorize("1;33", fmt.Sprintf(tr("\nTeleport certificate expires at %s."), status.Active.ValidUntil.Local().Format(time.TimeOnly))))
+	}
+	fmt.Print(tr("Log in with tsh login? [Y/n]: "))
+	input, _ := reader.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "" && answer != "y" && answer != "yes" {
+		return false
+	}
+
+	args := []string{"login"}
+	if proxy != "" {
+		args = append(args, "--proxy="+proxy)
+	}
+	cmd := exec.Command("tsh", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return false
+	}
+	loadTeleportStatus(proxy)
+	return true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+// Terminal is the user's terminal as sessions drive it: keys are read
+// from it, modes switched and its size asked for resizes
+type Terminal interface {
+	Read(p []byte) (int, error)
+	// MakeRaw and MakeCbreak return the function putting the mode back
+	MakeRaw() (func(), error)
+	MakeCbreak() (func(), error)
+	// InputWithin reports whether input arrives before timeout
+	InputWithin(timeout time.Duration) bool
+	// Drain drops the input waiting to be read and tells how many bytes
+	Drain() int
+	Size() (*pty.Winsize, error)
+}
+
+// Commander makes the commands reaching hosts, ssh in practice
+type Commander interface {
+	Command(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+// The session engine reaches the system through these; pkg/ptysession/ptytest
+// has fakes for running sessions headless
+var (
+	term       Terminal           = stdinTerminal{}
+	commander  Commander          = execCommander{}
+	ptyStarter ptysession.Starter = ptysession.PTY
+)
+
+// stdinTerminal is the terminal sshtui runs in
+type stdinTerminal struct{}
+
+func (stdinTerminal) Read(p []byte) (int, error) {
+	return os.Stdin.Read(p)
+}
+
+func (stdinTerminal) MakeRaw() (func(), error) {
+	oldState, err := makeRaw(os.Stdin.Fd())
+	if err != nil {
+		return nil, err
+	}
+	return func() { restore(os.Stdin.Fd(), oldState) }, nil
+}
+
+func (stdinTerminal) MakeCbreak() (func(), error) {
+	oldState, err := makeCbreak(os.Stdin.Fd())
+	if err != nil {
+		return nil, err
+	}
+	return func() { restore(os.Stdin.Fd(), oldState) }, nil
+}
+
+func (stdinTerminal) InputWithin(timeout time.Duration) bool {
+	return inputWithin(os.Stdin.Fd(), timeout)
+}
+
+func (stdinTerminal) Drain() int {
+	// Non-blocking for the time of reading what is there
+	fd := int(os.Stdin.Fd())
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		return 0
+	}
+	defer syscall.SetNonblock(fd, false)
+
+	buf := make([]byte, 1024)
+	drained := 0
+	for {
+		n, err := os.Stdin.Read(buf)
+		drained += n
+		if err != nil || n == 0 {
+			return drained
+		}
+	}
+}
+
+func (stdinTerminal) Size() (*pty.Winsize, error) {
+	return pty.GetsizeFull(os.Stdin)
+}
+
+// execCommander runs commands from PATH
+type execCommander struct{}
+
+func (execCommander) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
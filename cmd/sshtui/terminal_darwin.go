@@ -0,0 +1,80 @@
+// +build darwin
+
+package main
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func makeRaw(fd uintptr) (*syscall.Termios, error) {
+	var oldState syscall.Termios
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&oldState)), 0, 0, 0); err != 0 {
+		return nil, err
+	}
+
+	newState := oldState
+	newState.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	newState.Oflag &^= syscall.OPOST
+	newState.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	newState.Cflag &^= syscall.CSIZE | syscall.PARENB
+	newState.Cflag |= syscall.CS8
+
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCSETA, uintptr(unsafe.Pointer(&newState)), 0, 0, 0); err != 0 {
+		return nil, err
+	}
+
+	return &oldState, nil
+}
+
+func restore(fd uintptr, state *syscall.Termios) error {
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCSETA, uintptr(unsafe.Pointer(state)), 0, 0, 0); err != 0 {
+		return err
+	}
+	return nil
+}
+
+// makeCbreak turns off line buffering and echo only, keeping output
+// processing and signal keys, for reading a line key by key
+func makeCbreak(fd uintptr) (*syscall.Termios, error) {
+	var oldState syscall.Termios
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&oldState)), 0, 0, 0); err != 0 {
+		return nil, err
+	}
+
+	newState := oldState
+	newState.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON
+	newState.Cc[syscall.VMIN] = 1
+	newState.Cc[syscall.VTIME] = 0
+
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCSETA, uintptr(unsafe.Pointer(&newState)), 0, 0, 0); err != 0 {
+		return nil, err
+	}
+
+	return &oldState, nil
+}
+
+// inputWithin waits up to timeout for input on fd
+func inputWithin(fd uintptr, timeout time.Duration) bool {
+	var set syscall.FdSet
+	bits := uintptr(unsafe.Sizeof(set.Bits[0])) * 8
+	set.Bits[fd/bits] |= 1 << (fd % bits)
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	for {
+		err := syscall.Select(int(fd)+1, &set, nil, nil, &tv)
+		if err != syscall.EINTR {
+			return err == nil && set.Bits[fd/bits]&(1<<(fd%bits)) != 0
+		}
+	}
+}
+
+// secretEntry reports whether the terminal on fd reads a line without
+// echoing it, as a password prompt does
+func secretEntry(fd uintptr) bool {
+	var state syscall.Termios
+	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&state)), 0, 0, 0); err != 0 {
+		return false
+	}
+	return state.Lflag&syscall.ECHO == 0 && state.Lflag&syscall.ICANON != 0
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dayFormat = "2006-01-02"
+
+// TimeLog maps day (YYYY-MM-DD) -> host alias -> attached seconds
+type TimeLog map[string]map[string]int64
+
+func loadTimeLog() TimeLog {
+	log := make(TimeLog)
+	loadState("timetrack.json", &log)
+	return log
+}
+
+// recordAttachTime adds an attach period to the time log, split across days
+func recordAttachTime(alias string, start time.Time) {
+	end := time.Now()
+	log := loadTimeLog()
+
+	for start.Before(end) {
+		y, m, d := start.Date()
+		nextDay := time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+		chunkEnd := end
+		if nextDay.Before(end) {
+			chunkEnd = nextDay
+		}
+
+		day := start.Format(dayFormat)
+		if log[day] == nil {
+			log[day] = make(map[string]int64)
+		}
+		log[day][alias] += int64(chunkEnd.Sub(start).Seconds())
+		start = chunkEnd
+	}
+
+	saveState("timetrack.json", log)
+}
+
+// hostTotal is one line of a time report
+type hostTotal struct {
+	Alias   string
+	Seconds int64
+}
+
+// totalsSince sums attached time per host from the given day on
+func totalsSince(log TimeLog, since time.Time) []hostTotal {
+	from := since.Format(dayFormat)
+	sums := make(map[string]int64)
+	for day, hosts := range log {
+		if day < from {
+			continue
+		}
+		for alias, secs := range hosts {
+			sums[alias] += secs
+		}
+	}
+
+	totals := []hostTotal{}
+	for alias, secs := range sums {
+		totals = append(totals, hostTotal{alias, secs})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Seconds != totals[j].Seconds {
+			return totals[i].Seconds > totals[j].Seconds
+		}
+		return totals[i].Alias < totals[j].Alias
+	})
+	return totals
+}
+
+// formatDuration renders seconds as 3h12m
+func formatDuration(secs int64) string {
+	d := time.Duration(secs) * time.Second
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
+
+// exportTimeLog writes the per-day log as CSV (day,host,seconds)
+func exportTimeLog(log TimeLog, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	days := make([]string, 0, len(log))
+	for day := range log {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"day", "host", "seconds"})
+	for _, day := range days {
+		aliases := make([]string, 0, len(log[day]))
+		for alias := range log[day] {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		for _, alias := range aliases {
+			w.Write([]string{day, alias, fmt.Sprintf("%d", log[day][alias])})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// showTimeReport displays attached time per host for a selectable period
+func showTimeReport() {
+	reader := bufio.NewReader(os.Stdin)
+	days := 7
+
+	for {
+		log := loadTimeLog()
+		now := time.Now()
+		since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, now.Location())
+
+		clearScreen()
+		printHeader(fmt.Sprintf(tr("Time Report (last %d days)"), days))
+
+		totals := totalsSince(log, since)
+		if len(totals) == 0 {
+			fmt.Println(tr("  No attached time recorded"))
+		}
+		var sum int64
+		for _, t := range totals {
+			fmt.Printf("  %-30s %8s\n", t.Alias, formatDuration(t.Seconds))
+			sum += t.Seconds
+		}
+		if len(totals) > 0 {
+			fmt.Printf("\n  %-30s %8s\n", tr("Total"), formatDuration(sum))
+		}
+
+		fmt.Println(tr("\nCommands:"))
+		fmt.Println(tr("  d         - Today"))
+		fmt.Println(tr("  w         - Last 7 days"))
+		fmt.Println(tr("  m         - Last 30 days"))
+		fmt.Println(tr("  e         - Export CSV"))
+		fmt.Println(tr("  q         - Back to main menu"))
+		fmt.Print("\n> ")
+
+		input, _ := reader.ReadString('\n')
+		switch strings.TrimSpace(input) {
+		case "q":
+			return
+		case "d":
+			days = 1
+		case "w":
+			days = 7
+		case "m":
+			days = 30
+		case "e":
+			dir, err := stateDir()
+			if err == nil {
+				path := filepath.Join(dir, "time-report.csv")
+				if err = exportTimeLog(log, path); err == nil {
+					fmt.Printf(tr("Exported to %s\nPress Enter..."), path)
+					reader.ReadString('\n')
+					continue
+				}
+			}
+			fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+			reader.ReadString('\n')
+		}
+	}
+}
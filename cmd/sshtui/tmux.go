@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TmuxSession is a tmux session running on a remote host
+type TmuxSession struct {
+	Name     string
+	Windows  string
+	Attached bool
+}
+
+// listRemoteTmux runs tmux ls on the host; no server running means no sessions
+func listRemoteTmux(host SSHHost) ([]TmuxSession, error) {
+	if err := refreshCertificate(host); err != nil {
+		return nil, err
+	}
+	remote := `tmux ls -F '#{session_name}	#{session_windows}	#{session_attached}' 2>&1 || true`
+	args := append([]string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5"}, buildExecArgs(host, remote)...)
+
+	var stderr bytes.Buffer
+	cmd := commander.Command(context.Background(), "ssh", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+
+	output := string(out)
+	if strings.Contains(output, "command not found") {
+		return nil, errors.New(tr("tmux is not installed on the host"))
+	}
+	if strings.Contains(output, "no server running") || strings.Contains(output, "error connecting") {
+		return nil, nil
+	}
+
+	list := []TmuxSession{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		list = append(list, TmuxSession{Name: fields[0], Windows: fields[1], Attached: fields[2] != "0"})
+	}
+	return list, nil
+}
+
+// chooseRemoteTmux lists the host's tmux sessions and connects attached to
+// the chosen one, or to a new one
+func chooseRemoteTmux(host SSHHost) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Printf(tr("\nListing tmux sessions on %s...\n"), host.Alias)
+	list, err := listRemoteTmux(host)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		reader.ReadString('\n')
+		return
+	}
+
+	clearScreen()
+	printHeader(tr("tmux sessions: ") + host.Alias)
+	if len(list) == 0 {
+		fmt.Println(tr("No tmux sessions"))
+	}
+	for i, s := range list {
+		attached := ""
+		if s.Attached {
+			attached = tr(" (attached)")
+		}
+		fmt.Printf(tr("  [%d] %s - %s windows%s\n"), i+1, s.Name, s.Windows, attached)
+	}
+
+	fmt.Println(tr("\nCommands:"))
+	fmt.Println(tr("  [number]  - Attach to tmux session"))
+	fmt.Println(tr("  n [name]  - New tmux session"))
+	fmt.Println(tr("  q         - Back to main menu"))
+	fmt.Print("\n> ")
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	var num int
+	switch {
+	case input == "n" || strings.HasPrefix(input, "n "):
+		host.Command = "tmux new"
+		if name := strings.TrimSpace(strings.TrimPrefix(input, "n")); name != "" {
+			host.Command += " -s " + shellQuote(name)
+		}
+	case input != "q" && input != "":
+		if _, err := fmt.Sscanf(input, "%d", &num); err != nil || num < 1 || num > len(list) {
+			return
+		}
+		host.Command = "tmux attach -t " + shellQuote(list[num-1].Name)
+	default:
+		return
+	}
+
+	createSession(host)
+}
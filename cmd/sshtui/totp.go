@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TOTP modes of a Host block
+const (
+	TOTPShow = "show" // the current code is shown in the attach banner
+	TOTPType = "type" // and typed at verification code prompts
+)
+
+const (
+	// TOTPMaxTyped bounds the codes typed in one session, so a wrong
+	// secret does not answer every prompt that follows
+	TOTPMaxTyped = 2
+	// TOTPMinRemaining is the life a typed code must have left; closer
+	// to the end of its period, the next code is waited for
+	TOTPMinRemaining = 3 * time.Second
+)
+
+// totpPrompts are the fragments of authPrompts asking for a one-time code
+var totpPrompts = []string{"verification code", "one-time password", "otp:", "token:"}
+
+// totpKey is a TOTP secret with its RFC 6238 parameters
+type totpKey struct {
+	secret []byte
+	digits int
+	period time.Duration
+	hash   func() hash.Hash
+}
+
+// parseTOTPKey reads a base32 secret, as authenticator apps show it, or an
+// otpauth:// URI, as pass-otp and exports keep it
+func parseTOTPKey(text string) (totpKey, error) {
+	key := totpKey{digits: 6, period: 30 * time.Second, hash: sha1.New}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "otpauth://") {
+		u, err := url.Parse(text)
+		if err != nil {
+			return key, err
+		}
+		query := u.Query()
+		text = query.Get("secret")
+		if digits, err := strconv.Atoi(query.Get("digits")); err == nil && digits >= 6 && digits <= 10 {
+			key.digits = digits
+		}
+		if period, err := strconv.Atoi(query.Get("period")); err == nil && period > 0 {
+			key.period = time.Duration(period) * time.Second
+		}
+		switch strings.ToUpper(query.Get("algorithm")) {
+		case "", "SHA1":
+		case "SHA256":
+			key.hash = sha256.New
+		case "SHA512":
+			key.hash = sha512.New
+		default:
+			return key, fmt.Errorf(tr("unsupported TOTP algorithm %s"), query.Get("algorithm"))
+		}
+	}
+
+	text = strings.TrimRight(strings.ToUpper(strings.ReplaceAll(text, " ", "")), "=")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(text)
+	if err != nil || len(secret) == 0 {
+		return key, errors.New(tr("the TOTP secret is not base32 or an otpauth:// URI"))
+	}
+	key.secret = secret
+	return key, nil
+}
+
+// code returns the code valid at t and how long it stays valid
+func (k totpKey) code(t time.Time) (string, time.Duration) {
+	period := int64(k.period / time.Second)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/period))
+	mac := hmac.New(k.hash, k.secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for range k.digits {
+		modulo *= 10
+	}
+	remaining := time.Duration(period-t.Unix()%period) * time.Second
+	return fmt.Sprintf("%0*d", k.digits, value%modulo), remaining
+}
+
+var (
+	totpKeys   = map[string]totpKey{} // by TOTPSecret command, fetched once
+	totpKeysMu sync.Mutex
+)
+
+// hostTOTPKey runs the host's TOTPSecret command, once per sshtui run as
+// a keychain may ask to be unlocked each time
+func hostTOTPKey(host SSHHost) (totpKey, error) {
+	totpKeysMu.Lock()
+	defer totpKeysMu.Unlock()
+	if key, ok := totpKeys[host.TOTPSecret]; ok {
+		return key, nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", host.TOTPSecret)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return totpKey{}, fmt.Errorf(tr("TOTPSecret command failed: %v %s"), err, strings.TrimSpace(stderr.String()))
+	}
+	key, err := parseTOTPKey(string(out))
+	if err != nil {
+		return key, err
+	}
+	totpKeys[host.TOTPSecret] = key
+	return key, nil
+}
+
+// totpBanner is the attach banner line with the host's current code, ""
+// for hosts without TOTP
+func totpBanner(host SSHHost) string {
+	if host.TOTPSecret == "" {
+		return ""
+	}
+	key, err := hostTOTPKey(host)
+	if err != nil {
+		return fmt.Sprintf(tr("TOTP: %v"), err)
+	}
+	code, remaining := key.code(time.Now())
+	line := fmt.Sprintf(tr("TOTP code: %s (%v left)"), code, remaining)
+	if host.TOTP == TOTPType {
+		line += tr(", typed at the prompt")
+	}
+	return line
+}
+
+// totpTyper answers verification code prompts of hosts with TOTP type.
+// It follows the scrollback subscriber, so the prompt is in the scrollback
+type totpTyper struct {
+	session  *Session
+	typed    int
+	answered bool // the prompt at the end of the output has its code
+}
+
+func (t *totpTyper) Write(p []byte) (int, error) {
+	if bytes.IndexByte(p, '\n') >= 0 {
+		t.answered = false
+	}
+	if t.typed >= TOTPMaxTyped || t.answered {
+		return len(p), nil
+	}
+
+	sessionsMu.RLock()
+	prompt := authPrompt(t.session.Scrollback)
+	host := t.session.Host
+	sessionsMu.RUnlock()
+	if !slices.Contains(totpPrompts, prompt) {
+		return len(p), nil
+	}
+	t.answered = true
+	t.typed++
+
+	// The pipeline must not wait on the keychain or the next period
+	go func() {
+		key, err := hostTOTPKey(host)
+		if err != nil {
+			notify(tr("TOTP for %s: %v"), host.Alias, err)
+			return
+		}
+		code, remaining := key.code(time.Now())
+		if remaining < TOTPMinRemaining {
+			time.Sleep(remaining)
+			code, _ = key.code(time.Now())
+		}
+		// Through the session's encoding, to the PTY of a reconnect too
+		t.session.writeInput([]byte(code + "\r"))
+	}()
+	return len(p), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// touchPrompts are fragments of the messages asking to touch a hardware
+// key: ssh's for FIDO2 (sk) keys, pam_u2f's and those of other tools
+var touchPrompts = []string{
+	"confirm user presence for key",
+	"user presence required",
+	"please touch the device",
+	"touch your security key",
+	"tap your security key",
+	"touch your yubikey",
+	"waiting for touch",
+}
+
+// pinentryPrompts are fragments of the dialogs pinentry draws on the
+// terminal when gpg-agent needs a passphrase or a smartcard PIN
+var pinentryPrompts = []string{
+	"please enter the passphrase",
+	"please enter the pin",
+	"please unlock the card",
+}
+
+// lastLine returns the last line of output with text, as shown on screen.
+// ssh ends its touch prompt with a newline and waits, unlike password
+// prompts
+func lastLine(scrollback []byte) string {
+	tail := scrollback[max(0, len(scrollback)-2*AuthPromptMaxLen):]
+	lines := bytes.Split(tail, []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if text := strings.TrimSpace(screenText(string(lines[i]))); text != "" {
+			return strings.ToLower(text)
+		}
+	}
+	return ""
+}
+
+// touchLine tells whether a line of output asks to touch a hardware key
+func touchLine(line string) bool {
+	line = strings.ToLower(line)
+	for _, prompt := range touchPrompts {
+		if strings.Contains(line, prompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitingForTouch reports whether the output ends asking to touch a
+// hardware key; whatever ssh prints next, "User presence confirmed" or
+// the login, ends the wait
+func waitingForTouch(scrollback []byte) bool {
+	return touchLine(lastLine(scrollback))
+}
+
+// waitingForPinentry reports whether the output ends with a pinentry
+// dialog, which is waiting for input like an auth prompt
+func waitingForPinentry(scrollback []byte) bool {
+	line := lastLine(scrollback)
+	for _, prompt := range pinentryPrompts {
+		if strings.Contains(line, prompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// touchWatcher passes a multi-host run's output on and says which host
+// asks for a key touch, which collected runs would otherwise hide until
+// they time out
+type touchWatcher struct {
+	alias   string
+	stream  io.Writer // nil when the output is only collected
+	partial []byte
+}
+
+func (w *touchWatcher) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if touchLine(screenText(string(w.partial[:i]))) {
+			fmt.Fprintln(os.Stderr, colorize("1;33", fmt.Sprintf(tr("  %s: touch your security key"), w.alias)))
+		}
+		w.partial = w.partial[i+1:]
+	}
+	if w.stream != nil {
+		return w.stream.Write(p)
+	}
+	return len(p), nil
+}
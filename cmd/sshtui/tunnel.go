@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+const (
+	MaxForwardRestarts   = 5
+	ForwardRestartDelay  = 2 * time.Second
+	ForwardStableRuntime = 30 * time.Second
+)
+
+// createForwardSession starts a forwarding-only session (ssh -N) in the background
+func createForwardSession(host SSHHost, forwards []PortForward) {
+	fmt.Printf(tr("\nStarting forward%s on %s...\n"), displayForwards(forwards), host.Alias)
+
+	session, err := startForwardSession(host, forwards)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	fmt.Printf(tr("Forward running as session [!%d]\nPress Enter..."), sessionIndex(session))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// startForwardSession runs ssh -N without a PTY and restarts it when it fails
+func startForwardSession(host SSHHost, forwards []PortForward) (*Session, error) {
+	session := &Session{
+		Alias:    host.Alias,
+		Host:     host,
+		Kind:     SessionForward,
+		Forwards: forwards,
+	}
+	session.Output = newSessionOutput(session)
+
+	relays, err := startForwardRelays(forwards)
+	if err != nil {
+		return nil, err
+	}
+	session.Relays = relays
+
+	cmd, err := spawnForward(host, session)
+	if err != nil {
+		closeForwardRelays(relays)
+		return nil, err
+	}
+
+	order := loadSessionOrder()
+	sessionsMu.Lock()
+	session.ID = nextID
+	session.Cmd = cmd
+	session.State = StateReady
+	nextID++
+	insertSession(session, order)
+	sessionsMu.Unlock()
+	postEvent(EventSession)
+	markHostTouched(host.Alias)
+
+	go superviseForward(host, session)
+
+	return session, nil
+}
+
+// spawnForward starts one ssh -N process whose output goes to the session log
+func spawnForward(host SSHHost, session *Session) (*exec.Cmd, error) {
+	if err := refreshCertificate(host); err != nil {
+		return nil, err
+	}
+	cmd := commander.Command(context.Background(), "ssh", buildForwardArgs(host, sshForwards(session))...)
+	cmd.Stdout = session.Output
+	cmd.Stderr = session.Output
+	// New session: ssh must not grab our terminal for prompts
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// superviseForward waits on the ssh process and restarts it until the
+// user closes the session or it keeps failing right after starting; the
+// session is dead once it returns
+func superviseForward(host SSHHost, session *Session) {
+	defer func() {
+		sessionsMu.Lock()
+		session.State = StateDead
+		session.Ended = time.Now()
+		sessionsMu.Unlock()
+		postEvent(EventSession)
+	}()
+
+	sessionsMu.RLock()
+	cmd := session.Cmd
+	sessionsMu.RUnlock()
+	for {
+		started := time.Now()
+		err := cmd.Wait()
+		reason := fmt.Sprintf("ssh exited (%v)", err)
+
+		// Restart until ssh starts again, within the restart budget
+		for {
+			sessionsMu.Lock()
+			if session.closed {
+				sessionsMu.Unlock()
+				return
+			}
+			session.State = StateReconnecting
+			// A forward that stayed up for a while gets a fresh restart budget
+			if time.Since(started) > ForwardStableRuntime {
+				session.Restarts = 0
+			}
+			session.Restarts++
+			restarts := session.Restarts
+			// Critical forwards with auto-reconnect never give up
+			if session.Watch == WatchReconnect && restarts >= MaxForwardRestarts {
+				session.Restarts = 0
+			}
+			giveUp := session.Restarts >= MaxForwardRestarts
+			sessionsMu.Unlock()
+			postEvent(EventSession)
+
+			fmt.Fprintf(session.Output, "[sshtui] %s, restart %d/%d\n", reason, restarts, MaxForwardRestarts)
+			if giveUp {
+				fmt.Fprintf(session.Output, "[sshtui] giving up\n")
+				notify(tr("forward %s%s failed: ssh exited %d times (%v)"), session.Alias, displayForwards(session.Forwards), restarts, err)
+				return
+			}
+
+			time.Sleep(ForwardRestartDelay * time.Duration(restarts))
+
+			sessionsMu.RLock()
+			closed := session.closed
+			sessionsMu.RUnlock()
+			if closed {
+				return
+			}
+
+			newCmd, spawnErr := spawnForward(host, session)
+			if spawnErr == nil {
+				cmd = newCmd
+				break
+			}
+			started, reason = time.Now(), fmt.Sprintf("restart failed (%v)", spawnErr)
+		}
+
+		sessionsMu.Lock()
+		session.Cmd = cmd
+		session.State = StateReady
+		sessionsMu.Unlock()
+		postEvent(EventSession)
+	}
+}
+
+// closeForwardSession closes a forward-only session by its menu number,
+// leaving shell sessions to the main menu
+func closeForwardSession(num int) bool {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	if num < 1 || num > len(sessions) || sessions[num-1].Kind != SessionForward {
+		return false
+	}
+	killSession(sessions[num-1])
+	sessions = append(sessions[:num-1], sessions[num:]...)
+	return true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// waitState waits for a session to reach a state, at most timeout
+func waitState(t *testing.T, session *Session, state string, timeout time.Duration) {
+	t.Helper()
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		sessionsMu.RLock()
+		current := session.State
+		sessionsMu.RUnlock()
+		if current == state {
+			return
+		}
+	}
+	t.Fatalf("session %s did not become %s", session.Alias, state)
+}
+
+func TestForwardClosedWhileRestarting(t *testing.T) {
+	// ssh failing as soon as it starts
+	headless(t, "exit 255")
+	t.Setenv("HOME", t.TempDir())
+
+	session, err := startForwardSession(SSHHost{Alias: "web"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sessionsMu.Lock()
+		sessions = nil
+		sessionsMu.Unlock()
+	})
+	waitState(t, session, StateReconnecting, time.Second)
+
+	// Closed during the wait before the restart
+	sessionsMu.Lock()
+	session.closed = true
+	sessionsMu.Unlock()
+	waitState(t, session, StateDead, 2*ForwardRestartDelay)
+
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	if session.Ended.IsZero() {
+		t.Error("a closed forward has no end time")
+	}
+}
@@ -0,0 +1,772 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// ViewerContextLines are kept above a search match or jump target
+	ViewerContextLines = 3
+	// DefaultPageSize is used when the terminal height is unknown
+	DefaultPageSize = 20
+	// MinPageSize keeps the viewer usable on tiny terminals
+	MinPageSize = 5
+)
+
+// MenuView is how the main menu shows the host list
+type MenuView struct {
+	FailedOnly bool   // only hosts whose last connection failed
+	ByLatency  bool   // reachable hosts first, fastest first, see sortByHealth
+	Filter     string // words every shown host contains, see HostIndex.Search
+	Offset     int    // first host shown when the list is longer than a page
+	PageSize   int    // hosts per page at the last render
+}
+
+// MenuMinPageSize keeps a useful number of hosts on short terminals
+const MenuMinPageSize = 10
+
+// showMenu renders the menu in one write. Only the page of hosts that
+// fits the terminal is rendered, however long the list
+func showMenu(hosts []SSHHost, view *MenuView) {
+	var top, list, bottom strings.Builder
+
+	if readOnly {
+		fmt.Fprintln(&top, colorize("1;33", tr("READ-ONLY: viewing only, connecting and attaching are disabled")))
+		fmt.Fprintln(&top)
+	}
+	sessionsMu.RLock()
+	if len(sessions) > 0 {
+		fmt.Fprintln(&top, tr("Active Sessions:"))
+		for i, s := range sessions {
+			status := coloredStatus(s)
+			kind := ""
+			if s.Kind == SessionForward {
+				kind = tr(" forward-only") + displayForwards(s.Forwards)
+			}
+			needsInput := ""
+			switch s.State {
+			case StateAuthPrompt:
+				needsInput = colorize("1;35", tr(" <- needs input, attach or P to answer"))
+			case StateTouchKey:
+				needsInput = colorize("1;33", tr(" <- touch your security key"))
+			case StateAttached:
+				// The menu is not attached, so another view is
+				needsInput = colorize("36", tr(" <- attached elsewhere, resuming takes it over"))
+			}
+			fmt.Fprintf(&top, "  [!%d] %s (%s)%s%s%s%s%s%s\n", i+1, s.Alias, status, rootLabel(s), kind, commandLabel(s), watchLabel(s), bellLabel(s), needsInput)
+		}
+		fmt.Fprintln(&top)
+	}
+	if archived := archivedSummary(); archived != "" {
+		fmt.Fprintln(&top, archived)
+	}
+	if imported := handoffSummary(); imported != "" {
+		fmt.Fprintln(&top, imported)
+	}
+	sessionsMu.RUnlock()
+	if lines := notificationLines(); lines != nil {
+		fmt.Fprintln(&top, tr("Notifications (N to view and dismiss):"))
+		for _, line := range lines {
+			fmt.Fprintln(&top, "  "+line)
+		}
+		fmt.Fprintln(&top)
+	}
+
+	var history map[string]ConnectAttempt
+	switch {
+	case view.FailedOnly:
+		history = loadHistory()
+		fmt.Fprintln(&top, tr("Connections (last attempt failed, F shows all):"))
+	case view.ByLatency:
+		fmt.Fprintln(&top, tr("Connections (by latency, L restores the order):"))
+	default:
+		fmt.Fprintln(&top, tr("Connections:"))
+	}
+	if view.Filter != "" {
+		fmt.Fprintf(&top, tr("  matching %q: %d hosts, s clears\n"), view.Filter, len(hosts))
+	}
+	if len(hosts) == 0 && (view.FailedOnly || view.Filter != "") {
+		fmt.Fprintln(&top, tr("  none"))
+	}
+
+	for _, msg := range providerErrors {
+		fmt.Fprintf(&bottom, tr("  Provider error: %s\n"), colorize("31", msg))
+	}
+	if managedWarning != "" {
+		fmt.Fprintln(&bottom, "  "+colorize("33", managedWarning))
+	}
+	if summary := promptSummary(); summary != "" {
+		fmt.Fprintln(&bottom, "  "+colorize("1;35", summary))
+	}
+	if warning := teleportWarning(); warning != "" {
+		fmt.Fprintln(&bottom, "  "+colorize("33", warning))
+	}
+	if notice := updateNotice(); notice != "" {
+		fmt.Fprintln(&bottom, "  "+colorize("32", notice))
+	}
+
+	fmt.Fprintln(&bottom, tr("\nCommands:"))
+	fmt.Fprintln(&bottom, tr("  [number]  - Connect to host"))
+	for _, host := range hosts {
+		if host.Hotkey != "" {
+			fmt.Fprintln(&bottom, tr("  [key]     - Connect to the host shown with that key"))
+			break
+		}
+	}
+	fmt.Fprintln(&bottom, tr("  o[number] - Connect with extra ssh options"))
+	fmt.Fprintln(&bottom, tr("  W[number] - Connect in a new terminal window"))
+	fmt.Fprintln(&bottom, tr("  a[number] - Remote tmux sessions of host"))
+	fmt.Fprintln(&bottom, tr("  t[number] - Test connection (BatchMode, no session)"))
+	fmt.Fprintln(&bottom, tr("  h[number] - Host details and certificates"))
+	fmt.Fprintln(&bottom, tr("  C[number] - Clone host to a new alias and hostname"))
+	fmt.Fprintln(&bottom, tr("  I         - Import hosts from pasted text (user@host, host:port, CSV)"))
+	if appConfig.ManagedHosts {
+		fmt.Fprintln(&bottom, tr("  D[number] - Delete one of sshtui's hosts"))
+		fmt.Fprintln(&bottom, tr("  A         - Add a host to sshtui's hosts"))
+	}
+	fmt.Fprintln(&bottom, tr("  [!number] - Resume session"))
+	fmt.Fprintln(&bottom, tr("  P         - Answer the auth prompts of detached sessions, one by one"))
+	fmt.Fprintln(&bottom, tr("  v         - View scrollback/history"))
+	fmt.Fprintln(&bottom, tr("  i!number  - Session process tree and signals"))
+	fmt.Fprintln(&bottom, tr("  c!number  - Toggle critical watch (alert, alert+reconnect, off)"))
+	fmt.Fprintln(&bottom, tr("  n         - Watch pattern matches in session output"))
+	fmt.Fprintln(&bottom, tr("  N         - Notifications (sessions ended, forwards failed, reloads)"))
+	fmt.Fprintln(&bottom, tr("  m         - Multi-host command"))
+	fmt.Fprintln(&bottom, tr("  f         - Port forwards"))
+	fmt.Fprintln(&bottom, tr("  M         - SSH connection sharing (control masters)"))
+	fmt.Fprintln(&bottom, tr("  w         - Open workspace"))
+	fmt.Fprintln(&bottom, tr("  T         - Time report"))
+	fmt.Fprintln(&bottom, tr("  F         - Toggle hosts whose last connection failed"))
+	fmt.Fprintln(&bottom, tr("  L         - Toggle sorting by latency, unreachable hosts last"))
+	fmt.Fprintln(&bottom, tr("  s words   - Filter hosts (s alone clears); > and < page through"))
+	fmt.Fprintln(&bottom, tr("  /         - Search past connections and commands (Ctrl+R style)"))
+	fmt.Fprintln(&bottom, tr("  r         - Reload SSH and sshtui config"))
+	fmt.Fprintln(&bottom, tr("  x         - Close active shell session"))
+	fmt.Fprintln(&bottom, tr("  e         - Clear ended sessions (scrollback kept as archived)"))
+	fmt.Fprintln(&bottom, tr("  E         - Export sessions, scrollback and state for another machine"))
+	fmt.Fprintln(&bottom, tr("  b         - Bulk session operations"))
+	fmt.Fprintln(&bottom, tr("  q         - Quit all"))
+	fmt.Fprintln(&bottom, tr("\nIn session: Ctrl+Space to detach"))
+	fmt.Fprint(&bottom, "\n> ")
+
+	// One line is kept for the page position
+	chrome := strings.Count(top.String(), "\n") + strings.Count(bottom.String(), "\n") + 1
+	view.PageSize = menuPageSize(chrome)
+	view.Offset = clampTop(view.Offset, len(hosts), view.PageSize)
+	end := min(view.Offset+view.PageSize, len(hosts))
+
+	for i := view.Offset; i < end; i++ {
+		host := hosts[i]
+		fmt.Fprintf(&list, "  [%d] %s%s", i+1, host.Alias, hotkeyLabel(host))
+		if target := menuTarget(host); target != "" {
+			fmt.Fprintf(&list, " (%s)", target)
+		}
+		for _, tag := range host.Tags {
+			fmt.Fprintf(&list, " #%s", tag)
+		}
+		if host.Source != sshConfigSource {
+			list.WriteString(colorize("2", " <"+host.Source+">"))
+		}
+		if host.Status != "" {
+			list.WriteString(colorize("2", " ("+tr(host.Status)+")"))
+		}
+		list.WriteString(displayForwards(host.Forwards))
+		if view.ByLatency {
+			list.WriteString(healthLabel(host.Alias))
+		}
+		list.WriteString(dashboardLabel(host))
+		if attempt, ok := history[host.Alias]; ok {
+			// A host behind a failed jump host is not known to be down
+			color := "31"
+			if attempt.Jump != "" {
+				color = "33"
+			}
+			fmt.Fprintf(&list, tr(" - %s ago: %s"), formatDuration(int64(time.Since(attempt.Time).Seconds())), colorize(color, attempt.Error))
+		}
+		list.WriteString("\n")
+	}
+	if len(hosts) > view.PageSize {
+		fmt.Fprintf(&list, tr("  hosts %d-%d of %d, > next page, < previous\n"), view.Offset+1, end, len(hosts))
+	}
+
+	clearScreen()
+	printHeader(tr("   sshtui - Session Manager"))
+	fmt.Print(top.String() + list.String() + bottom.String())
+}
+
+// menuSnapshot sums up what background events change in the menu
+// (session states, bells, alerts, updates), to tell when it needs a redraw
+func menuSnapshot() string {
+	var b strings.Builder
+	sessionsMu.RLock()
+	for _, s := range sessions {
+		fmt.Fprintf(&b, "%d %s %v %s %s %s\n", s.ID, s.State, s.Bell, s.Watch, s.LastCommand, s.LastExit)
+	}
+	fmt.Fprintf(&b, "%d archived\n", len(archivedSessions))
+	sessionsMu.RUnlock()
+	fmt.Fprintf(&b, "%d notifications\n", unseenNotifications())
+	b.WriteString(healthSnapshot())
+	b.WriteString(dashboardSnapshot())
+	b.WriteString(strings.Join(criticalAlerts(), "\n"))
+	b.WriteString(updateNotice())
+	return b.String()
+}
+
+// menuPageSize is how many hosts fit on the terminal beside chrome lines
+// of menu; without a terminal every host is listed
+func menuPageSize(chrome int) int {
+	ws, err := term.Size()
+	if err != nil || ws.Rows == 0 {
+		return math.MaxInt32
+	}
+
+	// The header box, or plain mode's title and blank line
+	chrome += 4
+	if alerts := len(criticalAlerts()); alerts > 0 {
+		chrome += alerts + 1
+	}
+	return max(int(ws.Rows)-chrome, MenuMinPageSize)
+}
+
+// menuTarget is the "user@hostname" shown next to a host, with the
+// effective user when a Tag rule supplies it and the jump host if any
+func menuTarget(host SSHHost) string {
+	target := host.HostName
+	if user := effectiveUser(host); user != "" {
+		target = user + "@" + valueOr(host.HostName, host.Alias)
+	}
+	if program := connectProgram(host); program != "" {
+		target = strings.TrimSpace(fmt.Sprintf(tr("%s via %s"), target, program))
+	} else if host.ProxyJump != "" {
+		target = strings.TrimSpace(fmt.Sprintf(tr("%s via %s"), target, host.ProxyJump))
+	} else if host.ProxyCommand != "" {
+		target = strings.TrimSpace(fmt.Sprintf(tr("%s via %s"), target, tr("proxy command")))
+	}
+	return target
+}
+
+// promptExtras asks for extra ssh options, defaulting to the last ones used
+func promptExtras(host SSHHost) (SSHHost, bool) {
+	last := loadExtras()[host.Alias]
+
+	fmt.Println(tr("\nExtra ssh options (e.g. -i ~/.ssh/key -4 -o ServerAliveInterval=30)"))
+	if len(last) > 0 {
+		fmt.Printf(tr("Enter keeps last used: %s, '-' clears, 'q' cancels\n"), strings.Join(last, " "))
+	} else {
+		fmt.Println(tr("Enter for none, 'q' cancels"))
+	}
+	fmt.Print("> ")
+
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "q":
+		return host, false
+	case "":
+		host.Extras = last
+	case "-":
+		host.Extras = nil
+	default:
+		host.Extras = splitArgs(input)
+	}
+
+	if err := saveExtras(host.Alias, host.Extras); err != nil {
+		fmt.Printf(tr("Warning: could not remember options: %v\n"), err)
+	}
+	return host, true
+}
+
+// confirmHost makes the user re-type the alias of a host whose tags
+// require confirmation for the action
+func confirmHost(host SSHHost, action string) bool {
+	tag := needsConfirm(host, action)
+	if tag == "" {
+		return true
+	}
+
+	fmt.Printf(tr("\n%s is tagged %q. Type the alias to confirm: "), host.Alias, tag)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(input) == host.Alias {
+		return true
+	}
+
+	fmt.Print(tr("Not confirmed, skipping. Press Enter..."))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	return false
+}
+
+func viewScrollback(session *Session) {
+	sessionsMu.RLock()
+	scrollback, history := fullScrollback(session), historySummary(session)
+	sessionsMu.RUnlock()
+	if len(scrollback) == 0 {
+		fmt.Println(tr("No scrollback available. Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	// Split into lines
+	lines := strings.Split(string(scrollback), "\n")
+	currentLine := 0
+	pageSize := DefaultPageSize
+	searchTerm := ""
+	searchResults := []int{}
+	searchIndex := -1
+	lineNumbers := false
+	numberWidth := len(fmt.Sprint(len(lines)))
+
+	// Commands typed at shell prompts; collapsed shows their prompts only.
+	// The viewer shows rows: lines, or prompts and output summaries
+	blocks := segmentScrollback(lines, session.Host.PromptPattern)
+	blockIndex := len(blocks) - 1
+	if blockIndex > 0 && blocks[blockIndex].Command == "" && blocks[blockIndex].Output() == 0 {
+		// The prompt waiting for the next command
+		blockIndex--
+	}
+	collapsed := false
+	rows, rowLines := lines, identityRows(len(lines))
+	layout := func() {
+		if collapsed {
+			rows, rowLines = collapsedRows(lines, blocks)
+		} else {
+			rows, rowLines = lines, identityRows(len(lines))
+		}
+	}
+	// rowOf is the row showing line, or the prompt of the hidden output
+	rowOf := func(line int) int {
+		if !collapsed {
+			return line
+		}
+		row := 0
+		for i, l := range rowLines {
+			if l >= 0 && l <= line {
+				row = i
+			}
+		}
+		return row
+	}
+	// expand shows every line again, from the line at the top of the page
+	expand := func() {
+		if !collapsed {
+			return
+		}
+		line := 0
+		for i := min(currentLine, len(rowLines)-1); i >= 0; i-- {
+			if rowLines[i] >= 0 {
+				line = rowLines[i]
+				break
+			}
+		}
+		collapsed = false
+		layout()
+		currentLine = line
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	message := ""
+
+	// The page is redrawn from the resize handler while input is awaited
+	var mu sync.Mutex
+	render := func() {
+		clearScreen()
+		header := []string{tr("Scrollback: ") + session.Alias}
+		if history != "" {
+			header = append(header, history)
+		}
+		if searchTerm != "" {
+			header = append(header, tr("Search: ")+searchTerm, fmt.Sprintf(tr("Matches: %d"), len(searchResults)))
+		}
+		printHeader(header...)
+
+		pageSize = viewerPageSize(len(header))
+		currentLine = clampTop(currentLine, len(rows), pageSize)
+
+		endLine := currentLine + pageSize
+		if endLine > len(rows) {
+			endLine = len(rows)
+		}
+
+		for i := currentLine; i < endLine; i++ {
+			line := rows[i]
+			// Highlight search term (case-insensitive)
+			if searchTerm != "" {
+				line = highlightMatches(line, searchTerm)
+			}
+			if lineNumbers {
+				number := strings.Repeat(" ", numberWidth)
+				if rowLines[i] >= 0 {
+					number = fmt.Sprintf("%*d", numberWidth, rowLines[i]+1)
+				}
+				line = colorize("2", number+" ") + line
+			}
+			fmt.Println(line)
+		}
+
+		percent := 100
+		if len(rows) > 0 {
+			percent = endLine * 100 / len(rows)
+		}
+		if message != "" {
+			fmt.Println("\n" + message)
+			message = ""
+		}
+		fmt.Printf(tr("\n[Line %d/%d %d%%] "), currentLine+1, len(rows), percent)
+		if searchTerm != "" && len(searchResults) > 0 {
+			fmt.Printf(tr("[Match %d/%d] "), searchIndex+1, len(searchResults))
+		}
+		if blockIndex >= 0 {
+			fmt.Printf(tr("[Command %d/%d: %s] "), blockIndex+1, len(blocks), truncateText(blocks[blockIndex].Command, 30))
+		}
+		fmt.Print(tr("Command: "))
+	}
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	done := make(chan bool)
+	defer func() {
+		signal.Stop(winch)
+		close(done)
+	}()
+	go func() {
+		for {
+			select {
+			case <-winch:
+				mu.Lock()
+				render()
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		mu.Lock()
+		render()
+		mu.Unlock()
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "q" {
+			return
+		}
+
+		mu.Lock()
+		switch {
+		case input == "j" || input == "":
+			// Page down
+			currentLine = clampTop(currentLine+pageSize, len(rows), pageSize)
+
+		case input == "k":
+			// Page up
+			currentLine = clampTop(currentLine-pageSize, len(rows), pageSize)
+
+		case input == "d":
+			// Half page down
+			currentLine = clampTop(currentLine+pageSize/2, len(rows), pageSize)
+
+		case input == "u":
+			// Half page up
+			currentLine = clampTop(currentLine-pageSize/2, len(rows), pageSize)
+
+		case input == "J":
+			// One line down
+			currentLine = clampTop(currentLine+1, len(rows), pageSize)
+
+		case input == "K":
+			// One line up
+			currentLine = clampTop(currentLine-1, len(rows), pageSize)
+
+		case input == "g":
+			// Go to top
+			currentLine = 0
+
+		case input == "l":
+			// Toggle line numbers
+			lineNumbers = !lineNumbers
+
+		case strings.HasPrefix(input, ":"):
+			// Jump to line, keeping a few lines of context above it
+			var num int
+			if _, err := fmt.Sscanf(input, ":%d", &num); err == nil {
+				expand()
+				currentLine = showLine(num-1, len(rows), pageSize)
+			}
+
+		case input == "G":
+			// Go to bottom: the last full page
+			currentLine = clampTop(len(rows), len(rows), pageSize)
+
+		case strings.HasPrefix(input, "/"):
+			// Search
+			expand()
+			searchTerm = strings.TrimPrefix(input, "/")
+			searchResults = []int{}
+			for i, line := range lines {
+				if containsFold(line, searchTerm) {
+					searchResults = append(searchResults, i)
+				}
+			}
+			if len(searchResults) > 0 {
+				searchIndex = 0
+				currentLine = showLine(searchResults[0], len(rows), pageSize)
+			}
+
+		case input == "n":
+			// Next search result
+			if len(searchResults) > 0 {
+				expand()
+				searchIndex = (searchIndex + 1) % len(searchResults)
+				currentLine = showLine(searchResults[searchIndex], len(rows), pageSize)
+			}
+
+		case input == "N":
+			// Previous search result
+			if len(searchResults) > 0 {
+				expand()
+				searchIndex = (searchIndex - 1 + len(searchResults)) % len(searchResults)
+				currentLine = showLine(searchResults[searchIndex], len(rows), pageSize)
+			}
+
+		case input == "[" || input == "]":
+			// Previous or next command: from the current one when it is on
+			// the page, else from the top of the page
+			if len(blocks) == 0 {
+				message = tr("No shell prompts found (see PromptPattern)")
+				break
+			}
+			target := -1
+			if row := rowOf(blocks[blockIndex].Prompt); row >= currentLine && row < currentLine+pageSize {
+				target = blockIndex - 1
+				if input == "]" {
+					target = blockIndex + 1
+				}
+				if target >= len(blocks) {
+					target = -1
+				}
+			} else {
+				anchor := currentLine + ViewerContextLines
+				if currentLine == 0 {
+					anchor = 0
+				}
+				for i, block := range blocks {
+					row := rowOf(block.Prompt)
+					if (input == "[" && row < anchor) || (input == "]" && row >= anchor && target < 0) {
+						target = i
+					}
+				}
+			}
+			if target >= 0 {
+				blockIndex = target
+				currentLine = showLine(rowOf(blocks[target].Prompt), len(rows), pageSize)
+			}
+
+		case input == "c":
+			// Collapse command outputs to their prompts, or expand them
+			if len(blocks) == 0 {
+				message = tr("No shell prompts found (see PromptPattern)")
+				break
+			}
+			if collapsed {
+				expand()
+			} else {
+				collapsed = true
+				layout()
+			}
+			if blockIndex >= 0 {
+				currentLine = showLine(rowOf(blocks[blockIndex].Prompt), len(rows), pageSize)
+			}
+
+		case input == "y":
+			// Copy the output of the current command
+			if blockIndex < 0 {
+				message = tr("No shell prompts found (see PromptPattern)")
+				break
+			}
+			block := blocks[blockIndex]
+			if via, err := copyToClipboard(blockOutput(lines, block)); err != nil {
+				message = fmt.Sprintf(tr("Copy failed (%s): %v"), via, err)
+			} else {
+				message = fmt.Sprintf(tr("Copied %d lines of output of %q with %s"), block.Output(), block.Command, via)
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// identityRows maps each viewer row to the line of the same number
+func identityRows(count int) []int {
+	rowLines := make([]int, count)
+	for i := range rowLines {
+		rowLines[i] = i
+	}
+	return rowLines
+}
+
+// collapsedRows shows the lines before the first prompt, then each prompt
+// with a summary of its output. rowLines gives the line of each row, -1
+// for summaries
+func collapsedRows(lines []string, blocks []CommandBlock) ([]string, []int) {
+	rows, rowLines := []string{}, []int{}
+	for i := 0; i < blocks[0].Prompt; i++ {
+		rows, rowLines = append(rows, lines[i]), append(rowLines, i)
+	}
+	for _, block := range blocks {
+		rows, rowLines = append(rows, lines[block.Prompt]), append(rowLines, block.Prompt)
+		if n := block.Output(); n > 0 {
+			rows = append(rows, colorize("2", fmt.Sprintf(tr("    ... %d lines of output (c expands)"), n)))
+			rowLines = append(rowLines, -1)
+		}
+	}
+	return rows, rowLines
+}
+
+// clampTop keeps the first displayed line within the buffer, so the last
+// page is always full when there is enough content
+func clampTop(top, total, pageSize int) int {
+	if top > total-pageSize {
+		top = total - pageSize
+	}
+	if top < 0 {
+		top = 0
+	}
+	return top
+}
+
+// viewerPageSize fits a page between the header (with its number of
+// lines) and the prompt, unless PageSize is set in the config
+func viewerPageSize(headerLines int) int {
+	if appConfig.PageSize > 0 {
+		return appConfig.PageSize
+	}
+	ws, err := term.Size()
+	if err != nil || ws.Rows == 0 {
+		return DefaultPageSize
+	}
+
+	// Box borders and blank line, or plain mode's blank lines; then the
+	// prompt line and the blank line before it
+	chrome := headerLines + 3 + 2
+	if alerts := len(criticalAlerts()); alerts > 0 {
+		chrome += alerts + 1
+	}
+	if size := int(ws.Rows) - chrome; size > MinPageSize {
+		return size
+	}
+	return MinPageSize
+}
+
+// showLine returns the first displayed line that puts line near the top of
+// the page, with a few lines of context above it
+func showLine(line, total, pageSize int) int {
+	return clampTop(line-ViewerContextLines, total, pageSize)
+}
+
+// pickHost asks for one host by number or alias; false when cancelled
+func pickHost(hosts []SSHHost) (SSHHost, bool) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		clearScreen()
+		printHeader(tr("Pick a Host"))
+		for i, host := range hosts {
+			fmt.Printf("  [%d] %s", i+1, host.Alias)
+			if target := menuTarget(host); target != "" {
+				fmt.Printf(" (%s)", target)
+			}
+			fmt.Println()
+		}
+		fmt.Print(tr("\nNumber or alias (q or empty to cancel): "))
+
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err != nil || input == "" || input == "q" {
+			return SSHHost{}, false
+		}
+
+		if host, ok := findHost(hosts, input); ok {
+			return host, true
+		}
+		var num int
+		if _, err := fmt.Sscanf(input, "%d", &num); err == nil && num > 0 && num <= len(hosts) {
+			return hosts[num-1], true
+		}
+	}
+}
+
+func selectHosts(hosts []SSHHost) []SSHHost {
+	reader := bufio.NewReader(os.Stdin)
+	selected := make(map[int]bool)
+
+	for {
+		clearScreen()
+		printHeader(tr("Select Hosts (space to toggle)"))
+
+		for i, host := range hosts {
+			marker := "[ ]"
+			if selected[i] {
+				marker = "[X]"
+			}
+			fmt.Printf("  %s [%d] %s", marker, i+1, host.Alias)
+			if isLocal(host) {
+				fmt.Print(tr(" (this machine, without ssh)"))
+			} else if host.HostName != "" {
+				fmt.Printf(" (%s)", host.HostName)
+			}
+			fmt.Println()
+		}
+
+		fmt.Println(tr("\nCommands:"))
+		fmt.Println(tr("  [number]  - Toggle selection"))
+		fmt.Println(tr("  a         - Select all but this machine"))
+		fmt.Println(tr("  c         - Clear all"))
+		fmt.Println(tr("  d         - Done (execute)"))
+		fmt.Println(tr("  q         - Cancel"))
+		fmt.Print("\n> ")
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		switch {
+		case input == "q":
+			return nil
+
+		case input == "d":
+			result := []SSHHost{}
+			for i, host := range hosts {
+				if selected[i] {
+					result = append(result, host)
+				}
+			}
+			return result
+
+		case input == "a":
+			// This machine only by number: a fleet command is rarely meant for it
+			for i, host := range hosts {
+				if !isLocal(host) {
+					selected[i] = true
+				}
+			}
+
+		case input == "c":
+			selected = make(map[int]bool)
+
+		default:
+			var num int
+			if _, err := fmt.Sscanf(input, "%d", &num); err == nil {
+				if num > 0 && num <= len(hosts) {
+					idx := num - 1
+					selected[idx] = !selected[idx]
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// releasesURL is the GitHub API endpoint of the latest release
+	releasesURL = "https://api.github.com/repos/studiowebux/sshtui/releases/latest"
+
+	UpdateCheckInterval = 24 * time.Hour // between two checks from the menu
+	ReleaseCheckTimeout = 15 * time.Second
+	UpdateTimeout       = 2 * time.Minute // downloading the binary
+)
+
+// releaseAssets names the release binary built for each platform; the
+// publish workflow builds on these runners
+var releaseAssets = map[string]string{
+	"linux/amd64":  "sshtui-ubuntu-latest",
+	"darwin/arm64": "sshtui-macos-latest",
+}
+
+// Release is the part of a GitHub release we use
+type Release struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Version is the release version, without the leading v
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// assetURL returns the download URL of the named asset, or ""
+func (r Release) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL
+		}
+	}
+	return ""
+}
+
+// UpdateCheck is the cached result of the last release check
+type UpdateCheck struct {
+	Checked time.Time `json:"checked"`
+	Latest  string    `json:"latest"`
+}
+
+// availableUpdate holds a newer version found by the menu check, or ""
+var (
+	availableUpdate string
+	updateMu        sync.Mutex
+)
+
+// newerVersion reports whether version a is newer than b, comparing
+// dotted numbers
+func newerVersion(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func httpGet(url string, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "sshtui/"+version)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(tr("%s returned %s"), url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// latestRelease asks GitHub for the latest sshtui release
+func latestRelease() (Release, error) {
+	var release Release
+	data, err := httpGet(releasesURL, ReleaseCheckTimeout)
+	if err != nil {
+		return release, err
+	}
+	err = json.Unmarshal(data, &release)
+	return release, err
+}
+
+// checkForUpdate looks for a newer release at most once a day, in the
+// background, when UpdateCheck is on; the menu footer shows the result
+func checkForUpdate() {
+	if !appConfig.UpdateCheck {
+		return
+	}
+	var check UpdateCheck
+	loadState("update.json", &check)
+	if time.Since(check.Checked) < UpdateCheckInterval {
+		if newerVersion(check.Latest, version) {
+			availableUpdate = check.Latest
+		}
+		return
+	}
+
+	go func() {
+		release, err := latestRelease()
+		if err != nil {
+			return
+		}
+		saveState("update.json", UpdateCheck{Checked: time.Now(), Latest: release.Version()})
+		if newerVersion(release.Version(), version) {
+			updateMu.Lock()
+			availableUpdate = release.Version()
+			updateMu.Unlock()
+		}
+	}()
+}
+
+// updateNotice is the menu footer line about a newer release, or ""
+func updateNotice() string {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	if availableUpdate == "" {
+		return ""
+	}
+	return fmt.Sprintf(tr("sshtui %s is available (sshtui self-update)"), availableUpdate)
+}
+
+// printVersion is the version subcommand: this build, and the latest
+// release when GitHub can be reached
+func printVersion() {
+	fmt.Printf(tr("sshtui v%s\n"), version)
+	fmt.Printf("%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if client := probeSSHClient(); client.Err != nil {
+		fmt.Printf(tr("ssh: %v\n"), client.Err)
+	} else {
+		fmt.Printf(tr("ssh: %s\n"), client.Version)
+	}
+
+	release, err := latestRelease()
+	switch {
+	case err != nil:
+		fmt.Printf(tr("Could not check for updates: %v\n"), err)
+	case newerVersion(release.Version(), version):
+		fmt.Printf(tr("sshtui %s is available (sshtui self-update)\n"), release.Version())
+	default:
+		fmt.Println(tr("Up to date"))
+	}
+}
+
+// selfUpdate downloads the latest release binary for this platform,
+// checks it against the release's SHA-256 file and replaces the running
+// executable with it
+func selfUpdate() error {
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	asset, ok := releaseAssets[platform]
+	if !ok {
+		return fmt.Errorf(tr("no release build for %s, build from source"), platform)
+	}
+
+	release, err := latestRelease()
+	if err != nil {
+		return err
+	}
+	if !newerVersion(release.Version(), version) {
+		fmt.Printf(tr("sshtui v%s is up to date\n"), version)
+		return nil
+	}
+
+	binaryURL, sumURL := release.assetURL(asset), release.assetURL(asset+".sha256")
+	if binaryURL == "" || sumURL == "" {
+		return fmt.Errorf(tr("release %s has no %s with a .sha256 checksum"), release.TagName, asset)
+	}
+	fmt.Printf(tr("Downloading sshtui %s...\n"), release.Version())
+	binary, err := httpGet(binaryURL, UpdateTimeout)
+	if err != nil {
+		return err
+	}
+	sumFile, err := httpGet(sumURL, UpdateTimeout)
+	if err != nil {
+		return err
+	}
+
+	// The checksum file is sha256sum output: "<hex>  <name>"
+	fields := strings.Fields(string(sumFile))
+	sum := sha256.Sum256(binary)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+		return errors.New(tr("checksum mismatch, the binary was not replaced"))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	// Write next to the executable and rename over it, so a failure
+	// leaves the old binary in place
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".sshtui-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return err
+	}
+	fmt.Printf(tr("Updated %s to %s\n"), exe, release.Version())
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Watchdog modes for critical sessions
+const (
+	WatchOff       = ""
+	WatchAlert     = "alert"
+	WatchReconnect = "reconnect"
+)
+
+const ReconnectDelay = 3 * time.Second
+
+// cycleWatch toggles a session through off → alert → alert+reconnect
+func cycleWatch(num int) (string, bool) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	if num < 1 || num > len(sessions) {
+		return "", false
+	}
+
+	s := sessions[num-1]
+	switch s.Watch {
+	case WatchOff:
+		s.Watch = WatchAlert
+	case WatchAlert:
+		s.Watch = WatchReconnect
+	default:
+		s.Watch = WatchOff
+	}
+	return s.Watch, true
+}
+
+// watchLabel returns the menu marker for a watched session
+func watchLabel(s *Session) string {
+	switch s.Watch {
+	case WatchAlert:
+		return tr(" [critical]")
+	case WatchReconnect:
+		return tr(" [critical+reconnect]")
+	}
+	return ""
+}
+
+// criticalAlerts lists watched sessions that are not running, then the
+// latest unseen watch pattern matches
+func criticalAlerts() []string {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	alerts := []string{}
+	for i, s := range sessions {
+		if s.Watch == WatchOff || s.running() {
+			continue
+		}
+		msg := fmt.Sprintf(tr("CRITICAL: session [!%d] %s is down"), i+1, s.Alias)
+		if s.Watch == WatchReconnect {
+			msg += tr(" (reconnecting...)")
+		}
+		alerts = append(alerts, msg)
+	}
+	return append(alerts, watchAlerts()...)
+}
+
+// printAlerts shows critical session alerts at the top of a view
+func printAlerts() {
+	alerts := criticalAlerts()
+	for _, alert := range alerts {
+		fmt.Println(colorize("1;41;97", " "+alert+" "))
+	}
+	if len(alerts) > 0 {
+		fmt.Println()
+	}
+}
+
+// reconnectSession restarts ssh for a shell session until it succeeds or
+// the user closes the session or turns auto-reconnect off
+func reconnectSession(session *Session) bool {
+	for attempt := 1; ; attempt++ {
+		time.Sleep(ReconnectDelay)
+
+		sessionsMu.RLock()
+		keep := session.Watch == WatchReconnect && !session.closed
+		args := session.Args
+		sessionsMu.RUnlock()
+		if !keep {
+			return false
+		}
+
+		if err := refreshCertificate(session.Host); err != nil {
+			fmt.Fprintf(session.Output, "\r\n[sshtui] %v\r\n", err)
+		}
+		cmd, ptmx, err := spawnPTY(args)
+		if err != nil {
+			continue
+		}
+
+		sessionsMu.Lock()
+		if session.PTY != nil {
+			session.PTY.Close()
+		}
+		session.Cmd = cmd
+		session.PTY = ptmx
+		session.State = StateConnecting
+		sessionsMu.Unlock()
+		postEvent(EventSession)
+		fmt.Fprintf(session.Output, tr("\r\n[sshtui] reconnected (attempt %d)\r\n"), attempt)
+		notify(tr("session %s reconnected (attempt %d)"), session.Alias, attempt)
+		go readPTY(session, ptmx)
+		return true
+	}
+}
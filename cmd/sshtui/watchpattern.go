@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MaxWatchMatches    = 200  // older matches are dropped
+	MaxWatchLineLength = 4096 // longer lines are matched in pieces
+	WatchAlertLines    = 3    // unseen matches shown above the menu
+)
+
+// escapePattern finds terminal escape sequences, removed before matching
+var escapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>78]`)
+
+// WatchMatch is a line of session output containing a WatchPattern
+type WatchMatch struct {
+	Time    time.Time
+	Session *Session
+	Pattern string
+	Line    string
+	Seen    bool
+}
+
+var (
+	watchMatches []WatchMatch
+	watchMu      sync.Mutex
+)
+
+// watchMatcher scans a session's output line by line for the
+// WatchPattern entries of the sshtui config
+type watchMatcher struct {
+	session *Session
+	partial []byte
+}
+
+func (w *watchMatcher) Write(p []byte) (int, error) {
+	if len(appConfig.WatchPatterns) == 0 {
+		w.partial = nil
+		return len(p), nil
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		end := strings.IndexAny(string(w.partial), "\r\n")
+		if end < 0 {
+			break
+		}
+		w.matchLine(string(w.partial[:end]))
+		w.partial = w.partial[end+1:]
+	}
+	if len(w.partial) > MaxWatchLineLength {
+		w.matchLine(string(w.partial))
+		w.partial = nil
+	}
+	return len(p), nil
+}
+
+// matchLine records line against the first pattern it contains, ignoring
+// case and escape sequences
+func (w *watchMatcher) matchLine(line string) {
+	line = strings.TrimSpace(escapePattern.ReplaceAllString(line, ""))
+	if line == "" {
+		return
+	}
+	lower := strings.ToLower(line)
+	for _, pattern := range appConfig.WatchPatterns {
+		if strings.Contains(lower, strings.ToLower(pattern)) {
+			recordWatchMatch(WatchMatch{Time: time.Now(), Session: w.session, Pattern: pattern, Line: line})
+			return
+		}
+	}
+}
+
+func recordWatchMatch(match WatchMatch) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	watchMatches = append(watchMatches, match)
+	if len(watchMatches) > MaxWatchMatches {
+		watchMatches = watchMatches[len(watchMatches)-MaxWatchMatches:]
+	}
+}
+
+// watchAlerts describes the latest unseen matches for the top of a view.
+// Callers hold sessionsMu to number the sessions
+func watchAlerts() []string {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	unseen := []WatchMatch{}
+	for _, match := range watchMatches {
+		if !match.Seen {
+			unseen = append(unseen, match)
+		}
+	}
+	if len(unseen) == 0 {
+		return nil
+	}
+
+	alerts := []string{}
+	for _, match := range unseen[max(0, len(unseen)-WatchAlertLines):] {
+		alerts = append(alerts, fmt.Sprintf(tr("MATCH: %s %s: %s"), watchSessionLabel(match.Session), match.Session.Alias, match.Line))
+	}
+	if more := len(unseen) - WatchAlertLines; more > 0 {
+		alerts = append(alerts, fmt.Sprintf(tr("MATCH: %d more, n to view all"), more))
+	}
+	return alerts
+}
+
+// watchSessionLabel is "[!n]" for a session still in the list, "[closed]"
+// otherwise. Callers hold sessionsMu
+func watchSessionLabel(session *Session) string {
+	for i, s := range sessions {
+		if s == session {
+			return fmt.Sprintf("[!%d]", i+1)
+		}
+	}
+	return tr("[closed]")
+}
+
+// showWatchMatches lists the pattern matches of all sessions, marking
+// them seen; c clears the list
+func showWatchMatches() {
+	clearScreen()
+	printHeader(tr("Watch pattern matches"))
+
+	if len(appConfig.WatchPatterns) == 0 {
+		fmt.Println(tr("No WatchPattern in the sshtui config"))
+	} else {
+		fmt.Printf(tr("Patterns: %s\n\n"), strings.Join(appConfig.WatchPatterns, ", "))
+	}
+
+	sessionsMu.RLock()
+	watchMu.Lock()
+	for i := range watchMatches {
+		match := &watchMatches[i]
+		fmt.Printf("  %s %s %s %s\n", match.Time.Format(time.TimeOnly), watchSessionLabel(match.Session), match.Session.Alias,
+			highlightMatches(match.Line, match.Pattern))
+		match.Seen = true
+	}
+	count := len(watchMatches)
+	watchMu.Unlock()
+	sessionsMu.RUnlock()
+
+	if count == 0 {
+		fmt.Println(tr("  none"))
+		fmt.Print(tr("\nPress Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	fmt.Print(tr("\nc clears the list, Enter goes back: "))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(input) == "c" {
+		watchMu.Lock()
+		watchMatches = nil
+		watchMu.Unlock()
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	HealthGateTimeout  = 60 * time.Second
+	HealthGateInterval = 3 * time.Second
+)
+
+// orderMembers sorts workspace members so every host comes after its dependencies
+func orderMembers(ws Workspace) ([]WorkspaceMember, error) {
+	byAlias := make(map[string]WorkspaceMember)
+	for _, m := range ws.Members {
+		byAlias[m.Alias] = m
+	}
+
+	ordered := []WorkspaceMember{}
+	state := make(map[string]int) // 1 visiting, 2 done
+
+	var visit func(alias string) error
+	visit = func(alias string) error {
+		switch state[alias] {
+		case 1:
+			return fmt.Errorf(tr("dependency cycle at %s"), alias)
+		case 2:
+			return nil
+		}
+
+		member, ok := byAlias[alias]
+		if !ok {
+			return fmt.Errorf(tr("%s is not a member of workspace %s"), alias, ws.Name)
+		}
+
+		state[alias] = 1
+		for _, dep := range member.After {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[alias] = 2
+		ordered = append(ordered, member)
+		return nil
+	}
+
+	for _, m := range ws.Members {
+		if err := visit(m.Alias); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// hostResponds reports whether ssh reaches the host, through its jump host
+// if any; an auth rejection by the host itself still proves it is up
+func hostResponds(host SSHHost) bool {
+	var stderr bytes.Buffer
+	args := append([]string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5"}, overrideRemoteCommand(host)...)
+	args = append(args, sshTarget(host)...)
+	cmd := commander.Command(context.Background(), "ssh", append(args, "true")...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		return true
+	}
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if strings.Contains(line, "Permission denied") && failedJump(host, line) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForHost polls a host until it responds or the gate times out
+func waitForHost(host SSHHost) bool {
+	deadline := time.Now().Add(HealthGateTimeout)
+	for {
+		if hostResponds(host) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		fmt.Printf(tr("    waiting for %s...\n"), host.Alias)
+		time.Sleep(HealthGateInterval)
+	}
+}
+
+// openWorkspace starts detached sessions for all members in dependency order
+func openWorkspace(ws Workspace, hosts []SSHHost) {
+	fmt.Printf(tr("\nOpening workspace %s...\n"), ws.Name)
+
+	ordered, err := orderMembers(ws)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\nPress Enter..."), err)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	failed := make(map[string]bool)
+	opened := []*Session{}
+	for _, member := range ordered {
+		host, ok := findHost(hosts, member.Alias)
+		if !ok {
+			fmt.Printf(tr("  %s %s: not in ssh config\n"), failMark(), member.Alias)
+			failed[member.Alias] = true
+			continue
+		}
+
+		// Skipped before any confirmation when a dependency is not up
+		blocked := ""
+		for _, dep := range member.After {
+			if failed[dep] {
+				blocked = dep
+				break
+			}
+		}
+		if blocked != "" {
+			fmt.Printf(tr("  %s %s: skipped, %s is not up\n"), failMark(), member.Alias, blocked)
+			failed[member.Alias] = true
+			continue
+		}
+
+		if !confirmHost(host, ConfirmConnect) {
+			failed[member.Alias] = true
+			continue
+		}
+		reason := ""
+		if tag := reasonTag(host); tag != "" {
+			var ok bool
+			if reason, ok = askReason([]string{host.Alias}, tag); !ok {
+				failed[member.Alias] = true
+				continue
+			}
+		}
+
+		if err := refreshCertificate(host); err != nil {
+			fmt.Printf("  "+tr("Warning: %v\n"), err)
+		}
+		argv, err := sessionCommand(host)
+		var session *Session
+		if err == nil {
+			session, err = startSession(host, argv)
+		}
+		auditConnect(host, reason, session, err)
+		if err != nil {
+			fmt.Printf("  %s %s: %v\n", failMark(), member.Alias, err)
+			failed[member.Alias] = true
+			continue
+		}
+		fmt.Printf("  %s %s\n", okMark(), member.Alias)
+		opened = append(opened, session)
+
+		if member.Wait && !waitForHost(host) {
+			fmt.Printf(tr("  %s %s: no response after %v\n"), failMark(), member.Alias, HealthGateTimeout)
+			failed[member.Alias] = true
+		}
+	}
+
+	// Sessions left at a password or 2FA prompt would wait until attached
+	if offerPromptRelay(opened) {
+		return
+	}
+	fmt.Println(tr("\nWorkspace opened. Sessions are detached. Press Enter..."))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// chooseWorkspace lists configured workspaces and opens the selected one
+func chooseWorkspace(hosts []SSHHost) {
+	if len(appConfig.Workspaces) == 0 {
+		fmt.Println(tr("No workspaces configured (see README). Press Enter..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return
+	}
+
+	fmt.Println(tr("\nWorkspaces:"))
+	for i, ws := range appConfig.Workspaces {
+		aliases := []string{}
+		for _, m := range ws.Members {
+			aliases = append(aliases, m.Alias)
+		}
+		fmt.Printf("  [%d] %s (%s)\n", i+1, ws.Name, strings.Join(aliases, ", "))
+	}
+	fmt.Print(tr("\nWorkspace number: "))
+
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	var num int
+	if _, err := fmt.Sscanf(strings.TrimSpace(input), "%d", &num); err != nil || num < 1 || num > len(appConfig.Workspaces) {
+		return
+	}
+
+	openWorkspace(appConfig.Workspaces[num-1], hosts)
+}
@@ -1,5 +1,5 @@
-module sshtui
+module github.com/studiowebux/sshtui
 
 go 1.25.5
 
-require github.com/creack/pty v1.1.24 // indirect
+require github.com/creack/pty v1.1.24
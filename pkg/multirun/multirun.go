@@ -0,0 +1,112 @@
+// Package multirun runs a command on several hosts at once, each on its
+// own pseudo-terminal so remote programs behave as they would for a
+// user, with a timeout and cancellation shared by the run
+package multirun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/studiowebux/sshtui/pkg/ptysession"
+)
+
+var (
+	// ErrTimeout is returned for a command killed by its timeout
+	ErrTimeout = errors.New("timed out")
+	// ErrCancelled is returned for a command killed by a cancelled run
+	ErrCancelled = errors.New("cancelled")
+)
+
+// ExitError is returned for a command that ran and failed on the host
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// RunWith runs cmd, made with exec.CommandContext(ctx, ...) so it is
+// killed when ctx ends, on a pseudo-terminal of the given size made by
+// starter. Output received is returned, even on error, and copied to
+// stream as it arrives when stream is not nil
+func RunWith(ctx context.Context, starter ptysession.Starter, cmd *exec.Cmd, size *pty.Winsize, stream io.Writer) (string, error) {
+	if ctx.Err() != nil {
+		return "", contextError(ctx)
+	}
+	ptmx, err := starter.Start(cmd, size)
+	if err != nil {
+		return "", err
+	}
+	defer ptmx.Close()
+
+	// Stdin is not needed for non-interactive commands
+	var output bytes.Buffer
+	if stream != nil {
+		io.Copy(io.MultiWriter(&output, stream), ptmx)
+	} else {
+		io.Copy(&output, ptmx)
+	}
+	waitErr := cmd.Wait()
+
+	if ctx.Err() != nil {
+		return output.String(), contextError(ctx)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(waitErr, &exitErr) {
+		return output.String(), &ExitError{Code: exitErr.ExitCode()}
+	}
+	return output.String(), nil
+}
+
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ErrCancelled
+}
+
+// Target is a host to run on: a name for its result, and the command
+// reaching it, usually ssh, made for the context given
+type Target struct {
+	Name    string
+	Command func(ctx context.Context) *exec.Cmd
+}
+
+// Result is the outcome of a run on one target
+type Result struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+// All runs every target at once on pseudo-terminals made by starter, each
+// within timeout when it is not 0, and returns their results in the order
+// of targets
+func All(ctx context.Context, starter ptysession.Starter, targets []Target, size *pty.Winsize, timeout time.Duration) []Result {
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			runCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				runCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			output, err := RunWith(runCtx, starter, target.Command(runCtx), size, nil)
+			results[i] = Result{Name: target.Name, Output: output, Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
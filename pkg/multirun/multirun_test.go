@@ -0,0 +1,88 @@
+package multirun
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/studiowebux/sshtui/pkg/ptysession/ptytest"
+)
+
+var size = &pty.Winsize{Cols: 80, Rows: 24}
+
+func TestRunWithOutput(t *testing.T) {
+	ctx := context.Background()
+	var stream strings.Builder
+	output, err := RunWith(ctx, &ptytest.Starter{}, exec.CommandContext(ctx, "sh", "-c", "echo hello"), size, &stream)
+	if err != nil {
+		t.Fatalf("RunWith: %v", err)
+	}
+	if output != "hello\n" || stream.String() != output {
+		t.Errorf("got output %q, streamed %q, want %q", output, stream.String(), "hello\n")
+	}
+}
+
+func TestRunWithExitCode(t *testing.T) {
+	ctx := context.Background()
+	output, err := RunWith(ctx, &ptytest.Starter{}, exec.CommandContext(ctx, "sh", "-c", "echo partial; exit 3"), size, nil)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("got %v, want exit status 3", err)
+	}
+	if output != "partial\n" {
+		t.Errorf("output %q is not kept on failure", output)
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := RunWith(ctx, &ptytest.Starter{}, exec.CommandContext(ctx, "sleep", "5"), size, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("got %v, want ErrTimeout", err)
+	}
+}
+
+func TestRunWithCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := RunWith(ctx, &ptytest.Starter{}, exec.CommandContext(ctx, "true"), size, nil)
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("got %v, want ErrCancelled", err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	starter := &ptytest.Starter{}
+	target := func(name, script string) Target {
+		return Target{Name: name, Command: func(ctx context.Context) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", script)
+		}}
+	}
+	targets := []Target{
+		target("slow", "sleep 0.2; echo slow"),
+		target("fast", "echo fast"),
+		target("hung", "exec sleep 5"),
+	}
+	results := All(context.Background(), starter, targets, size, time.Second)
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results", len(results))
+	}
+	if results[0].Name != "slow" || results[0].Output != "slow\n" || results[0].Err != nil {
+		t.Errorf("slow: %+v", results[0])
+	}
+	if results[1].Name != "fast" || results[1].Output != "fast\n" || results[1].Err != nil {
+		t.Errorf("fast: %+v", results[1])
+	}
+	if !errors.Is(results[2].Err, ErrTimeout) {
+		t.Errorf("hung: got %v, want ErrTimeout", results[2].Err)
+	}
+	if sizes := starter.Sizes(); len(sizes) != 3 || sizes[0] != *size {
+		t.Errorf("started with sizes %v", sizes)
+	}
+}
@@ -0,0 +1,79 @@
+package ptysession
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+const (
+	// lineCutSearch is how far a cut looks ahead for a line start before
+	// settling for a character boundary
+	lineCutSearch = 1024
+	// escapeLookBack is how far back a cut looks for an escape sequence
+	// it would split
+	escapeLookBack = 64
+)
+
+// TailAtBoundary returns at most the last limit bytes of output, starting
+// at a line start when one is near, else at a character start, and never
+// inside an escape sequence, so replaying it draws no stray bytes
+func TailAtBoundary(output []byte, limit int) []byte {
+	if len(output) <= limit {
+		return output
+	}
+	start := len(output) - limit
+	if i := bytes.IndexByte(output[start:min(len(output), start+lineCutSearch)], '\n'); i >= 0 {
+		start += i + 1
+	} else {
+		for n := 0; n < utf8.UTFMax-1 && start < len(output) && !utf8.RuneStart(output[start]); n++ {
+			start++
+		}
+	}
+	return output[skipPartialEscape(output, start):]
+}
+
+// skipPartialEscape moves start past an escape sequence that began before
+// it and ends at or after it. Only escapeLookBack bytes past start are
+// looked at for its end: a sequence that never ends, or a longer one, is
+// cut at start rather than taking the whole output with it
+func skipPartialEscape(output []byte, start int) int {
+	from := max(0, start-escapeLookBack)
+	esc := bytes.LastIndexByte(output[from:start], 0x1b)
+	if esc < 0 {
+		return start
+	}
+	end := escapeEnd(output[:min(len(output), start+escapeLookBack)], from+esc)
+	if end < 0 {
+		return start
+	}
+	return max(start, end)
+}
+
+// escapeEnd returns the index right after the escape sequence starting
+// at output[i], or -1 when output ends before it does
+func escapeEnd(output []byte, i int) int {
+	if i+1 >= len(output) {
+		return -1
+	}
+	switch output[i+1] {
+	case '[': // CSI: parameters and intermediates, then a final byte
+		for j := i + 2; j < len(output); j++ {
+			if output[j] >= 0x40 && output[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return -1
+	case ']', 'P', '_', '^': // OSC and strings: BEL or ESC \ ends them
+		for j := i + 2; j < len(output); j++ {
+			if output[j] == 0x07 {
+				return j + 1
+			}
+			if output[j] == 0x1b && j+1 < len(output) && output[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return -1
+	default:
+		return i + 2
+	}
+}
@@ -0,0 +1,45 @@
+package ptysession
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTailAtBoundary(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		limit  int
+		want   string
+	}{
+		{"fits", "one\ntwo\n", 64, "one\ntwo\n"},
+		{"line start", "first line\nsecond\n", 10, "second\n"},
+		{"character start", "aé", 1, ""},
+		{"after escape", "xxxxx\x1b[31mred", 6, "red"},
+		{"after osc", "xxxxxxxx\x1b]0;title\x07text", 10, "text"},
+		{"escape before cut", "\x1b[1mbold text", 9, "bold text"},
+		{"unfinished osc", "xx\x1b]0;title that never ends", 10, "never ends"},
+		{"unfinished csi", "xx\x1b[1;2;3", 3, "2;3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TailAtBoundary([]byte(tt.output), tt.limit)
+			if string(got) != tt.want {
+				t.Errorf("TailAtBoundary(%q, %d) = %q, want %q", tt.output, tt.limit, got, tt.want)
+			}
+			if len(got) > tt.limit {
+				t.Errorf("TailAtBoundary(%q, %d) kept %d bytes", tt.output, tt.limit, len(got))
+			}
+		})
+	}
+}
+
+func TestTailAtBoundaryLongLine(t *testing.T) {
+	// No line start near the cut: a character start will do
+	output := []byte(strings.Repeat("é", 2000))
+	got := TailAtBoundary(output, 101)
+	if len(got) != 100 || !bytes.HasPrefix(got, []byte("é")) {
+		t.Errorf("got %d bytes starting with %q, want 100 starting with é", len(got), got[:min(2, len(got))])
+	}
+}
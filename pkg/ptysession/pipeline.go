@@ -0,0 +1,83 @@
+// Package ptysession has the building blocks of sshtui sessions: a
+// command on a pseudo-terminal whose output is broadcast to subscribers,
+// and cut at safe points when replayed. Managing sessions, attaching to
+// them and watching them end is left to the program
+package ptysession
+
+import (
+	"io"
+	"sync"
+)
+
+// Pipeline broadcasts a session's output to its subscribers (scrollback,
+// state tracking, tee, the terminal while attached...) in the order they
+// subscribed. Subscribers guard their own state, and must not write to
+// the pipeline they are subscribed to
+type Pipeline struct {
+	mu          sync.Mutex
+	subscribers []outputSubscriber
+}
+
+type outputSubscriber struct {
+	name string
+	w    io.Writer
+}
+
+// Write publishes a chunk to every subscriber. A failing subscriber does
+// not stop the others
+func (p *Pipeline) Write(chunk []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, sub := range p.subscribers {
+		sub.w.Write(chunk)
+	}
+	return len(chunk), nil
+}
+
+// Subscribe adds w under name, replacing any subscriber of that name in
+// place
+func (p *Pipeline) Subscribe(name string, w io.Writer) {
+	p.SubscribeAfter(name, w, nil)
+}
+
+// SubscribeAfter runs catchUp, then subscribes w, with publishing held
+// off in between so w neither misses a chunk nor sees one twice
+func (p *Pipeline) SubscribeAfter(name string, w io.Writer, catchUp func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if catchUp != nil {
+		catchUp()
+	}
+	for i, sub := range p.subscribers {
+		if sub.name == name {
+			p.subscribers[i].w = w
+			return
+		}
+	}
+	p.subscribers = append(p.subscribers, outputSubscriber{name, w})
+}
+
+// Hold runs fn with publishing held off, for writing to the terminal
+// between two chunks of session output
+func (p *Pipeline) Hold(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn()
+}
+
+// Unsubscribe removes the subscriber called name, if any; once it
+// returns, that subscriber receives nothing more
+func (p *Pipeline) Unsubscribe(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, sub := range p.subscribers {
+		if sub.name == name {
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ptysession
+
+import (
+	"bytes"
+	"testing"
+)
+
+// orderWriter records which subscriber got each chunk, in order
+type orderWriter struct {
+	name string
+	log  *[]string
+}
+
+func (w orderWriter) Write(p []byte) (int, error) {
+	*w.log = append(*w.log, w.name+":"+string(p))
+	return len(p), nil
+}
+
+func TestPipelineOrder(t *testing.T) {
+	var p Pipeline
+	log := []string{}
+	p.Subscribe("a", orderWriter{"a", &log})
+	p.Subscribe("b", orderWriter{"b", &log})
+	p.Write([]byte("1"))
+
+	// Replacing a subscriber keeps its place
+	p.Subscribe("a", orderWriter{"a2", &log})
+	p.Write([]byte("2"))
+
+	want := []string{"a:1", "b:1", "a2:2", "b:2"}
+	if len(log) != len(want) {
+		t.Fatalf("got %q, want %q", log, want)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("got %q, want %q", log, want)
+		}
+	}
+}
+
+func TestPipelineUnsubscribe(t *testing.T) {
+	var p Pipeline
+	var kept, dropped bytes.Buffer
+	p.Subscribe("kept", &kept)
+	p.Subscribe("dropped", &dropped)
+	p.Write([]byte("before "))
+	p.Unsubscribe("dropped")
+	p.Unsubscribe("unknown")
+	p.Write([]byte("after"))
+
+	if kept.String() != "before after" {
+		t.Errorf("kept subscriber got %q", kept.String())
+	}
+	if dropped.String() != "before " {
+		t.Errorf("unsubscribed subscriber got %q", dropped.String())
+	}
+}
+
+func TestPipelineSubscribeAfter(t *testing.T) {
+	var p Pipeline
+	var history, live bytes.Buffer
+	p.Subscribe("history", &history)
+	p.Write([]byte("old "))
+
+	// The catch-up sees everything published so far, the subscriber what
+	// comes next, with nothing lost or doubled in between
+	p.SubscribeAfter("live", &live, func() {
+		live.Write(history.Bytes())
+	})
+	p.Write([]byte("new"))
+
+	if live.String() != "old new" {
+		t.Errorf("got %q, want %q", live.String(), "old new")
+	}
+}
@@ -0,0 +1,208 @@
+// Package ptytest has fakes for running sessions without ssh or a
+// terminal: a Starter wiring commands to a socket instead of a
+// pseudo-terminal, a Commander running a shell script in place of ssh and
+// a Terminal typed into by the test
+package ptytest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// Starter starts commands on one end of a socket pair and returns the
+// other, which reads and writes like a pseudo-terminal's master side but
+// has no size and no line discipline
+type Starter struct {
+	mu    sync.Mutex
+	sizes []pty.Winsize
+}
+
+// Start implements ptysession.Starter
+func (s *Starter) Start(cmd *exec.Cmd, size *pty.Winsize) (*os.File, error) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		return nil, err
+	}
+	master := os.NewFile(uintptr(fds[0]), "ptytest-master")
+	slave := os.NewFile(uintptr(fds[1]), "ptytest-slave")
+	defer slave.Close()
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, err
+	}
+	s.mu.Lock()
+	if size != nil {
+		s.sizes = append(s.sizes, *size)
+	}
+	s.mu.Unlock()
+	return master, nil
+}
+
+// Sizes returns the size asked for by each start, oldest first
+func (s *Starter) Sizes() []pty.Winsize {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pty.Winsize(nil), s.sizes...)
+}
+
+// Commander runs Script with sh in place of every command: the command's
+// name is $0 and its arguments $@, so a script can play ssh for a host
+type Commander struct {
+	Script string
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+// Command implements the session engine's Commander
+func (c *Commander) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	c.mu.Lock()
+	c.calls = append(c.calls, append([]string{name}, args...))
+	c.mu.Unlock()
+	return exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", c.Script, name}, args...)...)
+}
+
+// Calls returns the name and arguments of each command made, oldest first
+func (c *Commander) Calls() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]string(nil), c.calls...)
+}
+
+// ErrClosed is returned by Read once a Terminal is closed and its typed
+// input read
+var ErrClosed = errors.New("terminal closed")
+
+// Terminal is a user's terminal: keys passed to Type are what Read
+// returns, and modes switched are recorded instead of applied
+type Terminal struct {
+	Cols, Rows uint16
+
+	keys    chan []byte
+	pending []byte
+	mu      sync.Mutex
+	mode    string
+}
+
+// NewTerminal returns a terminal of the given size with nothing typed
+func NewTerminal(cols, rows uint16) *Terminal {
+	return &Terminal{Cols: cols, Rows: rows, keys: make(chan []byte, 64)}
+}
+
+// Type queues keys to be read, as if typed by the user
+func (t *Terminal) Type(keys string) {
+	t.keys <- []byte(keys)
+}
+
+// Close ends input; reads after what was typed return ErrClosed
+func (t *Terminal) Close() {
+	close(t.keys)
+}
+
+// Read returns typed keys, waiting for some when none are queued
+func (t *Terminal) Read(p []byte) (int, error) {
+	t.mu.Lock()
+	if len(t.pending) > 0 {
+		n := copy(p, t.pending)
+		t.pending = t.pending[n:]
+		t.mu.Unlock()
+		return n, nil
+	}
+	t.mu.Unlock()
+
+	keys, ok := <-t.keys
+	if !ok {
+		return 0, ErrClosed
+	}
+	n := copy(p, keys)
+	t.mu.Lock()
+	t.pending = append(t.pending, keys[n:]...)
+	t.mu.Unlock()
+	return n, nil
+}
+
+// InputWithin reports whether keys are typed before timeout, keeping them
+// for the next Read
+func (t *Terminal) InputWithin(timeout time.Duration) bool {
+	t.mu.Lock()
+	waiting := len(t.pending) > 0
+	t.mu.Unlock()
+	if waiting {
+		return true
+	}
+	select {
+	case keys, ok := <-t.keys:
+		if !ok {
+			return false
+		}
+		t.mu.Lock()
+		t.pending = append(t.pending, keys...)
+		t.mu.Unlock()
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Drain drops the keys typed and not read yet, and tells how many bytes
+func (t *Terminal) Drain() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.pending)
+	t.pending = nil
+	for {
+		select {
+		case keys, ok := <-t.keys:
+			if !ok {
+				return n
+			}
+			n += len(keys)
+		default:
+			return n
+		}
+	}
+}
+
+// MakeRaw records raw mode until the returned function is called
+func (t *Terminal) MakeRaw() (func(), error) {
+	return t.setMode("raw"), nil
+}
+
+// MakeCbreak records cbreak mode until the returned function is called
+func (t *Terminal) MakeCbreak() (func(), error) {
+	return t.setMode("cbreak"), nil
+}
+
+func (t *Terminal) setMode(mode string) func() {
+	t.mu.Lock()
+	previous := t.mode
+	t.mode = mode
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		t.mode = previous
+		t.mu.Unlock()
+	}
+}
+
+// Mode returns "raw", "cbreak" or "" for the terminal's usual mode
+func (t *Terminal) Mode() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mode
+}
+
+// Size returns Cols and Rows
+func (t *Terminal) Size() (*pty.Winsize, error) {
+	return &pty.Winsize{Cols: t.Cols, Rows: t.Rows}, nil
+}
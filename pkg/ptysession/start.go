@@ -0,0 +1,63 @@
+package ptysession
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// ErrStartTimeout is returned by StartWith when the command did not start in
+// time
+var ErrStartTimeout = errors.New("start timed out")
+
+// Starter starts cmd on a pseudo-terminal of the given size and returns
+// its master side. PTY is the real one; tests give their own to run
+// sessions without a terminal
+type Starter interface {
+	Start(cmd *exec.Cmd, size *pty.Winsize) (*os.File, error)
+}
+
+// StarterFunc lets a plain function be used as a Starter
+type StarterFunc func(cmd *exec.Cmd, size *pty.Winsize) (*os.File, error)
+
+// Start calls f
+func (f StarterFunc) Start(cmd *exec.Cmd, size *pty.Winsize) (*os.File, error) {
+	return f(cmd, size)
+}
+
+// PTY starts commands on new pseudo-terminals from the system
+var PTY Starter = StarterFunc(pty.StartWithSize)
+
+// StartWith runs cmd on a new pseudo-terminal of the given size, made by
+// starter, and returns its master side. Starting is given up, and the
+// command killed, after timeout
+func StartWith(starter Starter, cmd *exec.Cmd, size *pty.Winsize, timeout time.Duration) (*os.File, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Start in a goroutine to support the timeout
+	type ptyResult struct {
+		ptmx *os.File
+		err  error
+	}
+	resultCh := make(chan ptyResult, 1)
+
+	go func() {
+		ptmx, err := starter.Start(cmd, size)
+		resultCh <- ptyResult{ptmx: ptmx, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.ptmx, result.err
+	case <-ctx.Done():
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		return nil, ErrStartTimeout
+	}
+}
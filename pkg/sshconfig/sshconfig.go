@@ -0,0 +1,202 @@
+// Package sshconfig reads the Host blocks of an OpenSSH client config,
+// ~/.ssh/config, with the settings sshtui needs to list and reach hosts.
+// It does not follow Include, nor merge wildcard or Match blocks: ssh -G
+// resolves a host completely
+package sshconfig
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Host is a literal alias of a Host block, with the block's settings
+type Host struct {
+	Alias            string
+	HostName         string
+	User             string
+	Port             string
+	Forwards         []Forward
+	RemoteCommand    string
+	ProxyJump        string // "" for none
+	ProxyCommand     string // "" for none, %h and other tokens left as written
+	IdentityFiles    []string
+	CertificateFiles []string
+	RequestTTY       string   // lower case
+	Keywords         []string // lower case, every one the block sets, in order
+}
+
+// Forward is a LocalForward, RemoteForward or DynamicForward
+type Forward struct {
+	Type       string // "L", "R", "D"
+	LocalPort  string
+	RemoteAddr string // "host:port" or empty for dynamic
+}
+
+// DefaultPath is the user's ssh config, ~/.ssh/config
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ssh", "config"), nil
+}
+
+// ParseFile reads the hosts of the ssh config at path
+func ParseFile(path string) ([]Host, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads the hosts of an ssh config, in order. A Host line naming
+// several aliases gives each of them the block's settings; wildcard and
+// negated patterns are skipped, as they are no host to connect to
+func Parse(r io.Reader) ([]Host, error) {
+	var hosts []Host
+	var current *Host
+	var aliases []string
+
+	// endBlock adds a host per alias of the block being read
+	endBlock := func() {
+		if current != nil {
+			for _, alias := range aliases {
+				host := *current
+				host.Alias = alias
+				host.Forwards = slices.Clone(current.Forwards)
+				host.IdentityFiles = slices.Clone(current.IdentityFiles)
+				host.CertificateFiles = slices.Clone(current.CertificateFiles)
+				host.Keywords = slices.Clone(current.Keywords)
+				hosts = append(hosts, host)
+			}
+		}
+		current, aliases = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+
+		key := strings.ToLower(parts[0])
+		value := strings.Join(parts[1:], " ")
+
+		if key == "host" || key == "match" {
+			// Any Host or Match line ends the block before it
+			endBlock()
+			if key == "match" {
+				continue
+			}
+			for _, pattern := range parts[1:] {
+				if !strings.ContainsAny(pattern, "*?!") {
+					aliases = append(aliases, pattern)
+				}
+			}
+			if len(aliases) > 0 {
+				current = &Host{Forwards: make([]Forward, 0)}
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		current.Keywords = append(current.Keywords, key)
+		switch key {
+		case "hostname":
+			current.HostName = value
+		case "user":
+			current.User = value
+		case "port":
+			current.Port = value
+		case "remotecommand":
+			current.RemoteCommand = value
+		case "proxyjump":
+			if !strings.EqualFold(value, "none") {
+				current.ProxyJump = value
+			}
+		case "proxycommand":
+			if !strings.EqualFold(value, "none") {
+				current.ProxyCommand = value
+			}
+		case "requesttty":
+			current.RequestTTY = strings.ToLower(value)
+		case "identityfile":
+			current.IdentityFiles = append(current.IdentityFiles, strings.Trim(value, `"`))
+		case "certificatefile":
+			current.CertificateFiles = append(current.CertificateFiles, strings.Trim(value, `"`))
+		case "localforward":
+			if fwd := ParseLocalForward(value); fwd != nil {
+				current.Forwards = append(current.Forwards, *fwd)
+			}
+		case "remoteforward":
+			if fwd := ParseRemoteForward(value); fwd != nil {
+				current.Forwards = append(current.Forwards, *fwd)
+			}
+		case "dynamicforward":
+			if fwd := ParseDynamicForward(value); fwd != nil {
+				current.Forwards = append(current.Forwards, *fwd)
+			}
+		}
+	}
+
+	endBlock()
+
+	return hosts, scanner.Err()
+}
+
+// ParseLocalForward reads a LocalForward value: 8080 remote:80
+func ParseLocalForward(value string) *Forward {
+	parts := strings.Fields(value)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	return &Forward{
+		Type:       "L",
+		LocalPort:  parts[0],
+		RemoteAddr: parts[1],
+	}
+}
+
+// ParseRemoteForward reads a RemoteForward value: 9090 localhost:80
+func ParseRemoteForward(value string) *Forward {
+	parts := strings.Fields(value)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	return &Forward{
+		Type:       "R",
+		LocalPort:  parts[0],
+		RemoteAddr: parts[1],
+	}
+}
+
+// ParseDynamicForward reads a DynamicForward value: 1080
+func ParseDynamicForward(value string) *Forward {
+	port := strings.TrimSpace(value)
+	if port == "" {
+		return nil
+	}
+
+	return &Forward{
+		Type:      "D",
+		LocalPort: port,
+	}
+}
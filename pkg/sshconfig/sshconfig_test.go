@@ -0,0 +1,122 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func aliases(hosts []Host) []string {
+	names := []string{}
+	for _, host := range hosts {
+		names = append(names, host.Alias)
+	}
+	return names
+}
+
+func TestParse(t *testing.T) {
+	config := `
+# Shared settings are left to ssh -G
+Include ~/.ssh/config.d/*
+Host *
+    ServerAliveInterval 30
+
+Host web1 web2
+    HostName %h.example.com
+    User deploy
+    LocalForward 8080 localhost:80
+    IdentityFile "~/.ssh/web key"
+
+Host db* !db-old
+    User postgres
+
+Host bastion
+    HostName 203.0.113.10
+    Port 2222
+    ProxyJump none
+
+Match host bastion exec "true"
+    User nobody
+
+Host app db? !app-old
+    ProxyJump bastion
+    Include app.conf
+`
+	hosts, err := Parse(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := aliases(hosts), []string{"web1", "web2", "bastion", "app"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("aliases %q, want %q", got, want)
+	}
+
+	web1, web2 := hosts[0], hosts[1]
+	if web1.HostName != "%h.example.com" || web1.User != "deploy" || web2.User != "deploy" {
+		t.Errorf("web1 %+v and web2 %+v, want both with the block's settings", web1, web2)
+	}
+	if len(web2.Forwards) != 1 || web2.Forwards[0] != (Forward{Type: "L", LocalPort: "8080", RemoteAddr: "localhost:80"}) {
+		t.Errorf("web2 forwards %+v", web2.Forwards)
+	}
+	if !reflect.DeepEqual(web1.IdentityFiles, []string{"~/.ssh/web key"}) {
+		t.Errorf("web1 identities %q", web1.IdentityFiles)
+	}
+	// Hosts of a block share no slices
+	web1.Forwards[0].LocalPort = "9090"
+	if web2.Forwards[0].LocalPort != "8080" {
+		t.Error("changing web1's forward changed web2's")
+	}
+
+	// Match ends the bastion block; its settings go to no host
+	bastion := hosts[2]
+	if bastion.User != "" || bastion.Port != "2222" || bastion.ProxyJump != "" {
+		t.Errorf("bastion %+v", bastion)
+	}
+	if !reflect.DeepEqual(bastion.Keywords, []string{"hostname", "port", "proxyjump"}) {
+		t.Errorf("bastion keywords %q", bastion.Keywords)
+	}
+
+	// Include is not followed, within a block or outside
+	app := hosts[3]
+	if app.ProxyJump != "bastion" || !reflect.DeepEqual(app.Keywords, []string{"proxyjump", "include"}) {
+		t.Errorf("app %+v", app)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host one\n  DynamicForward 1080\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Alias != "one" || hosts[0].Forwards[0] != (Forward{Type: "D", LocalPort: "1080"}) {
+		t.Errorf("got %+v", hosts)
+	}
+
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v", err)
+	}
+}
+
+func TestParseForwards(t *testing.T) {
+	tests := []struct {
+		parse func(string) *Forward
+		value string
+		want  *Forward
+	}{
+		{ParseLocalForward, "8080 remote:80", &Forward{Type: "L", LocalPort: "8080", RemoteAddr: "remote:80"}},
+		{ParseLocalForward, "8080", nil},
+		{ParseRemoteForward, "9090 localhost:80", &Forward{Type: "R", LocalPort: "9090", RemoteAddr: "localhost:80"}},
+		{ParseDynamicForward, " 1080 ", &Forward{Type: "D", LocalPort: "1080"}},
+		{ParseDynamicForward, "", nil},
+	}
+	for _, tt := range tests {
+		if got := tt.parse(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}